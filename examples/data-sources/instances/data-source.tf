@@ -3,4 +3,51 @@ data "zsphere_instances" "test" {
 
 output "zstack_secs" {
   value = data.zsphere_instances.test
-}
\ No newline at end of file
+}
+
+# Running instances with at least 4 CPUs and 8 GB of memory or more
+data "zsphere_instances" "large" {
+  filter {
+    name   = "state"
+    values = ["Running"]
+  }
+  filter {
+    name     = "cpu_num"
+    operator = ">="
+    values   = ["4"]
+  }
+  filter {
+    name     = "memory_size"
+    operator = ">="
+    values   = ["8192"]
+  }
+}
+
+# Instances whose name starts with web- or api-, or that run on another host than this one
+data "zsphere_instances" "services" {
+  filter_match = "any"
+  filter {
+    name     = "name"
+    operator = "regex"
+    values   = ["^(web|api)-"]
+  }
+  filter {
+    name     = "host_uuid"
+    operator = "not_in"
+    values   = ["9b26312501614ec0b6dc731e6977dfb2"]
+  }
+}
+
+# Instances with a NIC in 192.168.0.0/24 and a volume larger than 100 GB
+data "zsphere_instances" "private" {
+  filter {
+    name     = "vm_nics.ip"
+    operator = "prefix"
+    values   = ["192.168.0."]
+  }
+  filter {
+    name     = "all_volumes.volume_size"
+    operator = ">"
+    values   = ["100"]
+  }
+}
@@ -0,0 +1,16 @@
+data "zsphere_l2_networks" "vlans" {
+  filter {
+    name   = "type"
+    values = ["L2VlanNetwork"]
+  }
+
+  filter {
+    name     = "vlan"
+    operator = ">="
+    values   = ["100"]
+  }
+}
+
+output "zsphere_l2_networks" {
+  value = data.zsphere_l2_networks.vlans
+}
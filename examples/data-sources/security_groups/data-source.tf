@@ -0,0 +1,12 @@
+data "zsphere_security_groups" "web" {
+  name_pattern = "web%"
+
+  filter {
+    name   = "rules.dst_port_range"
+    values = ["443"]
+  }
+}
+
+output "zsphere_security_groups" {
+  value = data.zsphere_security_groups.web
+}
@@ -0,0 +1,15 @@
+data "zsphere_virtual_router_instances" "routers" {
+  filter {
+    name   = "ha_status"
+    values = ["Master", "NoHa"]
+  }
+
+  filter {
+    name   = "status"
+    values = ["Connected"]
+  }
+}
+
+output "zsphere_virtual_router_instances" {
+  value = data.zsphere_virtual_router_instances.routers
+}
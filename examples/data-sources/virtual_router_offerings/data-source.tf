@@ -0,0 +1,12 @@
+data "zsphere_virtual_router_offerings" "offerings" {
+  name_pattern = "vr-%"
+
+  filter {
+    name   = "is_default"
+    values = ["true"]
+  }
+}
+
+output "zsphere_virtual_router_offerings" {
+  value = data.zsphere_virtual_router_offerings.offerings
+}
@@ -0,0 +1,11 @@
+data "zsphere_volumes" "test" {
+  name_pattern = "volume%"
+  filter {
+    name   = "vm_instance_uuid"
+    values = ["9b26312501614ec0b6dc731e6977dfb2"]
+  }
+}
+
+output "zsphere_volumes" {
+  value = data.zsphere_volumes.test
+}
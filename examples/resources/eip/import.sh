@@ -0,0 +1,2 @@
+# zsphere_eip can be imported using its UUID
+terraform import zsphere_eip.eip 8d0f2b4c6e1a4c3e5a7d9f1b3c5e7a9d
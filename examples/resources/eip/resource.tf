@@ -0,0 +1,14 @@
+resource "zsphere_vip" "vip" {
+  name            = "vip-from-terraform"
+  l3_network_uuid = "6b1e3a9c2d4f4e5a8b7c6d5e4f3a2b1c"
+}
+
+resource "zsphere_eip" "eip" {
+  name        = "eip-from-terraform"
+  description = "create an eip from terraform"
+  vip_uuid    = zsphere_vip.vip.uuid
+}
+
+output "zsphere_eip" {
+  value = zsphere_eip.eip
+}
@@ -0,0 +1,2 @@
+# zsphere_eip_attachment can be imported using the UUID of the attached EIP
+terraform import zsphere_eip_attachment.attachment 8d0f2b4c6e1a4c3e5a7d9f1b3c5e7a9d
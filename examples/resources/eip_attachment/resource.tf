@@ -0,0 +1,9 @@
+resource "zsphere_eip" "eip" {
+  name     = "eip-from-terraform"
+  vip_uuid = zsphere_vip.vip.uuid
+}
+
+resource "zsphere_eip_attachment" "attachment" {
+  eip_uuid    = zsphere_eip.eip.uuid
+  vm_nic_uuid = zsphere_instance.vm.vm_nics[0].uuid
+}
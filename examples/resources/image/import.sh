@@ -0,0 +1,2 @@
+# zsphere_image can be imported using its UUID
+terraform import zsphere_image.image 9b26312501614ec0b6dc731e6977dfb2
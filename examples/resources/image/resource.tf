@@ -15,6 +15,9 @@ resource "zsphere_image" "image" {
   boot_mode           = "Legacy"
   expunge             = true
 
+  timeouts {
+    create = "2h"
+  }
 }
 
 output "zsphere_image" {
@@ -0,0 +1,2 @@
+# zsphere_l2_network can be imported using its UUID
+terraform import zsphere_l2_network.vlan 4e5f6a7b8c9d4e0f1a2b3c4d5e6f7a8b
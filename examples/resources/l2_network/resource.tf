@@ -0,0 +1,36 @@
+data "zsphere_datacenters" "zone" {
+}
+
+data "zsphere_clusters" "clusters" {
+  name = "cluster-1"
+}
+
+resource "zsphere_l2_network" "vlan" {
+  name                   = "vlan-100"
+  description            = "create a VLAN network from terraform"
+  type                   = "L2VlanNetwork"
+  zone_uuid              = data.zsphere_datacenters.zone.data_centers.0.uuid
+  physical_interface     = "eth0"
+  vlan                   = 100
+  attached_cluster_uuids = [data.zsphere_clusters.clusters.clusters.0.uuid]
+}
+
+resource "zsphere_l2_network" "vxlan_pool" {
+  name                   = "vxlan-pool"
+  type                   = "VxlanNetworkPool"
+  zone_uuid              = data.zsphere_datacenters.zone.data_centers.0.uuid
+  start_vni              = 1000
+  end_vni                = 2000
+  vtep_cidr              = "172.20.0.0/16"
+  attached_cluster_uuids = [data.zsphere_clusters.clusters.clusters.0.uuid]
+}
+
+resource "zsphere_port_group" "network" {
+  name            = "port-group-on-vlan-100"
+  l2_network_uuid = zsphere_l2_network.vlan.uuid
+
+  ip_ranges {
+    name = "range-1"
+    cidr = "192.168.100.0/24"
+  }
+}
@@ -0,0 +1,2 @@
+# zsphere_port_group can be imported using its UUID
+terraform import zsphere_port_group.network 9c3b2f1e8d7a4c6b5e4f3a2b1c0d9e8f
@@ -0,0 +1,27 @@
+resource "zsphere_port_group" "network" {
+  name            = "port-group-from-terraform"
+  description     = "create a port group from terraform"
+  l2_network_uuid = "1d4a0e1b2c3f4d5e6f7a8b9c0d1e2f3a"
+  category        = "Private"
+  dns_domain      = "example.local"
+  dns_servers     = ["223.5.5.5", "8.8.8.8"]
+  enable_dhcp     = true
+  dhcp_server_ip  = "192.168.10.2"
+
+  ip_ranges {
+    name = "range-by-cidr"
+    cidr = "192.168.10.0/24"
+  }
+
+  ip_ranges {
+    name     = "range-by-ips"
+    start_ip = "192.168.20.10"
+    end_ip   = "192.168.20.200"
+    netmask  = "255.255.255.0"
+    gateway  = "192.168.20.1"
+  }
+}
+
+output "zsphere_port_group" {
+  value = zsphere_port_group.network
+}
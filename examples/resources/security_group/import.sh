@@ -0,0 +1,2 @@
+# zsphere_security_group can be imported using its UUID
+terraform import zsphere_security_group.web 7b8c9d0e1f2a4b3c8d5e6f7a8b9c0d1e
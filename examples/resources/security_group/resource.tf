@@ -0,0 +1,23 @@
+data "zsphere_port_groups" "network" {
+  name = "public-net"
+}
+
+data "zsphere_instances" "web" {
+  name = "web-1"
+
+  filter {
+    name   = "vm_nics.l3_network_uuid"
+    values = [data.zsphere_port_groups.network.port_groups.0.uuid]
+  }
+}
+
+resource "zsphere_security_group" "web" {
+  name                      = "web"
+  description               = "create a security group from terraform"
+  attached_l3_network_uuids = [data.zsphere_port_groups.network.port_groups.0.uuid]
+  vm_nic_uuids              = [data.zsphere_instances.web.vminstances.0.vm_nics.0.uuid]
+}
+
+output "zsphere_security_group" {
+  value = zsphere_security_group.web
+}
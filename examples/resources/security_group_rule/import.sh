@@ -0,0 +1,2 @@
+# zsphere_security_group_rule can be imported using its UUID
+terraform import zsphere_security_group_rule.https 2c3d4e5f6a7b4c8d9e0f1a2b3c4d5e6f
@@ -0,0 +1,30 @@
+resource "zsphere_security_group" "web" {
+  name = "web"
+}
+
+resource "zsphere_security_group_rule" "https" {
+  security_group_uuid = zsphere_security_group.web.uuid
+  type                = "Ingress"
+  protocol            = "TCP"
+  src_ip_range        = "0.0.0.0/0"
+  dst_port_range      = "443"
+  description         = "allow HTTPS from anywhere"
+}
+
+resource "zsphere_security_group_rule" "ssh" {
+  security_group_uuid = zsphere_security_group.web.uuid
+  type                = "Ingress"
+  protocol            = "TCP"
+  src_ip_range        = "10.0.0.0/8"
+  dst_port_range      = "22"
+  priority            = 1
+}
+
+resource "zsphere_security_group_rule" "deny_smtp" {
+  security_group_uuid = zsphere_security_group.web.uuid
+  type                = "Egress"
+  protocol            = "TCP"
+  dst_ip_range        = "0.0.0.0/0"
+  dst_port_range      = "25"
+  action              = "DROP"
+}
@@ -0,0 +1,2 @@
+# zsphere_vip can be imported using its UUID
+terraform import zsphere_vip.vip 2c4e6a8b0d1f4a3c5e7b9d1f3a5c7e9b
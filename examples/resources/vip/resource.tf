@@ -0,0 +1,10 @@
+resource "zsphere_vip" "vip" {
+  name            = "vip-from-terraform"
+  description     = "create a vip from terraform"
+  l3_network_uuid = "6b1e3a9c2d4f4e5a8b7c6d5e4f3a2b1c"
+  ip              = "172.20.0.100"
+}
+
+output "zsphere_vip" {
+  value = zsphere_vip.vip
+}
@@ -0,0 +1,2 @@
+# zsphere_virtual_router_offering can be imported using its UUID
+terraform import zsphere_virtual_router_offering.offering 4e6a8c0b2d1f4e3a5c7b9d1e3f5a7c9b
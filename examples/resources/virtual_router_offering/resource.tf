@@ -0,0 +1,19 @@
+data "zsphere_datacenters" "zone" {
+  name = "zone-1"
+}
+
+resource "zsphere_virtual_router_offering" "offering" {
+  name                    = "vr-offering-from-terraform"
+  description             = "create a virtual router offering from terraform"
+  zone_uuid               = data.zsphere_datacenters.zone.data_centers[0].uuid
+  cpu_num                 = 2
+  memory_size             = 2048
+  image_uuid              = "3f5b7d9e1a2c4e6a8b0d2f4a6c8e0b1d"
+  management_network_uuid = "9a8b7c6d5e4f4a3b2c1d0e9f8a7b6c5d"
+  public_network_uuid     = "6b1e3a9c2d4f4e5a8b7c6d5e4f3a2b1c"
+  is_default              = true
+}
+
+output "zsphere_virtual_router_offering" {
+  value = zsphere_virtual_router_offering.offering
+}
@@ -0,0 +1,2 @@
+# zsphere_volume can be imported using its UUID
+terraform import zsphere_volume.volume 5a0e7b1f3c6d4f2a9e8b7c6d5e4f3a2b
@@ -0,0 +1,16 @@
+data "zsphere_primary_storages" "storages" {
+  name = "PS-1"
+}
+
+resource "zsphere_volume" "volume" {
+  name                 = "volume-from-terraform"
+  description          = "create a data volume from terraform"
+  size                 = 20
+  primary_storage_uuid = data.zsphere_primary_storages.storages.primary_storages.0.uuid
+  virtio_scsi          = true
+  expunge              = true
+}
+
+output "zsphere_volume" {
+  value = zsphere_volume.volume
+}
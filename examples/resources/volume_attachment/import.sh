@@ -0,0 +1,2 @@
+# zsphere_volume_attachment can be imported using the UUID of the attached volume
+terraform import zsphere_volume_attachment.attachment 5a0e7b1f3c6d4f2a9e8b7c6d5e4f3a2b
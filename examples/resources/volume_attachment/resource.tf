@@ -0,0 +1,9 @@
+resource "zsphere_volume" "volume" {
+  name = "volume-from-terraform"
+  size = 20
+}
+
+resource "zsphere_volume_attachment" "attachment" {
+  volume_uuid   = zsphere_volume.volume.uuid
+  instance_uuid = zsphere_instance.vm.uuid
+}
@@ -0,0 +1,529 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"terraform-provider-zsphere/internal/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+var _ planmodifier.List = dataDisksPlanModifier{}
+
+// ownedDataVolumesKey is the private state key of the data volumes the vm resource created through data_disks.
+// Data volumes taken over on import, or from state written before they were recorded, are not in it:
+// removing them from data_disks only stops managing them, they are never detached or deleted.
+const ownedDataVolumesKey = "owned_data_volumes"
+
+// privateData is the private state of a resource, as passed to and returned by the resource and plan modifier calls.
+type privateData interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// ownedDataVolumes returns the data volumes the vm resource created, by uuid.
+func ownedDataVolumes(ctx context.Context, private privateData) (map[string]bool, diag.Diagnostics) {
+	owned := make(map[string]bool)
+	value, diags := private.GetKey(ctx, ownedDataVolumesKey)
+	if diags.HasError() || len(value) == 0 {
+		return owned, diags
+	}
+
+	var uuids []string
+	if err := json.Unmarshal(value, &uuids); err != nil {
+		diags.AddError("Could not read private state", fmt.Sprintf("invalid %s, err: %v", ownedDataVolumesKey, err))
+		return owned, diags
+	}
+	for _, uuid := range uuids {
+		owned[uuid] = true
+	}
+	return owned, diags
+}
+
+// setOwnedDataVolumes records the data volumes the vm resource created.
+func setOwnedDataVolumes(ctx context.Context, private privateData, owned map[string]bool) diag.Diagnostics {
+	uuids := make([]string, 0, len(owned))
+	for uuid := range owned {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+
+	value, err := json.Marshal(uuids)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Could not write private state", err.Error())
+		return diags
+	}
+	return private.SetKey(ctx, ownedDataVolumesKey, value)
+}
+
+// dataDisksPlanModifier carries the uuid and computed settings of existing data volumes over to
+// the planned data disks, see matchDataDisks. Planned disks without a match are created, volumes
+// in state without a match are removed. Plans that would remove a volume by mistake, because it
+// cannot be told apart from another one or because a setting that cannot be changed in place was
+// changed, are rejected, and every removal is reported.
+type dataDisksPlanModifier struct{}
+
+func (m dataDisksPlanModifier) Description(_ context.Context) string {
+	return "Matches the planned data disks with the data volumes in state."
+}
+
+func (m dataDisksPlanModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m dataDisksPlanModifier) PlanModifyList(ctx context.Context, req planmodifier.ListRequest, resp *planmodifier.ListResponse) {
+	if req.PlanValue.IsNull() || req.PlanValue.IsUnknown() {
+		return
+	}
+
+	var planDisks, configDisks, stateDisks []diskModel
+	resp.Diagnostics.Append(req.PlanValue.ElementsAs(ctx, &planDisks, false)...)
+	resp.Diagnostics.Append(req.ConfigValue.ElementsAs(ctx, &configDisks, false)...)
+	if !req.StateValue.IsNull() {
+		resp.Diagnostics.Append(req.StateValue.ElementsAs(ctx, &stateDisks, false)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// only a configured uuid selects a volume, the plan may hold the uuid of the volume at the same position
+	for i := range planDisks {
+		if i < len(configDisks) && !hasDiskUuid(configDisks[i]) {
+			planDisks[i].Uuid = types.StringUnknown()
+		}
+	}
+
+	if req.StateValue.IsNull() {
+		for i := range planDisks {
+			if hasDiskUuid(planDisks[i]) {
+				resp.Diagnostics.AddAttributeError(
+					req.Path.AtListIndex(i).AtName("uuid"),
+					"Params Error",
+					"uuid can only be set to select a data volume the vm instance already has in data_disks",
+				)
+			}
+		}
+		return
+	}
+
+	owned, diags := ownedDataVolumes(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	matches := matchDataDisks(planDisks, stateDisks)
+	matched := make([]bool, len(stateDisks))
+	for i, j := range matches {
+		if j < 0 {
+			if hasDiskUuid(planDisks[i]) {
+				resp.Diagnostics.AddAttributeError(
+					req.Path.AtListIndex(i).AtName("uuid"),
+					"Params Error",
+					fmt.Sprintf("data volume %s is not in data_disks of the vm instance", planDisks[i].Uuid.ValueString()),
+				)
+			}
+			continue
+		}
+		matched[j] = true
+
+		prior := stateDisks[j]
+		if !samePlacement(planDisks[i], prior) {
+			resp.Diagnostics.AddAttributeError(
+				req.Path.AtListIndex(i),
+				"Params Error",
+				fmt.Sprintf("ceph_pool_name and virtio_scsi of data disk %s cannot be changed in place, "+
+					"remove the disk and add a new data disk instead", prior.Uuid.ValueString()),
+			)
+			continue
+		}
+		if !planDisks[i].Size.IsUnknown() && planDisks[i].Size.ValueInt64() < prior.Size.ValueInt64() {
+			resp.Diagnostics.AddAttributeError(
+				req.Path.AtListIndex(i).AtName("size"),
+				"Params Error",
+				fmt.Sprintf("data disk %s cannot be shrunk from %d GB to %d GB, remove it and add a new data disk instead",
+					prior.Uuid.ValueString(), prior.Size.ValueInt64(), planDisks[i].Size.ValueInt64()),
+			)
+			continue
+		}
+
+		planDisks[i].Uuid = prior.Uuid
+		if planDisks[i].PrimaryStorageUuid.IsUnknown() {
+			planDisks[i].PrimaryStorageUuid = prior.PrimaryStorageUuid
+		}
+		if planDisks[i].CephPoolName.IsUnknown() {
+			planDisks[i].CephPoolName = prior.CephPoolName
+		}
+		if planDisks[i].VirtioSCSI.IsUnknown() {
+			planDisks[i].VirtioSCSI = prior.VirtioSCSI
+		}
+	}
+
+	for j, removed := range stateDisks {
+		if matched[j] || !hasDiskUuid(removed) {
+			continue
+		}
+		if summary, detail := checkDataDiskRemoval(planDisks, stateDisks, matches, j); summary != "" {
+			resp.Diagnostics.AddAttributeError(req.Path, summary, detail)
+			continue
+		}
+
+		if owned[removed.Uuid.ValueString()] {
+			resp.Diagnostics.AddAttributeWarning(req.Path, "Data Volume Deleted",
+				fmt.Sprintf("data volume %s is no longer in data_disks, it is detached and deleted with its data", removed.Uuid.ValueString()))
+		} else {
+			resp.Diagnostics.AddAttributeWarning(req.Path, "Data Volume Released",
+				fmt.Sprintf("data volume %s is no longer in data_disks and was not created by this vm instance, it stays attached but is no longer managed",
+					removed.Uuid.ValueString()))
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planValue, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: diskModelAttrTypes}, planDisks)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.PlanValue = planValue
+}
+
+// checkDataDiskRemoval returns an error summary and detail if the disk at index removed of stateDisks, which no
+// planned disk matches, may be removed by mistake: if it cannot be told apart from a volume that is kept, if it
+// is one of several volumes a new planned disk could be, or if its planned disk at the same position only
+// changes settings that cannot be changed in place.
+func checkDataDiskRemoval(planDisks []diskModel, stateDisks []diskModel, matches []int, removed int) (string, string) {
+	disk := stateDisks[removed]
+	for i, j := range matches {
+		if j >= 0 && !hasDiskUuid(planDisks[i]) && identicalDataDisks(stateDisks[j], disk) {
+			return "Ambiguous Data Disks", fmt.Sprintf("data volumes %s and %s have the same settings, set uuid on the data disks "+
+				"to keep so that the right data volume is removed", stateDisks[j].Uuid.ValueString(), disk.Uuid.ValueString())
+		}
+	}
+
+	for i, j := range matches {
+		if j >= 0 || hasDiskUuid(planDisks[i]) {
+			continue
+		}
+		if samePlacement(planDisks[i], disk) {
+			return "Ambiguous Data Disks", fmt.Sprintf("data disk %d could be data volume %s or another data volume of data_disks, "+
+				"set its uuid to the data volume it refers to", i, disk.Uuid.ValueString())
+		}
+		if i == removed && planDisks[i].Size.Equal(disk.Size) {
+			return "Params Error", fmt.Sprintf("ceph_pool_name and virtio_scsi of data disk %s cannot be changed in place, "+
+				"remove the disk and add a new data disk in a later apply instead", disk.Uuid.ValueString())
+		}
+	}
+	return "", ""
+}
+
+// matchDataDisks returns, for every planned disk, the index of the disk in state it refers to, or -1 for a new disk.
+// A planned disk with a uuid refers to the disk in state with that uuid. Any other planned disk refers to a disk
+// in state with exactly the same settings, or else to the only disk in state it can be grown or migrated to if
+// that disk has no other candidate either. Disks are never matched by their position.
+func matchDataDisks(planDisks []diskModel, stateDisks []diskModel) []int {
+	matches := make([]int, len(planDisks))
+	matched := make([]bool, len(stateDisks))
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	for i := range planDisks {
+		if !hasDiskUuid(planDisks[i]) {
+			continue
+		}
+		for j := range stateDisks {
+			if !matched[j] && stateDisks[j].Uuid.Equal(planDisks[i].Uuid) {
+				matches[i] = j
+				matched[j] = true
+				break
+			}
+		}
+	}
+
+	for i := range planDisks {
+		if hasDiskUuid(planDisks[i]) {
+			continue
+		}
+		for j := range stateDisks {
+			if !matched[j] && hasDiskUuid(stateDisks[j]) &&
+				sameDataDisk(planDisks[i], stateDisks[j]) && planDisks[i].Size.Equal(stateDisks[j].Size) {
+				matches[i] = j
+				matched[j] = true
+				break
+			}
+		}
+	}
+
+	candidates := func(i int, j int) bool {
+		return matches[i] < 0 && !hasDiskUuid(planDisks[i]) && !matched[j] && hasDiskUuid(stateDisks[j]) &&
+			samePlacement(planDisks[i], stateDisks[j])
+	}
+	for i := range planDisks {
+		only := -1
+		for j := range stateDisks {
+			if !candidates(i, j) {
+				continue
+			}
+			if only >= 0 {
+				only = -1
+				break
+			}
+			only = j
+		}
+		if only < 0 {
+			continue
+		}
+
+		others := false
+		for k := range planDisks {
+			if k != i && candidates(k, only) {
+				others = true
+				break
+			}
+		}
+		if !others {
+			matches[i] = only
+			matched[only] = true
+		}
+	}
+
+	return matches
+}
+
+// hasDiskUuid reports whether a disk refers to a data volume.
+func hasDiskUuid(disk diskModel) bool {
+	return !disk.Uuid.IsNull() && !disk.Uuid.IsUnknown() && disk.Uuid.ValueString() != ""
+}
+
+// sameDataDisk reports whether a planned disk has the same settings as a disk in state.
+// Settings left unknown in the plan are not configured and match anything.
+func sameDataDisk(planned diskModel, prior diskModel) bool {
+	return samePlacement(planned, prior) &&
+		(planned.PrimaryStorageUuid.IsUnknown() || planned.PrimaryStorageUuid.Equal(prior.PrimaryStorageUuid))
+}
+
+// samePlacement reports whether a planned disk can be turned into a disk in state without replacing it.
+func samePlacement(planned diskModel, prior diskModel) bool {
+	return (planned.CephPoolName.IsUnknown() || planned.CephPoolName.Equal(prior.CephPoolName)) &&
+		(planned.VirtioSCSI.IsUnknown() || planned.VirtioSCSI.Equal(prior.VirtioSCSI))
+}
+
+// identicalDataDisks reports whether two disks in state have the same settings.
+func identicalDataDisks(a diskModel, b diskModel) bool {
+	return a.Size.Equal(b.Size) && a.PrimaryStorageUuid.Equal(b.PrimaryStorageUuid) &&
+		a.CephPoolName.Equal(b.CephPoolName) && a.VirtioSCSI.Equal(b.VirtioSCSI)
+}
+
+// updateDataDisks applies the planned data disks to a vm instance: owned data volumes no longer planned are detached
+// and deleted, other ones are only no longer managed, grown disks are resized, disks with a new primary storage are
+// migrated and new disks are created and attached. owned is updated with the volumes created and deleted.
+// It returns the data disks the vm ends up with, also when it fails half way.
+func updateDataDisks(ctx context.Context, cli *client.ZSClient, vm *view.VmInstanceInventoryView, planDisks []diskModel, stateDisks []diskModel, owned map[string]bool, expunge bool) ([]diskModel, error) {
+	planned := make(map[string]bool)
+	for _, disk := range planDisks {
+		if !disk.Uuid.IsUnknown() && disk.Uuid.ValueString() != "" {
+			planned[disk.Uuid.ValueString()] = true
+		}
+	}
+
+	var removed []diskModel
+	priorDisks := make(map[string]diskModel)
+	for _, disk := range stateDisks {
+		if disk.Uuid.ValueString() == "" {
+			continue
+		}
+		priorDisks[disk.Uuid.ValueString()] = disk
+		if !planned[disk.Uuid.ValueString()] {
+			removed = append(removed, disk)
+		}
+	}
+
+	for i, disk := range removed {
+		if !owned[disk.Uuid.ValueString()] {
+			tflog.Warn(ctx, fmt.Sprintf("data volume %s was not created by vm instance %s, keep it attached and stop managing it", disk.Uuid.ValueString(), vm.UUID))
+			continue
+		}
+		err := deleteDataDiskVolume(ctx, cli, vm.UUID, disk.Uuid.ValueString(), expunge)
+		if err != nil {
+			return append(knownDataDisks(planDisks), removed[i:]...), err
+		}
+		delete(owned, disk.Uuid.ValueString())
+	}
+
+	for i := range planDisks {
+		disk := planDisks[i]
+		prior, ok := priorDisks[disk.Uuid.ValueString()]
+		if !disk.Uuid.IsUnknown() && ok {
+			if disk.Size.ValueInt64() > prior.Size.ValueInt64() {
+				tflog.Info(ctx, fmt.Sprintf("resize data volume %s to %d GB", prior.Uuid.ValueString(), disk.Size.ValueInt64()))
+				_, err := runJob(ctx, job{
+					name:         "resize data volume",
+					resourceUuid: prior.Uuid.ValueString(),
+				}, func() (any, error) {
+					return cli.ResizeDataVolume(prior.Uuid.ValueString(), param.ResizeDataVolumeParam{
+						ResizeDataVolume: param.ResizeDataVolumeDetailParam{
+							Size: utils.GBToBytes(disk.Size.ValueInt64()),
+						},
+					})
+				})
+				if err != nil {
+					return knownDataDisks(planDisks), fmt.Errorf("fail to resize data volume %s, err: %w", prior.Uuid.ValueString(), err)
+				}
+			}
+
+			if !disk.PrimaryStorageUuid.IsUnknown() && disk.PrimaryStorageUuid.ValueString() != "" &&
+				!disk.PrimaryStorageUuid.Equal(prior.PrimaryStorageUuid) {
+				err := migrateVolume(ctx, cli, prior.Uuid.ValueString(), disk.PrimaryStorageUuid.ValueString())
+				if err != nil {
+					return knownDataDisks(planDisks), err
+				}
+			}
+			continue
+		}
+
+		volume, err := createDataDiskVolume(ctx, cli, vm, disk)
+		if err != nil {
+			return knownDataDisks(planDisks), err
+		}
+		planDisks[i].Uuid = types.StringValue(volume.UUID)
+		owned[volume.UUID] = true
+	}
+
+	return planDisks, nil
+}
+
+// knownDataDisks returns the disks that already have a data volume.
+func knownDataDisks(disks []diskModel) []diskModel {
+	var known []diskModel
+	for _, disk := range disks {
+		if !disk.Uuid.IsUnknown() && disk.Uuid.ValueString() != "" {
+			known = append(known, disk)
+		}
+	}
+	return known
+}
+
+// createDataDiskVolume creates a data volume with the settings of a data disk and attaches it to the vm instance.
+func createDataDiskVolume(ctx context.Context, cli *client.ZSClient, vm *view.VmInstanceInventoryView, disk diskModel) (*view.VolumeView, error) {
+	if disk.Size.IsNull() || disk.Size.IsUnknown() {
+		return nil, fmt.Errorf("dataDisk offering_uuid and size cannot be null at the same time")
+	}
+
+	err := isDiskParamValid(cli, disk)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dataDisk param, err: %v", err)
+	}
+
+	var systemTags []string
+	if !disk.CephPoolName.IsUnknown() && disk.CephPoolName.ValueString() != "" {
+		systemTags = append(systemTags, fmt.Sprintf("ceph::pool::%s", disk.CephPoolName.ValueString()))
+	}
+	if disk.VirtioSCSI.ValueBool() {
+		systemTags = append(systemTags, volumeVirtioSCSISystemTag)
+	}
+
+	primaryStorageUuid := ""
+	if !disk.PrimaryStorageUuid.IsUnknown() {
+		primaryStorageUuid = disk.PrimaryStorageUuid.ValueString()
+	}
+
+	// the uuid is assigned up front so that the volume can be looked up if the job does not finish in time
+	resourceUuid, err := newResourceUuid()
+	if err != nil {
+		return nil, err
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("create %d GB data volume %s for vm instance %s", disk.Size.ValueInt64(), resourceUuid, vm.UUID))
+	volume, err := runJob(ctx, job{
+		name:         "create data volume",
+		resourceUuid: resourceUuid,
+	}, func() (*view.VolumeView, error) {
+		return cli.CreateDataVolume(param.CreateDataVolumeParam{
+			BaseParam: param.BaseParam{
+				SystemTags: systemTags,
+			},
+			Params: param.CreateDataVolumeDetailParam{
+				Name:               fmt.Sprintf("DATA-for-%s", vm.Name),
+				DiskSize:           utils.GBToBytes(disk.Size.ValueInt64()),
+				PrimaryStorageUuid: primaryStorageUuid,
+				ResourceUuid:       resourceUuid,
+			},
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to create data volume, err: %w", err)
+	}
+
+	_, err = runJob(ctx, job{
+		name:         "attach data volume",
+		resourceUuid: volume.UUID,
+	}, func() (any, error) {
+		return cli.AttachDataVolumeToVm(volume.UUID, vm.UUID)
+	})
+	if err != nil {
+		// the volume is not tracked anywhere yet, so do not leave it behind, unless it may still be attached
+		if errors.Is(err, errJobNotFinished) {
+			return nil, fmt.Errorf("data volume %s may still be attached to vm instance %s, err: %w", volume.UUID, vm.UUID, err)
+		}
+		if deleteErr := deleteDataDiskVolume(ctx, cli, "", volume.UUID, true); deleteErr != nil {
+			tflog.Warn(ctx, fmt.Sprintf("fail to clean up data volume %s, err: %v", volume.UUID, deleteErr))
+		}
+		return nil, fmt.Errorf("fail to attach data volume %s to vm instance %s, err: %w", volume.UUID, vm.UUID, err)
+	}
+
+	return volume, nil
+}
+
+// deleteDataDiskVolume detaches a data volume from the vm instance, if any, and deletes it.
+func deleteDataDiskVolume(ctx context.Context, cli *client.ZSClient, vmUuid string, volumeUuid string, expunge bool) error {
+	if vmUuid != "" {
+		tflog.Info(ctx, fmt.Sprintf("detach data volume %s from vm instance %s", volumeUuid, vmUuid))
+		_, err := runJob(ctx, job{
+			name:         "detach data volume",
+			resourceUuid: volumeUuid,
+		}, func() (any, error) {
+			return cli.DetachDataVolumeFromVm(volumeUuid, vmUuid)
+		})
+		if err != nil {
+			return fmt.Errorf("fail to detach data volume %s, err: %w", volumeUuid, err)
+		}
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("delete data volume %s", volumeUuid))
+	_, err := runJob(ctx, job{
+		name:         "delete data volume",
+		resourceUuid: volumeUuid,
+	}, func() (any, error) {
+		return nil, cli.DeleteDataVolume(volumeUuid, param.DeleteModePermissive)
+	})
+	if err != nil {
+		return fmt.Errorf("fail to delete data volume %s, err: %w", volumeUuid, err)
+	}
+
+	if expunge {
+		_, err = runJob(ctx, job{
+			name:         "expunge data volume",
+			resourceUuid: volumeUuid,
+		}, func() (any, error) {
+			return nil, cli.ExpungeDataVolume(volumeUuid)
+		})
+		if err != nil {
+			return fmt.Errorf("fail to expunge data volume %s, err: %w", volumeUuid, err)
+		}
+	}
+	return nil
+}
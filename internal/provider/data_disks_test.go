@@ -0,0 +1,203 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// stateDisk is a data disk in state, with every setting known.
+func stateDisk(uuid string, size int64) diskModel {
+	return diskModel{
+		Uuid:               types.StringValue(uuid),
+		Size:               types.Int64Value(size),
+		PrimaryStorageUuid: types.StringValue("ps"),
+		CephPoolName:       types.StringNull(),
+		VirtioSCSI:         types.BoolValue(false),
+	}
+}
+
+// plannedDisk is a configured data disk: uuid and the computed settings are unknown unless set.
+func plannedDisk(size int64) diskModel {
+	return diskModel{
+		Uuid:               types.StringUnknown(),
+		Size:               types.Int64Value(size),
+		PrimaryStorageUuid: types.StringUnknown(),
+		CephPoolName:       types.StringUnknown(),
+		VirtioSCSI:         types.BoolUnknown(),
+	}
+}
+
+func withUuid(disk diskModel, uuid string) diskModel {
+	disk.Uuid = types.StringValue(uuid)
+	return disk
+}
+
+func withVirtio(disk diskModel) diskModel {
+	disk.VirtioSCSI = types.BoolValue(true)
+	return disk
+}
+
+func withPrimaryStorage(disk diskModel, uuid string) diskModel {
+	disk.PrimaryStorageUuid = types.StringValue(uuid)
+	return disk
+}
+
+func TestMatchDataDisks(t *testing.T) {
+	tests := []struct {
+		name  string
+		plan  []diskModel
+		state []diskModel
+		want  []int
+	}{
+		{
+			name:  "unchanged",
+			plan:  []diskModel{plannedDisk(10), plannedDisk(20)},
+			state: []diskModel{stateDisk("a", 10), stateDisk("b", 20)},
+			want:  []int{0, 1},
+		},
+		{
+			name:  "reordered by settings, not position",
+			plan:  []diskModel{plannedDisk(20), plannedDisk(10)},
+			state: []diskModel{stateDisk("a", 10), stateDisk("b", 20)},
+			want:  []int{1, 0},
+		},
+		{
+			name:  "middle disk removed",
+			plan:  []diskModel{plannedDisk(10), plannedDisk(30)},
+			state: []diskModel{stateDisk("a", 10), stateDisk("b", 20), stateDisk("c", 30)},
+			want:  []int{0, 2},
+		},
+		{
+			name:  "selected by uuid",
+			plan:  []diskModel{withUuid(plannedDisk(20), "c"), withUuid(plannedDisk(20), "a")},
+			state: []diskModel{stateDisk("a", 20), stateDisk("b", 20), stateDisk("c", 20)},
+			want:  []int{2, 0},
+		},
+		{
+			name:  "unknown uuid",
+			plan:  []diskModel{withUuid(plannedDisk(20), "x")},
+			state: []diskModel{stateDisk("a", 20)},
+			want:  []int{-1},
+		},
+		{
+			name:  "only candidate is grown",
+			plan:  []diskModel{plannedDisk(10), plannedDisk(40)},
+			state: []diskModel{stateDisk("a", 10), stateDisk("b", 20)},
+			want:  []int{0, 1},
+		},
+		{
+			name:  "only candidate is migrated",
+			plan:  []diskModel{withPrimaryStorage(plannedDisk(20), "ps2")},
+			state: []diskModel{stateDisk("a", 20)},
+			want:  []int{0},
+		},
+		{
+			name:  "several candidates to grow",
+			plan:  []diskModel{plannedDisk(40)},
+			state: []diskModel{stateDisk("a", 10), stateDisk("b", 20)},
+			want:  []int{-1},
+		},
+		{
+			name:  "candidate of several planned disks",
+			plan:  []diskModel{plannedDisk(30), plannedDisk(40)},
+			state: []diskModel{stateDisk("a", 10)},
+			want:  []int{-1, -1},
+		},
+		{
+			name:  "changed virtio_scsi is a new disk",
+			plan:  []diskModel{withVirtio(plannedDisk(20))},
+			state: []diskModel{stateDisk("a", 20)},
+			want:  []int{-1},
+		},
+		{
+			name:  "added disk",
+			plan:  []diskModel{plannedDisk(10), plannedDisk(20)},
+			state: []diskModel{stateDisk("a", 10)},
+			want:  []int{0, -1},
+		},
+		{
+			name:  "legacy disk without uuid",
+			plan:  []diskModel{plannedDisk(10)},
+			state: []diskModel{{Uuid: types.StringNull(), Size: types.Int64Value(10)}},
+			want:  []int{-1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchDataDisks(tt.plan, tt.state); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matchDataDisks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDataDiskRemoval(t *testing.T) {
+	tests := []struct {
+		name    string
+		plan    []diskModel
+		state   []diskModel
+		removed int
+		want    string
+	}{
+		{
+			name:    "distinct disk removed",
+			plan:    []diskModel{plannedDisk(10), plannedDisk(30)},
+			state:   []diskModel{stateDisk("a", 10), stateDisk("b", 20), stateDisk("c", 30)},
+			removed: 1,
+			want:    "",
+		},
+		{
+			name:    "one of identical disks removed",
+			plan:    []diskModel{plannedDisk(20), plannedDisk(20)},
+			state:   []diskModel{stateDisk("a", 20), stateDisk("b", 20), stateDisk("c", 20)},
+			removed: 2,
+			want:    "Ambiguous Data Disks",
+		},
+		{
+			name:    "identical disks kept by uuid",
+			plan:    []diskModel{withUuid(plannedDisk(20), "a"), withUuid(plannedDisk(20), "c")},
+			state:   []diskModel{stateDisk("a", 20), stateDisk("b", 20), stateDisk("c", 20)},
+			removed: 1,
+			want:    "",
+		},
+		{
+			name:    "several candidates to grow",
+			plan:    []diskModel{plannedDisk(40)},
+			state:   []diskModel{stateDisk("a", 10), stateDisk("b", 20)},
+			removed: 0,
+			want:    "Ambiguous Data Disks",
+		},
+		{
+			name:    "virtio_scsi changed in place",
+			plan:    []diskModel{withVirtio(plannedDisk(20))},
+			state:   []diskModel{stateDisk("a", 20)},
+			removed: 0,
+			want:    "Params Error",
+		},
+		{
+			name:    "disk replaced by a different one",
+			plan:    []diskModel{withVirtio(plannedDisk(50))},
+			state:   []diskModel{stateDisk("a", 20)},
+			removed: 0,
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := matchDataDisks(tt.plan, tt.state)
+			if slices.Contains(matches, tt.removed) {
+				t.Fatalf("disk %d is matched by %v, it is not removed", tt.removed, matches)
+			}
+			if got, _ := checkDataDiskRemoval(tt.plan, tt.state, matches, tt.removed); got != tt.want {
+				t.Errorf("checkDataDiskRemoval() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
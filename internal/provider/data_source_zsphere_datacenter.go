@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"terraform-provider-zsphere/internal/utils"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
 	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
 )
 
 var (
@@ -31,6 +34,7 @@ type zoneDataSourceModel struct {
 	Name        types.String `tfsdk:"name"`
 	NamePattern types.String `tfsdk:"name_pattern"`
 	Filter      []Filter     `tfsdk:"filter"`
+	FilterMatch types.String `tfsdk:"filter_match"`
 	Zones       []zoneModel  `tfsdk:"data_centers"`
 }
 
@@ -72,6 +76,13 @@ func (d *zoneDataSource) Schema(_ context.Context, req datasource.SchemaRequest,
 				Description: "Exact name for Searching  data centers",
 				Optional:    true,
 			},
+			"filter_match": schema.StringAttribute{
+				Description: "How the `filter` blocks are combined: `all` returns the resources that match every filter, `any` the resources that match at least one. Defaults to `all`.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(utils.MatchAll, utils.MatchAny),
+				},
+			},
 			"name_pattern": schema.StringAttribute{
 				Description: "Pattern for fuzzy name search, similar to MySQL LIKE. Use % for multiple characters and _ for exactly one character.",
 				Optional:    true,
@@ -103,11 +114,20 @@ func (d *zoneDataSource) Schema(_ context.Context, req datasource.SchemaRequest,
 				NestedObject: schema.NestedBlockObject{
 					Attributes: map[string]schema.Attribute{
 						"name": schema.StringAttribute{
-							Description: "Name of the field to filter by (e.g., status, state).",
+							Description: "Name of the field to filter by (e.g., status, state). Use a dotted path for a field of a nested object or list; it matches if any element matches.",
 							Required:    true,
 						},
+						"operator": schema.StringAttribute{
+							Description: "How the field is compared with the values: `=` (default) or `in` if it equals any value, `!=` or `not_in` if it equals none, " +
+								"`regex` if it matches any regular expression, `prefix` if it starts with any value, " +
+								"or `<`, `<=`, `>`, `>=` to compare a numeric field with a single number.",
+							Optional: true,
+							Validators: []validator.String{
+								stringvalidator.OneOf(utils.FilterOperators...),
+							},
+						},
 						"values": schema.SetAttribute{
-							Description: "Values to filter by. Multiple values will be treated as an OR condition.",
+							Description: "Values to filter by. Multiple values will be treated as an OR condition, except for `!=` and `not_in`.",
 							Required:    true,
 							ElementType: types.StringType,
 						},
@@ -125,6 +145,11 @@ func (d *zoneDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 	resp.Diagnostics.Append(diags...)
 
 	name := state.Name
+	filters := filterConditions(ctx, state.Filter, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	params := param.NewQueryParam()
 
 	if !name.IsNull() {
@@ -133,6 +158,8 @@ func (d *zoneDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 		params.AddQ("name~=" + state.NamePattern.ValueString())
 	}
 
+	utils.AddQueryConditions[view.ZoneView](&params, filters, state.FilterMatch.ValueString(), "zone")
+
 	zones, err := d.client.QueryZone(params)
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -142,18 +169,7 @@ func (d *zoneDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 		return
 	}
 
-	filters := make(map[string][]string)
-	for _, filter := range state.Filter {
-		values := make([]string, 0, len(filter.Values.Elements()))
-		diags := filter.Values.ElementsAs(ctx, &values, false)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		filters[filter.Name.ValueString()] = values
-	}
-
-	filterZones, filterDiags := utils.FilterResource(ctx, zones, filters, "zone")
+	filterZones, filterDiags := utils.FilterResource(ctx, zones, filters, state.FilterMatch.ValueString(), "zone")
 	resp.Diagnostics.Append(filterDiags...)
 	if resp.Diagnostics.HasError() {
 		return
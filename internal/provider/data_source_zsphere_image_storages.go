@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"terraform-provider-zsphere/internal/utils"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
 	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
 )
 
 var (
@@ -36,6 +39,7 @@ type backupStorageDataSourceModel struct {
 	Name          types.String    `tfsdk:"name"`
 	NamePattern   types.String    `tfsdk:"name_pattern"`
 	Filter        []Filter        `tfsdk:"filter"`
+	FilterMatch   types.String    `tfsdk:"filter_match"`
 	BackupStorges []backupStorage `tfsdk:"image_storages"`
 }
 
@@ -76,6 +80,11 @@ func (d *backupStorageDataSource) Read(ctx context.Context, req datasource.ReadR
 		return
 	}
 
+	filters := filterConditions(ctx, state.Filter, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	params := param.NewQueryParam()
 
 	if !state.Name.IsNull() {
@@ -84,6 +93,8 @@ func (d *backupStorageDataSource) Read(ctx context.Context, req datasource.ReadR
 		params.AddQ("name~=" + state.NamePattern.ValueString())
 	}
 
+	utils.AddQueryConditions[view.BackupStorageInventoryView](&params, filters, state.FilterMatch.ValueString(), "backup_storage")
+
 	backupstorages, err := d.client.QueryBackupStorage(params)
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -93,18 +104,7 @@ func (d *backupStorageDataSource) Read(ctx context.Context, req datasource.ReadR
 		return
 	}
 
-	filters := make(map[string][]string)
-	for _, filter := range state.Filter {
-		values := make([]string, 0, len(filter.Values.Elements()))
-		diags := filter.Values.ElementsAs(ctx, &values, false)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		filters[filter.Name.ValueString()] = values
-	}
-
-	filterImageStorage, filterDiags := utils.FilterResource(ctx, backupstorages, filters, "backup_storage")
+	filterImageStorage, filterDiags := utils.FilterResource(ctx, backupstorages, filters, state.FilterMatch.ValueString(), "backup_storage")
 	resp.Diagnostics.Append(filterDiags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -140,6 +140,13 @@ func (d *backupStorageDataSource) Schema(ctx context.Context, req datasource.Sch
 				Description: "Exact name for searching Image storage.",
 				Optional:    true,
 			},
+			"filter_match": schema.StringAttribute{
+				Description: "How the `filter` blocks are combined: `all` returns the resources that match every filter, `any` the resources that match at least one. Defaults to `all`.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(utils.MatchAll, utils.MatchAny),
+				},
+			},
 			"name_pattern": schema.StringAttribute{
 				Description: "Pattern for fuzzy name search, similar to MySQL LIKE. Use % for multiple characters and _ for exactly one character.",
 				Optional:    true,
@@ -184,11 +191,20 @@ func (d *backupStorageDataSource) Schema(ctx context.Context, req datasource.Sch
 				NestedObject: schema.NestedBlockObject{
 					Attributes: map[string]schema.Attribute{
 						"name": schema.StringAttribute{
-							Description: "Name of the field to filter by (e.g., status, state).",
+							Description: "Name of the field to filter by (e.g., status, state). Use a dotted path for a field of a nested object or list; it matches if any element matches.",
 							Required:    true,
 						},
+						"operator": schema.StringAttribute{
+							Description: "How the field is compared with the values: `=` (default) or `in` if it equals any value, `!=` or `not_in` if it equals none, " +
+								"`regex` if it matches any regular expression, `prefix` if it starts with any value, " +
+								"or `<`, `<=`, `>`, `>=` to compare a numeric field, such as `available_capacity` in bytes, with a single number.",
+							Optional: true,
+							Validators: []validator.String{
+								stringvalidator.OneOf(utils.FilterOperators...),
+							},
+						},
 						"values": schema.SetAttribute{
-							Description: "Values to filter by. Multiple values will be treated as an OR condition.",
+							Description: "Values to filter by. Multiple values will be treated as an OR condition, except for `!=` and `not_in`.",
 							Required:    true,
 							ElementType: types.StringType,
 						},
@@ -0,0 +1,229 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-provider-zsphere/internal/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+var (
+	_ datasource.DataSource              = &l2NetworkDataSource{}
+	_ datasource.DataSourceWithConfigure = &l2NetworkDataSource{}
+)
+
+func ZSphereL2NetworkDataSource() datasource.DataSource {
+	return &l2NetworkDataSource{}
+}
+
+type l2NetworkDataSource struct {
+	client *client.ZSClient
+}
+
+type l2NetworkDataSourceModel struct {
+	Name        types.String     `tfsdk:"name"`
+	NamePattern types.String     `tfsdk:"name_pattern"`
+	Filter      []Filter         `tfsdk:"filter"`
+	FilterMatch types.String     `tfsdk:"filter_match"`
+	L2Networks  []l2NetworkModel `tfsdk:"l2_networks"`
+}
+
+type l2NetworkModel struct {
+	Uuid                 types.String   `tfsdk:"uuid"`
+	Name                 types.String   `tfsdk:"name"`
+	Description          types.String   `tfsdk:"description"`
+	Type                 types.String   `tfsdk:"type"`
+	ZoneUuid             types.String   `tfsdk:"zone_uuid"`
+	PhysicalInterface    types.String   `tfsdk:"physical_interface"`
+	Vlan                 types.Int64    `tfsdk:"vlan"`
+	VSwitchType          types.String   `tfsdk:"v_switch_type"`
+	AttachedClusterUuids []types.String `tfsdk:"attached_cluster_uuids"`
+}
+
+// Configure implements datasource.DataSourceWithConfigure.
+func (d *l2NetworkDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.ZSClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.ZSClient, got: %T. Please report this issue to the Provider developer. ", req.ProviderData),
+		)
+		return
+	}
+	d.client = client
+}
+
+func (d *l2NetworkDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_l2_networks"
+}
+
+func (d *l2NetworkDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Fetches a list of L2 networks and their associated attributes.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Description: "Exact name for searching L2 networks.",
+				Optional:    true,
+			},
+			"filter_match": schema.StringAttribute{
+				Description: "How the `filter` blocks are combined: `all` returns the resources that match every filter, `any` the resources that match at least one. Defaults to `all`.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(utils.MatchAll, utils.MatchAny),
+				},
+			},
+			"name_pattern": schema.StringAttribute{
+				Description: "Pattern for fuzzy name search, similar to MySQL LIKE. Use % for multiple characters and _ for exactly one character.",
+				Optional:    true,
+			},
+			"l2_networks": schema.ListNestedAttribute{
+				Description: "List of L2 networks matching the specified filters.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"uuid": schema.StringAttribute{
+							Computed:    true,
+							Description: "UUID of the L2 network.",
+						},
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "Name of the L2 network.",
+						},
+						"description": schema.StringAttribute{
+							Computed:    true,
+							Description: "Description of the L2 network.",
+						},
+						"type": schema.StringAttribute{
+							Computed:    true,
+							Description: "Type of the L2 network (e.g., L2NoVlanNetwork, L2VlanNetwork, VxlanNetworkPool).",
+						},
+						"zone_uuid": schema.StringAttribute{
+							Computed:    true,
+							Description: "UUID of the zone of the L2 network.",
+						},
+						"physical_interface": schema.StringAttribute{
+							Computed:    true,
+							Description: "Physical interface of the hosts the L2 network is bridged to.",
+						},
+						"vlan": schema.Int64Attribute{
+							Computed:    true,
+							Description: "VLAN ID of an L2VlanNetwork, 0 for the other types.",
+						},
+						"v_switch_type": schema.StringAttribute{
+							Computed:    true,
+							Description: "Type of the virtual switch of the L2 network (e.g., LinuxBridge).",
+						},
+						"attached_cluster_uuids": schema.ListAttribute{
+							Computed:    true,
+							ElementType: types.StringType,
+							Description: "UUIDs of the clusters the L2 network is attached to.",
+						},
+					},
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"filter": schema.ListNestedBlock{
+				Description: "Filter resources based on any field in the schema. For example, to filter by status, use `name = \"status\"` and `values = [\"Ready\"]`.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "Name of the field to filter by (e.g., status, state). Use a dotted path for a field of a nested object or list; it matches if any element matches.",
+							Required:    true,
+						},
+						"operator": schema.StringAttribute{
+							Description: "How the field is compared with the values: `=` (default) or `in` if it equals any value, `!=` or `not_in` if it equals none, " +
+								"`regex` if it matches any regular expression, `prefix` if it starts with any value, " +
+								"or `<`, `<=`, `>`, `>=` to compare a numeric field, such as `vlan`, with a single number.",
+							Optional: true,
+							Validators: []validator.String{
+								stringvalidator.OneOf(utils.FilterOperators...),
+							},
+						},
+						"values": schema.SetAttribute{
+							Description: "Values to filter by. Multiple values will be treated as an OR condition, except for `!=` and `not_in`.",
+							Required:    true,
+							ElementType: types.StringType,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *l2NetworkDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state l2NetworkDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	filters := filterConditions(ctx, state.Filter, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	params := param.NewQueryParam()
+
+	if !state.Name.IsNull() {
+		params.AddQ("name=" + state.Name.ValueString())
+	} else if !state.NamePattern.IsNull() {
+		params.AddQ("name~=" + state.NamePattern.ValueString())
+	}
+
+	utils.AddQueryConditions[view.L2NetworkInventoryView](&params, filters, state.FilterMatch.ValueString(), "l2network")
+
+	l2Networks, err := d.client.QueryL2Network(params)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read ZSphere L2 Networks",
+			err.Error(),
+		)
+		return
+	}
+
+	filterL2Networks, filterDiags := utils.FilterResource(ctx, l2Networks, filters, state.FilterMatch.ValueString(), "l2network")
+	resp.Diagnostics.Append(filterDiags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, l2Network := range filterL2Networks {
+		l2NetworkState := l2NetworkModel{
+			Uuid:                 types.StringValue(l2Network.UUID),
+			Name:                 types.StringValue(l2Network.Name),
+			Description:          types.StringValue(l2Network.Description),
+			Type:                 types.StringValue(l2Network.Type),
+			ZoneUuid:             types.StringValue(l2Network.ZoneUuid),
+			PhysicalInterface:    types.StringValue(l2Network.PhysicalInterface),
+			Vlan:                 types.Int64Value(int64(l2Network.Vlan)),
+			VSwitchType:          types.StringValue(l2Network.VSwitchType),
+			AttachedClusterUuids: []types.String{},
+		}
+		for _, clusterUuid := range l2Network.AttachedClusterUuids {
+			l2NetworkState.AttachedClusterUuids = append(l2NetworkState.AttachedClusterUuids, types.StringValue(clusterUuid))
+		}
+
+		state.L2Networks = append(state.L2Networks, l2NetworkState)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
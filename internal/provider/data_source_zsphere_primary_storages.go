@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"terraform-provider-zsphere/internal/utils"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
 	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
 )
 
 var (
@@ -39,6 +42,7 @@ type primaryStorageDataSourceModel struct {
 	Name           types.String     `tfsdk:"name"`
 	NamePattern    types.String     `tfsdk:"name_pattern"`
 	Filter         []Filter         `tfsdk:"filter"`
+	FilterMatch    types.String     `tfsdk:"filter_match"`
 	PrimaryStorges []primaryStorage `tfsdk:"primary_storages"`
 }
 
@@ -79,6 +83,11 @@ func (d *primaryStorageDataSource) Read(ctx context.Context, req datasource.Read
 		return
 	}
 
+	filters := filterConditions(ctx, state.Filter, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	params := param.NewQueryParam()
 
 	if !state.Name.IsNull() {
@@ -87,6 +96,8 @@ func (d *primaryStorageDataSource) Read(ctx context.Context, req datasource.Read
 		params.AddQ("name~=" + state.NamePattern.ValueString())
 	}
 
+	utils.AddQueryConditions[view.PrimaryStorageInventoryView](&params, filters, state.FilterMatch.ValueString(), "primary_storage")
+
 	primaryStorages, err := d.client.QueryPrimaryStorage(params)
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -96,18 +107,7 @@ func (d *primaryStorageDataSource) Read(ctx context.Context, req datasource.Read
 		return
 	}
 
-	filters := make(map[string][]string)
-	for _, filter := range state.Filter {
-		values := make([]string, 0, len(filter.Values.Elements()))
-		diags := filter.Values.ElementsAs(ctx, &values, false)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		filters[filter.Name.ValueString()] = values
-	}
-
-	filterPrimaryStorage, filterDiags := utils.FilterResource(ctx, primaryStorages, filters, "primary_storage")
+	filterPrimaryStorage, filterDiags := utils.FilterResource(ctx, primaryStorages, filters, state.FilterMatch.ValueString(), "primary_storage")
 	resp.Diagnostics.Append(filterDiags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -146,6 +146,13 @@ func (d *primaryStorageDataSource) Schema(ctx context.Context, req datasource.Sc
 				Description: "Exact name for searching primary storage.",
 				Optional:    true,
 			},
+			"filter_match": schema.StringAttribute{
+				Description: "How the `filter` blocks are combined: `all` returns the resources that match every filter, `any` the resources that match at least one. Defaults to `all`.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(utils.MatchAll, utils.MatchAny),
+				},
+			},
 			"name_pattern": schema.StringAttribute{
 				Description: "Pattern for fuzzy name search, similar to MySQL LIKE. Use % for multiple characters and _ for exactly one character.",
 				Optional:    true,
@@ -202,11 +209,20 @@ func (d *primaryStorageDataSource) Schema(ctx context.Context, req datasource.Sc
 				NestedObject: schema.NestedBlockObject{
 					Attributes: map[string]schema.Attribute{
 						"name": schema.StringAttribute{
-							Description: "Name of the field to filter by (e.g., status, state).",
+							Description: "Name of the field to filter by (e.g., status, state). Use a dotted path for a field of a nested object or list; it matches if any element matches.",
 							Required:    true,
 						},
+						"operator": schema.StringAttribute{
+							Description: "How the field is compared with the values: `=` (default) or `in` if it equals any value, `!=` or `not_in` if it equals none, " +
+								"`regex` if it matches any regular expression, `prefix` if it starts with any value, " +
+								"or `<`, `<=`, `>`, `>=` to compare a numeric field, such as `available_capacity` in bytes, with a single number.",
+							Optional: true,
+							Validators: []validator.String{
+								stringvalidator.OneOf(utils.FilterOperators...),
+							},
+						},
 						"values": schema.SetAttribute{
-							Description: "Values to filter by. Multiple values will be treated as an OR condition.",
+							Description: "Values to filter by. Multiple values will be treated as an OR condition, except for `!=` and `not_in`.",
 							Required:    true,
 							ElementType: types.StringType,
 						},
@@ -0,0 +1,279 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"terraform-provider-zsphere/internal/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+var (
+	_ datasource.DataSource              = &securityGroupDataSource{}
+	_ datasource.DataSourceWithConfigure = &securityGroupDataSource{}
+)
+
+func ZSphereSecurityGroupDataSource() datasource.DataSource {
+	return &securityGroupDataSource{}
+}
+
+type securityGroupDataSource struct {
+	client *client.ZSClient
+}
+
+type securityGroupDataSourceModel struct {
+	Name           types.String         `tfsdk:"name"`
+	NamePattern    types.String         `tfsdk:"name_pattern"`
+	Filter         []Filter             `tfsdk:"filter"`
+	FilterMatch    types.String         `tfsdk:"filter_match"`
+	SecurityGroups []securityGroupModel `tfsdk:"security_groups"`
+}
+
+type securityGroupModel struct {
+	Uuid                   types.String             `tfsdk:"uuid"`
+	Name                   types.String             `tfsdk:"name"`
+	Description            types.String             `tfsdk:"description"`
+	State                  types.String             `tfsdk:"state"`
+	IpVersion              types.Int64              `tfsdk:"ip_version"`
+	AttachedL3NetworkUuids []types.String           `tfsdk:"attached_l3_network_uuids"`
+	Rules                  []securityGroupRuleModel `tfsdk:"rules"`
+}
+
+// Configure implements datasource.DataSourceWithConfigure.
+func (d *securityGroupDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.ZSClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.ZSClient, got: %T. Please report this issue to the Provider developer. ", req.ProviderData),
+		)
+		return
+	}
+	d.client = client
+}
+
+func (d *securityGroupDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_security_groups"
+}
+
+func (d *securityGroupDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Fetches a list of security groups and their rules.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Description: "Exact name for searching security groups.",
+				Optional:    true,
+			},
+			"filter_match": schema.StringAttribute{
+				Description: "How the `filter` blocks are combined: `all` returns the resources that match every filter, `any` the resources that match at least one. Defaults to `all`.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(utils.MatchAll, utils.MatchAny),
+				},
+			},
+			"name_pattern": schema.StringAttribute{
+				Description: "Pattern for fuzzy name search, similar to MySQL LIKE. Use % for multiple characters and _ for exactly one character.",
+				Optional:    true,
+			},
+			"security_groups": schema.ListNestedAttribute{
+				Description: "List of security groups matching the specified filters.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"uuid": schema.StringAttribute{
+							Computed:    true,
+							Description: "UUID of the security group.",
+						},
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "Name of the security group.",
+						},
+						"description": schema.StringAttribute{
+							Computed:    true,
+							Description: "Description of the security group.",
+						},
+						"state": schema.StringAttribute{
+							Computed:    true,
+							Description: "State of the security group (e.g., Enabled, Disabled).",
+						},
+						"ip_version": schema.Int64Attribute{
+							Computed:    true,
+							Description: "IP version of the security group.",
+						},
+						"attached_l3_network_uuids": schema.ListAttribute{
+							Computed:    true,
+							ElementType: types.StringType,
+							Description: "UUIDs of the port groups the security group is attached to.",
+						},
+						"rules": schema.ListNestedAttribute{
+							Computed:    true,
+							Description: "Rules of the security group, ingress rules first, each direction in the order of priority.",
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"uuid": schema.StringAttribute{
+										Computed:    true,
+										Description: "UUID of the rule.",
+									},
+									"type": schema.StringAttribute{
+										Computed:    true,
+										Description: "Direction of the rule, Ingress or Egress.",
+									},
+									"protocol": schema.StringAttribute{
+										Computed:    true,
+										Description: "Protocol of the rule (e.g., TCP, UDP, ICMP, ALL).",
+									},
+									"action": schema.StringAttribute{
+										Computed:    true,
+										Description: "Action of the rule, ACCEPT or DROP.",
+									},
+									"state": schema.StringAttribute{
+										Computed:    true,
+										Description: "State of the rule, Enabled or Disabled.",
+									},
+									"priority": schema.Int64Attribute{
+										Computed:    true,
+										Description: "Priority of the rule among the rules of the same direction, 1 is evaluated first.",
+									},
+									"ip_version": schema.Int64Attribute{
+										Computed:    true,
+										Description: "IP version of the rule.",
+									},
+									"src_ip_range": schema.StringAttribute{
+										Computed:    true,
+										Description: "Source IPs of an ingress rule.",
+									},
+									"dst_ip_range": schema.StringAttribute{
+										Computed:    true,
+										Description: "Destination IPs of an egress rule.",
+									},
+									"dst_port_range": schema.StringAttribute{
+										Computed:    true,
+										Description: "Destination ports of a TCP or UDP rule.",
+									},
+									"remote_security_group_uuid": schema.StringAttribute{
+										Computed:    true,
+										Description: "UUID of the security group whose members the rule applies to.",
+									},
+									"description": schema.StringAttribute{
+										Computed:    true,
+										Description: "Description of the rule.",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"filter": schema.ListNestedBlock{
+				Description: "Filter resources based on any field in the schema. For example, to filter by status, use `name = \"status\"` and `values = [\"Ready\"]`.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "Name of the field to filter by (e.g., status, state). Use a dotted path for a field of a nested object or list, such as `rules.protocol`; it matches if any element matches.",
+							Required:    true,
+						},
+						"operator": schema.StringAttribute{
+							Description: "How the field is compared with the values: `=` (default) or `in` if it equals any value, `!=` or `not_in` if it equals none, " +
+								"`regex` if it matches any regular expression, `prefix` if it starts with any value, " +
+								"or `<`, `<=`, `>`, `>=` to compare a numeric field, such as `ip_version`, with a single number.",
+							Optional: true,
+							Validators: []validator.String{
+								stringvalidator.OneOf(utils.FilterOperators...),
+							},
+						},
+						"values": schema.SetAttribute{
+							Description: "Values to filter by. Multiple values will be treated as an OR condition, except for `!=` and `not_in`.",
+							Required:    true,
+							ElementType: types.StringType,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *securityGroupDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state securityGroupDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	filters := filterConditions(ctx, state.Filter, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	params := param.NewQueryParam()
+
+	if !state.Name.IsNull() {
+		params.AddQ("name=" + state.Name.ValueString())
+	} else if !state.NamePattern.IsNull() {
+		params.AddQ("name~=" + state.NamePattern.ValueString())
+	}
+
+	utils.AddQueryConditions[view.SecurityGroupInventoryView](&params, filters, state.FilterMatch.ValueString(), "security_group")
+
+	securityGroups, err := d.client.QuerySecurityGroup(params)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read ZSphere Security Groups",
+			err.Error(),
+		)
+		return
+	}
+
+	filterSecurityGroups, filterDiags := utils.FilterResource(ctx, securityGroups, filters, state.FilterMatch.ValueString(), "security_group")
+	resp.Diagnostics.Append(filterDiags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, securityGroup := range filterSecurityGroups {
+		securityGroupState := securityGroupModel{
+			Uuid:                   types.StringValue(securityGroup.UUID),
+			Name:                   types.StringValue(securityGroup.Name),
+			Description:            types.StringValue(securityGroup.Description),
+			State:                  types.StringValue(securityGroup.State),
+			IpVersion:              types.Int64Value(int64(securityGroup.IpVersion)),
+			AttachedL3NetworkUuids: []types.String{},
+		}
+		for _, l3NetworkUuid := range securityGroup.AttachedL3NetworkUuids {
+			securityGroupState.AttachedL3NetworkUuids = append(securityGroupState.AttachedL3NetworkUuids, types.StringValue(l3NetworkUuid))
+		}
+
+		// ZSphere returns the rules in no particular order
+		rules := append([]view.SecurityGroupRuleInventoryView(nil), securityGroup.Rules...)
+		sort.SliceStable(rules, func(i, j int) bool {
+			if rules[i].Type != rules[j].Type {
+				return rules[i].Type == securityGroupRuleIngress
+			}
+			return rules[i].Priority < rules[j].Priority
+		})
+		securityGroupState.Rules = securityGroupRules(rules)
+
+		state.SecurityGroups = append(state.SecurityGroups, securityGroupState)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,318 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-provider-zsphere/internal/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+var (
+	_ datasource.DataSource              = &virtualRouterInstanceDataSource{}
+	_ datasource.DataSourceWithConfigure = &virtualRouterInstanceDataSource{}
+)
+
+func ZSphereVirtualRouterInstanceDataSource() datasource.DataSource {
+	return &virtualRouterInstanceDataSource{}
+}
+
+type virtualRouterInstanceDataSource struct {
+	client *client.ZSClient
+}
+
+type virtualRouterInstanceDataSourceModel struct {
+	Name                   types.String                 `tfsdk:"name"`
+	NamePattern            types.String                 `tfsdk:"name_pattern"`
+	Filter                 []Filter                     `tfsdk:"filter"`
+	FilterMatch            types.String                 `tfsdk:"filter_match"`
+	VirtualRouterInstances []virtualRouterInstanceModel `tfsdk:"virtual_router_instances"`
+}
+
+type virtualRouterInstanceModel struct {
+	Uuid                  types.String  `tfsdk:"uuid"`
+	Name                  types.String  `tfsdk:"name"`
+	Description           types.String  `tfsdk:"description"`
+	ZoneUuid              types.String  `tfsdk:"zone_uuid"`
+	ClusterUuid           types.String  `tfsdk:"cluster_uuid"`
+	HostUuid              types.String  `tfsdk:"host_uuid"`
+	HypervisorType        types.String  `tfsdk:"hypervisor_type"`
+	ImageUuid             types.String  `tfsdk:"image_uuid"`
+	InstanceOfferingUuid  types.String  `tfsdk:"instance_offering_uuid"`
+	ManagementNetworkUuid types.String  `tfsdk:"management_network_uuid"`
+	PublicNetworkUuid     types.String  `tfsdk:"public_network_uuid"`
+	CpuNum                types.Int64   `tfsdk:"cpu_num"`
+	MemorySize            types.Int64   `tfsdk:"memory_size"`
+	State                 types.String  `tfsdk:"state"`
+	Status                types.String  `tfsdk:"status"`
+	HaStatus              types.String  `tfsdk:"ha_status"`
+	AgentPort             types.Int64   `tfsdk:"agent_port"`
+	ApplianceVmType       types.String  `tfsdk:"appliance_vm_type"`
+	VmNics                []vmNicsModel `tfsdk:"vm_nics"`
+}
+
+// Configure implements datasource.DataSourceWithConfigure.
+func (d *virtualRouterInstanceDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.ZSClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.ZSClient, got: %T. Please report this issue to the Provider developer. ", req.ProviderData),
+		)
+		return
+	}
+	d.client = client
+}
+
+func (d *virtualRouterInstanceDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_virtual_router_instances"
+}
+
+func (d *virtualRouterInstanceDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Fetches a list of virtual router instances and their associated attributes, such as their HA status, agent port and appliance VM type.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Description: "Exact name for searching virtual router instances.",
+				Optional:    true,
+			},
+			"filter_match": schema.StringAttribute{
+				Description: "How the `filter` blocks are combined: `all` returns the resources that match every filter, `any` the resources that match at least one. Defaults to `all`.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(utils.MatchAll, utils.MatchAny),
+				},
+			},
+			"name_pattern": schema.StringAttribute{
+				Description: "Pattern for fuzzy name search, similar to MySQL LIKE. Use % for multiple characters and _ for exactly one character.",
+				Optional:    true,
+			},
+			"virtual_router_instances": schema.ListNestedAttribute{
+				Description: "List of virtual router instances matching the specified filters.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"uuid": schema.StringAttribute{
+							Computed:    true,
+							Description: "UUID of the virtual router.",
+						},
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "Name of the virtual router.",
+						},
+						"description": schema.StringAttribute{
+							Computed:    true,
+							Description: "Description of the virtual router.",
+						},
+						"zone_uuid": schema.StringAttribute{
+							Computed:    true,
+							Description: "UUID of the zone of the virtual router.",
+						},
+						"cluster_uuid": schema.StringAttribute{
+							Computed:    true,
+							Description: "UUID of the cluster of the virtual router.",
+						},
+						"host_uuid": schema.StringAttribute{
+							Computed:    true,
+							Description: "UUID of the host the virtual router runs on.",
+						},
+						"hypervisor_type": schema.StringAttribute{
+							Computed:    true,
+							Description: "Hypervisor type of the virtual router (e.g., KVM).",
+						},
+						"image_uuid": schema.StringAttribute{
+							Computed:    true,
+							Description: "UUID of the image the virtual router was created from.",
+						},
+						"instance_offering_uuid": schema.StringAttribute{
+							Computed:    true,
+							Description: "UUID of the virtual router offering of the virtual router.",
+						},
+						"management_network_uuid": schema.StringAttribute{
+							Computed:    true,
+							Description: "UUID of the port group of the management NIC of the virtual router.",
+						},
+						"public_network_uuid": schema.StringAttribute{
+							Computed:    true,
+							Description: "UUID of the port group of the public NIC of the virtual router.",
+						},
+						"cpu_num": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Number of CPUs of the virtual router.",
+						},
+						"memory_size": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Memory size of the virtual router in megabytes (MB).",
+						},
+						"state": schema.StringAttribute{
+							Computed:    true,
+							Description: "State of the virtual router VM (e.g., Running, Stopped).",
+						},
+						"status": schema.StringAttribute{
+							Computed:    true,
+							Description: "Connection status of the agent of the virtual router (e.g., Connected, Disconnected).",
+						},
+						"ha_status": schema.StringAttribute{
+							Computed:    true,
+							Description: "HA status of the virtual router (e.g., NoHa, Master, Backup).",
+						},
+						"agent_port": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Port of the agent running in the virtual router.",
+						},
+						"appliance_vm_type": schema.StringAttribute{
+							Computed:    true,
+							Description: "Type of the appliance VM (e.g., VirtualRouter, vrouter).",
+						},
+						"vm_nics": schema.ListNestedAttribute{
+							Computed:    true,
+							Description: "NICs of the virtual router, one on its management network, its public network and each VPC network it serves.",
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"ip": schema.StringAttribute{
+										Computed:    true,
+										Description: "The IP address assigned to the VM NIC.",
+									},
+									"mac": schema.StringAttribute{
+										Computed:    true,
+										Description: "The MAC address of the VM NIC.",
+									},
+									"netmask": schema.StringAttribute{
+										Computed:    true,
+										Description: "The network mask of the VM NIC.",
+									},
+									"gateway": schema.StringAttribute{
+										Computed:    true,
+										Description: "The gateway IP address for the VM NIC.",
+									},
+									"uuid": schema.StringAttribute{
+										Computed:    true,
+										Description: "The uuid for the VM NIC.",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"filter": schema.ListNestedBlock{
+				Description: "Filter resources based on any field in the schema. For example, to filter by status, use `name = \"status\"` and `values = [\"Ready\"]`.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "Name of the field to filter by (e.g., status, state). Use a dotted path for a field of a nested object or list, such as `vm_nics.ip`; it matches if any element matches.",
+							Required:    true,
+						},
+						"operator": schema.StringAttribute{
+							Description: "How the field is compared with the values: `=` (default) or `in` if it equals any value, `!=` or `not_in` if it equals none, " +
+								"`regex` if it matches any regular expression, `prefix` if it starts with any value, " +
+								"or `<`, `<=`, `>`, `>=` to compare a numeric field, such as `cpu_num` or `memory_size` in MB, with a single number.",
+							Optional: true,
+							Validators: []validator.String{
+								stringvalidator.OneOf(utils.FilterOperators...),
+							},
+						},
+						"values": schema.SetAttribute{
+							Description: "Values to filter by. Multiple values will be treated as an OR condition, except for `!=` and `not_in`.",
+							Required:    true,
+							ElementType: types.StringType,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *virtualRouterInstanceDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state virtualRouterInstanceDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	filters := filterConditions(ctx, state.Filter, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	params := param.NewQueryParam()
+
+	if !state.Name.IsNull() {
+		params.AddQ("name=" + state.Name.ValueString())
+	} else if !state.NamePattern.IsNull() {
+		params.AddQ("name~=" + state.NamePattern.ValueString())
+	}
+
+	utils.AddQueryConditions[view.VirtualRouterVmInventoryView](&params, filters, state.FilterMatch.ValueString(), "virtual_router_instance")
+
+	virtualRouters, err := d.client.QueryVirtualRouterVm(params)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read ZSphere Virtual Router Instances",
+			err.Error(),
+		)
+		return
+	}
+
+	filterVirtualRouters, filterDiags := utils.FilterResource(ctx, virtualRouters, filters, state.FilterMatch.ValueString(), "virtual_router_instance")
+	resp.Diagnostics.Append(filterDiags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, virtualRouter := range filterVirtualRouters {
+		virtualRouterState := virtualRouterInstanceModel{
+			Uuid:                  types.StringValue(virtualRouter.UUID),
+			Name:                  types.StringValue(virtualRouter.Name),
+			Description:           types.StringValue(virtualRouter.Description),
+			ZoneUuid:              types.StringValue(virtualRouter.ZoneUuid),
+			ClusterUuid:           types.StringValue(virtualRouter.ClusterUuid),
+			HostUuid:              types.StringValue(virtualRouter.HostUuid),
+			HypervisorType:        types.StringValue(virtualRouter.HypervisorType),
+			ImageUuid:             types.StringValue(virtualRouter.ImageUuid),
+			InstanceOfferingUuid:  types.StringValue(virtualRouter.InstanceOfferingUuid),
+			ManagementNetworkUuid: types.StringValue(virtualRouter.ManagementNetworkUuid),
+			PublicNetworkUuid:     types.StringValue(virtualRouter.PublicNetworkUuid),
+			CpuNum:                types.Int64Value(int64(virtualRouter.CpuNum)),
+			MemorySize:            types.Int64Value(utils.BytesToMB(virtualRouter.MemorySize)),
+			State:                 types.StringValue(virtualRouter.State),
+			Status:                types.StringValue(virtualRouter.Status),
+			HaStatus:              types.StringValue(virtualRouter.HaStatus),
+			AgentPort:             types.Int64Value(int64(virtualRouter.AgentPort)),
+			ApplianceVmType:       types.StringValue(virtualRouter.ApplianceVmType),
+			VmNics:                []vmNicsModel{},
+		}
+		for _, nic := range virtualRouter.VmNics {
+			virtualRouterState.VmNics = append(virtualRouterState.VmNics, vmNicsModel{
+				IP:      types.StringValue(nic.IP),
+				Mac:     types.StringValue(nic.Mac),
+				Netmask: types.StringValue(nic.Netmask),
+				Gateway: types.StringValue(nic.Gateway),
+				Uuid:    types.StringValue(nic.UUID),
+			})
+		}
+
+		state.VirtualRouterInstances = append(state.VirtualRouterInstances, virtualRouterState)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-provider-zsphere/internal/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+var (
+	_ datasource.DataSource              = &virtualRouterOfferingDataSource{}
+	_ datasource.DataSourceWithConfigure = &virtualRouterOfferingDataSource{}
+)
+
+func ZSphereVirtualRouterOfferingDataSource() datasource.DataSource {
+	return &virtualRouterOfferingDataSource{}
+}
+
+type virtualRouterOfferingDataSource struct {
+	client *client.ZSClient
+}
+
+type virtualRouterOfferingDataSourceModel struct {
+	Name                   types.String                 `tfsdk:"name"`
+	NamePattern            types.String                 `tfsdk:"name_pattern"`
+	Filter                 []Filter                     `tfsdk:"filter"`
+	FilterMatch            types.String                 `tfsdk:"filter_match"`
+	VirtualRouterOfferings []virtualRouterOfferingModel `tfsdk:"virtual_router_offerings"`
+}
+
+type virtualRouterOfferingModel struct {
+	Uuid                  types.String `tfsdk:"uuid"`
+	Name                  types.String `tfsdk:"name"`
+	Description           types.String `tfsdk:"description"`
+	ZoneUuid              types.String `tfsdk:"zone_uuid"`
+	CpuNum                types.Int64  `tfsdk:"cpu_num"`
+	MemorySize            types.Int64  `tfsdk:"memory_size"`
+	ImageUuid             types.String `tfsdk:"image_uuid"`
+	ManagementNetworkUuid types.String `tfsdk:"management_network_uuid"`
+	PublicNetworkUuid     types.String `tfsdk:"public_network_uuid"`
+	IsDefault             types.Bool   `tfsdk:"is_default"`
+	AllocatorStrategy     types.String `tfsdk:"allocator_strategy"`
+	State                 types.String `tfsdk:"state"`
+}
+
+// Configure implements datasource.DataSourceWithConfigure.
+func (d *virtualRouterOfferingDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.ZSClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.ZSClient, got: %T. Please report this issue to the Provider developer. ", req.ProviderData),
+		)
+		return
+	}
+	d.client = client
+}
+
+func (d *virtualRouterOfferingDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_virtual_router_offerings"
+}
+
+func (d *virtualRouterOfferingDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Fetches a list of virtual router offerings and their associated attributes.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Description: "Exact name for searching virtual router offerings.",
+				Optional:    true,
+			},
+			"filter_match": schema.StringAttribute{
+				Description: "How the `filter` blocks are combined: `all` returns the resources that match every filter, `any` the resources that match at least one. Defaults to `all`.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(utils.MatchAll, utils.MatchAny),
+				},
+			},
+			"name_pattern": schema.StringAttribute{
+				Description: "Pattern for fuzzy name search, similar to MySQL LIKE. Use % for multiple characters and _ for exactly one character.",
+				Optional:    true,
+			},
+			"virtual_router_offerings": schema.ListNestedAttribute{
+				Description: "List of virtual router offerings matching the specified filters.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"uuid": schema.StringAttribute{
+							Computed:    true,
+							Description: "UUID of the virtual router offering.",
+						},
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "Name of the virtual router offering.",
+						},
+						"description": schema.StringAttribute{
+							Computed:    true,
+							Description: "Description of the virtual router offering.",
+						},
+						"zone_uuid": schema.StringAttribute{
+							Computed:    true,
+							Description: "UUID of the zone of the virtual router offering.",
+						},
+						"cpu_num": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Number of CPUs of the virtual routers.",
+						},
+						"memory_size": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Memory size of the virtual routers in megabytes (MB).",
+						},
+						"image_uuid": schema.StringAttribute{
+							Computed:    true,
+							Description: "UUID of the image the virtual routers are created from.",
+						},
+						"management_network_uuid": schema.StringAttribute{
+							Computed:    true,
+							Description: "UUID of the port group of the management NIC of the virtual routers.",
+						},
+						"public_network_uuid": schema.StringAttribute{
+							Computed:    true,
+							Description: "UUID of the port group of the public NIC of the virtual routers.",
+						},
+						"is_default": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether the offering is the default virtual router offering of its zone.",
+						},
+						"allocator_strategy": schema.StringAttribute{
+							Computed:    true,
+							Description: "Strategy used to choose the hosts of the virtual routers.",
+						},
+						"state": schema.StringAttribute{
+							Computed:    true,
+							Description: "State of the virtual router offering (e.g., Enabled, Disabled).",
+						},
+					},
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"filter": schema.ListNestedBlock{
+				Description: "Filter resources based on any field in the schema. For example, to filter by status, use `name = \"status\"` and `values = [\"Ready\"]`.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "Name of the field to filter by (e.g., status, state). Use a dotted path for a field of a nested object or list; it matches if any element matches.",
+							Required:    true,
+						},
+						"operator": schema.StringAttribute{
+							Description: "How the field is compared with the values: `=` (default) or `in` if it equals any value, `!=` or `not_in` if it equals none, " +
+								"`regex` if it matches any regular expression, `prefix` if it starts with any value, " +
+								"or `<`, `<=`, `>`, `>=` to compare a numeric field, such as `cpu_num` or `memory_size` in MB, with a single number.",
+							Optional: true,
+							Validators: []validator.String{
+								stringvalidator.OneOf(utils.FilterOperators...),
+							},
+						},
+						"values": schema.SetAttribute{
+							Description: "Values to filter by. Multiple values will be treated as an OR condition, except for `!=` and `not_in`.",
+							Required:    true,
+							ElementType: types.StringType,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *virtualRouterOfferingDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state virtualRouterOfferingDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	filters := filterConditions(ctx, state.Filter, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	params := param.NewQueryParam()
+
+	if !state.Name.IsNull() {
+		params.AddQ("name=" + state.Name.ValueString())
+	} else if !state.NamePattern.IsNull() {
+		params.AddQ("name~=" + state.NamePattern.ValueString())
+	}
+
+	utils.AddQueryConditions[view.VirtualRouterOfferingInventoryView](&params, filters, state.FilterMatch.ValueString(), "virtual_router_offer")
+
+	offerings, err := d.client.QueryVirtualRouterOffering(params)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read ZSphere Virtual Router Offerings",
+			err.Error(),
+		)
+		return
+	}
+
+	filterOfferings, filterDiags := utils.FilterResource(ctx, offerings, filters, state.FilterMatch.ValueString(), "virtual_router_offer")
+	resp.Diagnostics.Append(filterDiags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, offering := range filterOfferings {
+		offeringState := virtualRouterOfferingModel{
+			Uuid:                  types.StringValue(offering.UUID),
+			Name:                  types.StringValue(offering.Name),
+			Description:           types.StringValue(offering.Description),
+			ZoneUuid:              types.StringValue(offering.ZoneUuid),
+			CpuNum:                types.Int64Value(int64(offering.CpuNum)),
+			MemorySize:            types.Int64Value(utils.BytesToMB(offering.MemorySize)),
+			ImageUuid:             types.StringValue(offering.ImageUuid),
+			ManagementNetworkUuid: types.StringValue(offering.ManagementNetworkUuid),
+			PublicNetworkUuid:     types.StringValue(offering.PublicNetworkUuid),
+			IsDefault:             types.BoolValue(offering.IsDefault),
+			AllocatorStrategy:     types.StringValue(offering.AllocatorStrategy),
+			State:                 types.StringValue(offering.State),
+		}
+
+		state.VirtualRouterOfferings = append(state.VirtualRouterOfferings, offeringState)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-zsphere/internal/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+var (
+	_ datasource.DataSource              = &volumeDataSource{}
+	_ datasource.DataSourceWithConfigure = &volumeDataSource{}
+)
+
+func ZSphereVolumeDataSource() datasource.DataSource {
+	return &volumeDataSource{}
+}
+
+type volumeModel struct {
+	Name               types.String `tfsdk:"name"`
+	Uuid               types.String `tfsdk:"uuid"`
+	Description        types.String `tfsdk:"description"`
+	Type               types.String `tfsdk:"type"`
+	Format             types.String `tfsdk:"format"`
+	Size               types.Int64  `tfsdk:"size"`
+	ActualSize         types.Int64  `tfsdk:"actual_size"`
+	State              types.String `tfsdk:"state"`
+	Status             types.String `tfsdk:"status"`
+	PrimaryStorageUuid types.String `tfsdk:"primary_storage_uuid"`
+	VmInstanceUuid     types.String `tfsdk:"vm_instance_uuid"`
+	IsShareable        types.Bool   `tfsdk:"is_shareable"`
+}
+
+type volumeDataSourceModel struct {
+	Name        types.String  `tfsdk:"name"`
+	NamePattern types.String  `tfsdk:"name_pattern"`
+	Filter      []Filter      `tfsdk:"filter"`
+	FilterMatch types.String  `tfsdk:"filter_match"`
+	Volumes     []volumeModel `tfsdk:"volumes"`
+}
+
+type volumeDataSource struct {
+	client *client.ZSClient
+}
+
+// Configure implements datasource.DataSourceWithConfigure.
+func (d *volumeDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.ZSClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.ZSClient, got: %T. Please report this issue to the Provider developer. ", req.ProviderData),
+		)
+
+		return
+	}
+	d.client = client
+}
+
+// Metadata implements datasource.DataSourceWithConfigure.
+func (d *volumeDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_volumes"
+}
+
+// Read implements datasource.DataSourceWithConfigure.
+func (d *volumeDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state volumeDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filters := filterConditions(ctx, state.Filter, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	params := param.NewQueryParam()
+
+	if !state.Name.IsNull() {
+		params.AddQ("name=" + state.Name.ValueString())
+	} else if !state.NamePattern.IsNull() {
+		params.AddQ("name~=" + state.NamePattern.ValueString())
+	}
+
+	utils.AddQueryConditions[view.VolumeView](&params, filters, state.FilterMatch.ValueString(), "disks")
+
+	volumes, err := d.client.QueryVolume(params)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read ZStack Volumes",
+			err.Error(),
+		)
+		return
+	}
+
+	filterVolumes, filterDiags := utils.FilterResource(ctx, volumes, filters, state.FilterMatch.ValueString(), "disks")
+	resp.Diagnostics.Append(filterDiags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, volume := range filterVolumes {
+		volumeState := volumeModel{
+			Name:               types.StringValue(volume.Name),
+			Uuid:               types.StringValue(volume.UUID),
+			Description:        types.StringValue(volume.Description),
+			Type:               types.StringValue(volume.Type),
+			Format:             types.StringValue(volume.Format),
+			Size:               types.Int64Value(utils.BytesToGB(volume.Size)),
+			ActualSize:         types.Int64Value(utils.BytesToGB(volume.ActualSize)),
+			State:              types.StringValue(volume.State),
+			Status:             types.StringValue(volume.Status),
+			PrimaryStorageUuid: types.StringValue(volume.PrimaryStorageUUID),
+			VmInstanceUuid:     types.StringValue(volume.VMInstanceUUID),
+			IsShareable:        types.BoolValue(volume.IsShareable),
+		}
+
+		state.Volumes = append(state.Volumes, volumeState)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Schema implements datasource.DataSourceWithConfigure.
+func (d *volumeDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "List all volumes, or query volumes by exact name match, or query volumes by name pattern fuzzy match.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Description: "Exact name for searching volume.",
+				Optional:    true,
+			},
+			"filter_match": schema.StringAttribute{
+				Description: "How the `filter` blocks are combined: `all` returns the resources that match every filter, `any` the resources that match at least one. Defaults to `all`.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(utils.MatchAll, utils.MatchAny),
+				},
+			},
+			"name_pattern": schema.StringAttribute{
+				Description: "Pattern for fuzzy name search, similar to MySQL LIKE. Use % for multiple characters and _ for exactly one character.",
+				Optional:    true,
+			},
+			"volumes": schema.ListNestedAttribute{
+				Description: "List of volume entries",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "Name of the volume",
+							Computed:    true,
+						},
+						"uuid": schema.StringAttribute{
+							Description: "UUID identifier of the volume",
+							Computed:    true,
+						},
+						"description": schema.StringAttribute{
+							Description: "Description of the volume",
+							Computed:    true,
+						},
+						"type": schema.StringAttribute{
+							Description: "Type of the volume (Root or Data)",
+							Computed:    true,
+						},
+						"format": schema.StringAttribute{
+							Description: "Format of the volume (e.g., qcow2, raw)",
+							Computed:    true,
+						},
+						"size": schema.Int64Attribute{
+							Description: "Size of the volume in gigabytes (GB)",
+							Computed:    true,
+						},
+						"actual_size": schema.Int64Attribute{
+							Description: "Actual size of the volume on the primary storage in gigabytes (GB)",
+							Computed:    true,
+						},
+						"state": schema.StringAttribute{
+							Description: "State of the volume (Enabled or Disabled)",
+							Computed:    true,
+						},
+						"status": schema.StringAttribute{
+							Description: "Readiness status of the volume (e.g., Ready, NotInstantiated)",
+							Computed:    true,
+						},
+						"primary_storage_uuid": schema.StringAttribute{
+							Description: "UUID of the primary storage the volume is located on",
+							Computed:    true,
+						},
+						"vm_instance_uuid": schema.StringAttribute{
+							Description: "UUID of the VM instance the volume is attached to, empty if the volume is not attached",
+							Computed:    true,
+						},
+						"is_shareable": schema.BoolAttribute{
+							Description: "Whether the volume can be attached to multiple VM instances",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"filter": schema.ListNestedBlock{
+				Description: "Filter resources based on any field in the schema. For example, to filter by attached VM, use `name = \"vm_instance_uuid\"` and `values = [\"<uuid>\"]`.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "Name of the field to filter by (e.g., status, vm_instance_uuid, primary_storage_uuid, is_shareable). Use a dotted path for a field of a nested object or list; it matches if any element matches.",
+							Required:    true,
+						},
+						"operator": schema.StringAttribute{
+							Description: "How the field is compared with the values: `=` (default) or `in` if it equals any value, `!=` or `not_in` if it equals none, " +
+								"`regex` if it matches any regular expression, `prefix` if it starts with any value, " +
+								"or `<`, `<=`, `>`, `>=` to compare a numeric field, such as `size` in GB, with a single number.",
+							Optional: true,
+							Validators: []validator.String{
+								stringvalidator.OneOf(utils.FilterOperators...),
+							},
+						},
+						"values": schema.SetAttribute{
+							Description: "Values to filter by. Multiple values will be treated as an OR condition, except for `!=` and `not_in`.",
+							Required:    true,
+							ElementType: types.StringType,
+						},
+					},
+				},
+			},
+		},
+	}
+}
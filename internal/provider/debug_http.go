@@ -0,0 +1,178 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// maxDebugBodySize limits how much of a request or response body is logged.
+	maxDebugBodySize = 16 * 1024
+
+	redacted = "<redacted>"
+)
+
+// secretKeys are the JSON keys, in lower case and without separators, whose values are never logged.
+var secretKeys = []string{
+	"password",
+	"secret",
+	"userdata",
+	"token",
+	"privatekey",
+	"sessionid",
+}
+
+// secretHeaders are the headers whose values are never logged.
+var secretHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"Proxy-Authorization",
+}
+
+// secretTagPrefixes are the system tags that carry secrets, like the user data of a vm instance.
+var secretTagPrefixes = []string{
+	vmUserDataSystemTagPrefix,
+}
+
+// debugTransport logs the requests to the ZSphere API and their responses through tflog,
+// with passwords, secrets, user data and session ids redacted.
+type debugTransport struct {
+	// ctx carries the provider logger; requests of the SDK do not.
+	ctx  context.Context
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = body
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	tflog.Debug(t.ctx, fmt.Sprintf("ZSphere API request: %s %s", req.Method, req.URL.String()), map[string]any{
+		"headers": redactHeaders(req.Header),
+		"body":    redactBody(reqBody),
+	})
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		tflog.Debug(t.ctx, fmt.Sprintf("ZSphere API request failed: %s %s after %s, err: %v", req.Method, req.URL.Path, time.Since(start).Round(time.Millisecond), err))
+		return resp, err
+	}
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxDebugBodySize))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(respBody), resp.Body), resp.Body}
+	if err != nil {
+		return resp, nil
+	}
+
+	logBody := redactBody(respBody)
+	if strings.Contains(strings.ToLower(req.URL.Path), "login") {
+		// the response to a login is the new session
+		logBody = redacted
+	}
+	tflog.Debug(t.ctx, fmt.Sprintf("ZSphere API response: %s %s %s after %s", req.Method, req.URL.Path, resp.Status, time.Since(start).Round(time.Millisecond)), map[string]any{
+		"headers": redactHeaders(resp.Header),
+		"body":    logBody,
+	})
+	return resp, nil
+}
+
+// redactHeaders returns the headers for logging, with credentials redacted.
+func redactHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name := range header {
+		headers[name] = header.Get(name)
+	}
+	for _, name := range secretHeaders {
+		if _, ok := headers[http.CanonicalHeaderKey(name)]; ok {
+			headers[http.CanonicalHeaderKey(name)] = redacted
+		}
+	}
+	return headers
+}
+
+// redactBody returns a request or response body for logging, with secrets redacted.
+// A body that is not JSON is not logged, as its secrets cannot be found.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		if len(body) >= maxDebugBodySize {
+			return fmt.Sprintf("<%d bytes or more, truncated>", len(body))
+		}
+		return fmt.Sprintf("<%d bytes, not JSON>", len(body))
+	}
+
+	// without HTML escaping, so that the log reads <redacted> rather than \u003credacted\u003e
+	var redactedBody strings.Builder
+	encoder := json.NewEncoder(&redactedBody)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(redactValue(value)); err != nil {
+		return fmt.Sprintf("<%d bytes>", len(body))
+	}
+	return strings.TrimSuffix(redactedBody.String(), "\n")
+}
+
+// redactValue replaces the secrets in a decoded JSON value.
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if isSecretKey(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactValue(item)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+		return v
+	case string:
+		for _, prefix := range secretTagPrefixes {
+			if strings.HasPrefix(v, prefix) {
+				return prefix + redacted
+			}
+		}
+		return v
+	}
+	return value
+}
+
+// isSecretKey reports whether the value of a JSON key is a secret, e.g. password, accessKeySecret or user_data.
+func isSecretKey(key string) bool {
+	normalized := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	for _, secret := range secretKeys {
+		if strings.Contains(normalized, secret) {
+			return true
+		}
+	}
+	return false
+}
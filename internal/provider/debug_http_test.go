@@ -0,0 +1,151 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestDebugTransportRedactsSecrets(t *testing.T) {
+	// every secret contains this marker, which must not reach the log
+	const marker = "hunter2"
+
+	// the fake API echoes the request body, so responses carry the secrets too
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.HasSuffix(r.URL.Path, "/accounts/login") {
+			_, _ = io.WriteString(w, `{"inventory":{"uuid":"`+marker+`-session"}}`)
+			return
+		}
+		w.Header().Set("Set-Cookie", "session="+marker+"-cookie")
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	requests := []struct {
+		name    string
+		method  string
+		path    string
+		headers map[string]string
+		body    string
+	}{
+		{
+			name:   "login",
+			method: http.MethodPut,
+			path:   "/zstack/v1/accounts/login",
+			body:   `{"logInByAccount":{"accountName":"admin","password":"` + marker + `-password"}}`,
+		},
+		{
+			name:   "access key",
+			method: http.MethodGet,
+			path:   "/zstack/v1/zones",
+			headers: map[string]string{
+				"Authorization": "ZStack 8nEKmBL2mgMbYaHkpRkU:" + marker + "-signature",
+				"Cookie":        "session=" + marker + "-cookie",
+			},
+		},
+		{
+			name:   "user data system tag",
+			method: http.MethodPost,
+			path:   "/zstack/v1/vm-instances",
+			body:   `{"params":{"name":"web-1"},"systemTags":["userdata::` + marker + `-userdata","cdroms::Empty"]}`,
+		},
+		{
+			name:   "nested password",
+			method: http.MethodPost,
+			path:   "/zstack/v1/access-keys",
+			body: `{"params":{"accounts":[{"name":"ops","Password":"` + marker + `-nested"}]},` +
+				`"accessKeySecret":"` + marker + `-secret","user_data":"` + marker + `-user-data"}`,
+		},
+	}
+
+	for _, r := range requests {
+		t.Run(r.name, func(t *testing.T) {
+			var output bytes.Buffer
+			ctx := tflogtest.RootLogger(context.Background(), &output)
+			cli := &http.Client{Transport: &debugTransport{ctx: ctx, next: http.DefaultTransport}}
+
+			var body io.Reader
+			if r.body != "" {
+				body = strings.NewReader(r.body)
+			}
+			req, err := http.NewRequest(r.method, server.URL+r.path, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, value := range r.headers {
+				req.Header.Set(name, value)
+			}
+
+			resp, err := cli.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			respBody, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			// the secrets are only redacted in the log, the API and the SDK still see them
+			if r.body != "" && r.name != "login" && string(respBody) != r.body {
+				t.Errorf("response body = %q, want %q", respBody, r.body)
+			}
+
+			log := output.String()
+			if !strings.Contains(log, "ZSphere API request") || !strings.Contains(log, "ZSphere API response") {
+				t.Fatalf("request and response are not logged: %s", log)
+			}
+			if strings.Contains(log, marker) {
+				t.Errorf("a secret reached the log: %s", log)
+			}
+		})
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "empty",
+			body: "",
+			want: "",
+		},
+		{
+			name: "not JSON",
+			body: "password=secret",
+			want: "<15 bytes, not JSON>",
+		},
+		{
+			name: "nothing secret",
+			body: `{"params":{"name":"web-1","cpuNum":2}}`,
+			want: `{"params":{"cpuNum":2,"name":"web-1"}}`,
+		},
+		{
+			name: "secret keys at any depth",
+			body: `{"session-id":"a","list":[{"privateKey":"b"}],"newPassword":"c"}`,
+			want: `{"list":[{"privateKey":"<redacted>"}],"newPassword":"<redacted>","session-id":"<redacted>"}`,
+		},
+		{
+			name: "user data system tag",
+			body: `{"systemTags":["userdata::abc","cdroms::Empty"]}`,
+			want: `{"systemTags":["userdata::<redacted>","cdroms::Empty"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactBody([]byte(tt.body)); got != tt.want {
+				t.Errorf("redactBody() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,9 +3,34 @@
 
 package provider
 
-import "github.com/hashicorp/terraform-plugin-framework/types"
+import (
+	"context"
+	"terraform-provider-zsphere/internal/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
 
 type Filter struct {
-	Name   types.String `tfsdk:"name"`
-	Values types.Set    `tfsdk:"values"`
+	Name     types.String `tfsdk:"name"`
+	Operator types.String `tfsdk:"operator"`
+	Values   types.Set    `tfsdk:"values"`
+}
+
+// filterConditions converts the filter blocks of a data source for utils.FilterResource.
+func filterConditions(ctx context.Context, filters []Filter, diags *diag.Diagnostics) []utils.Filter {
+	conditions := make([]utils.Filter, 0, len(filters))
+	for _, filter := range filters {
+		values := make([]string, 0, len(filter.Values.Elements()))
+		diags.Append(filter.Values.ElementsAs(ctx, &values, false)...)
+		if diags.HasError() {
+			return nil
+		}
+		conditions = append(conditions, utils.Filter{
+			Name:     filter.Name.ValueString(),
+			Operator: filter.Operator.ValueString(),
+			Values:   values,
+		})
+	}
+	return conditions
 }
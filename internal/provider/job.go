@@ -0,0 +1,293 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// jobProgressInterval is how often the progress of a running job is logged.
+const jobProgressInterval = 15 * time.Second
+
+// apiJobRetention is how long the API jobs started by the requests of the provider are remembered.
+const apiJobRetention = time.Hour
+
+// job describes a long-running API call to ZSphere. The SDK waits for the API job to finish, runJob
+// adds progress logging on top of it and stops waiting when the context is cancelled or times out.
+type job struct {
+	// name describes the job in logs and errors, e.g. "create vm instance".
+	name string
+	// resourceUuid is the uuid of the resource the job works on, the API job is looked up by it. Jobs
+	// creating a resource pre-assign it, so the resource can still be found when the job cannot.
+	resourceUuid string
+	// progress optionally reports the state of the resource while the job runs.
+	progress func() (string, error)
+}
+
+// errJobNotFinished is returned when the provider stops waiting for a job that is still running.
+var errJobNotFinished = errors.New("job did not finish")
+
+// runJob runs call and waits until it returns or ctx is done, logging the progress of the job. When ctx is
+// done, the API job of call is abandoned: the SDK stops polling it, so call returns soon after runJob.
+func runJob[T any](ctx context.Context, j job, call func() (T, error)) (T, error) {
+	type result struct {
+		value T
+		err   error
+	}
+
+	start := time.Now()
+	done := make(chan result, 1)
+	go func() {
+		value, err := call()
+		apiJobs.release(j.resourceUuid, start)
+		done <- result{value: value, err: err}
+	}()
+
+	ticker := time.NewTicker(jobProgressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case res := <-done:
+			if res.err != nil {
+				return res.value, fmt.Errorf("%s failed, %s, err: %w", j.name, apiJobs.describe(j.resourceUuid, start), res.err)
+			}
+			tflog.Info(ctx, fmt.Sprintf("%s finished after %s, %s", j.name, time.Since(start).Round(time.Second), apiJobs.describe(j.resourceUuid, start)))
+			return res.value, nil
+		case <-ticker.C:
+			status := "running"
+			if j.progress != nil {
+				if s, err := j.progress(); err == nil && s != "" {
+					status = s
+				}
+			}
+			tflog.Info(ctx, fmt.Sprintf("still waiting for %s, %s, after %s, status: %s", j.name, apiJobs.describe(j.resourceUuid, start),
+				time.Since(start).Round(time.Second), status))
+		case <-ctx.Done():
+			apiJobs.abandon(j.resourceUuid, start)
+			var zero T
+			return zero, fmt.Errorf("%w: stopped waiting for %s after %s (%v). The job keeps running in ZSphere, "+
+				"look up %s to follow it before retrying", errJobNotFinished, j.name, time.Since(start).Round(time.Second), ctx.Err(),
+				apiJobs.describe(j.resourceUuid, start))
+		}
+	}
+}
+
+// apiJobs are the API jobs started by the requests of the provider.
+var apiJobs = newApiJobRegistry()
+
+type apiJob struct {
+	uuid string
+	// sent is when the request starting the job was sent.
+	sent time.Time
+}
+
+// abandonedCall is the time span of a call runJob stopped waiting for.
+type abandonedCall struct {
+	started, abandoned time.Time
+}
+
+// apiJobRegistry keeps the API jobs ZSphere started for asynchronous requests, by the uuids of the resources
+// the requests were about, and the jobs runJob stopped waiting for.
+type apiJobRegistry struct {
+	mu   sync.Mutex
+	jobs map[string][]apiJob
+	// abandoned are the jobs nobody waits for anymore, by job uuid.
+	abandoned map[string]bool
+	// abandonedCalls abandon the jobs of a resource that are accepted after runJob stopped waiting, when the
+	// request starting them was sent by the abandoned call. They are kept until the call returns.
+	abandonedCalls map[string][]abandonedCall
+}
+
+func newApiJobRegistry() *apiJobRegistry {
+	return &apiJobRegistry{
+		jobs:           make(map[string][]apiJob),
+		abandoned:      make(map[string]bool),
+		abandonedCalls: make(map[string][]abandonedCall),
+	}
+}
+
+// add records a job started by a request about the given resources that was sent at the given time.
+func (r *apiJobRegistry) add(resourceUuids []string, jobUuid string, sent time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, resourceUuid := range resourceUuids {
+		jobs := r.jobs[resourceUuid]
+		for len(jobs) > 0 && time.Since(jobs[0].sent) > apiJobRetention {
+			delete(r.abandoned, jobs[0].uuid)
+			jobs = jobs[1:]
+		}
+		r.jobs[resourceUuid] = append(jobs, apiJob{uuid: jobUuid, sent: sent})
+
+		for _, call := range r.abandonedCalls[resourceUuid] {
+			if !sent.Before(call.started) && sent.Before(call.abandoned) {
+				r.abandoned[jobUuid] = true
+			}
+		}
+	}
+}
+
+// latest returns the uuid of the last job started for a resource since the given time, if any.
+func (r *apiJobRegistry) latest(resourceUuid string, since time.Time) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	jobs := r.jobs[resourceUuid]
+	if len(jobs) == 0 || jobs[len(jobs)-1].sent.Before(since) {
+		return ""
+	}
+	return jobs[len(jobs)-1].uuid
+}
+
+// describe tells the user what to look up in ZSphere for the job of a resource started since the given time.
+func (r *apiJobRegistry) describe(resourceUuid string, since time.Time) string {
+	if jobUuid := r.latest(resourceUuid, since); jobUuid != "" {
+		return fmt.Sprintf("api job %s of resource %s", jobUuid, resourceUuid)
+	}
+	return "resource " + resourceUuid
+}
+
+// abandon marks the jobs of a resource started by the call runJob started at the given time as abandoned,
+// including the one the call is still waiting to be accepted.
+func (r *apiJobRegistry) abandon(resourceUuid string, started time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, job := range r.jobs[resourceUuid] {
+		if !job.sent.Before(started) {
+			r.abandoned[job.uuid] = true
+		}
+	}
+	r.abandonedCalls[resourceUuid] = append(r.abandonedCalls[resourceUuid], abandonedCall{started: started, abandoned: now})
+}
+
+// release forgets an abandoned call once it returned.
+func (r *apiJobRegistry) release(resourceUuid string, started time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calls := slices.DeleteFunc(r.abandonedCalls[resourceUuid], func(call abandonedCall) bool {
+		return call.started.Equal(started)
+	})
+	if len(calls) == 0 {
+		delete(r.abandonedCalls, resourceUuid)
+	} else {
+		r.abandonedCalls[resourceUuid] = calls
+	}
+}
+
+// isAbandoned reports whether nobody waits for a job anymore.
+func (r *apiJobRegistry) isAbandoned(jobUuid string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.abandoned[jobUuid]
+}
+
+var (
+	// apiJobPath matches the path the SDK polls an API job at, e.g. /zstack/v1/api-jobs/<job uuid>.
+	apiJobPath = regexp.MustCompile(`/api-jobs/([0-9a-f]{32})$`)
+	// resourceUuidField matches the pre-assigned uuid of a resource in the body of a create request.
+	resourceUuidField = regexp.MustCompile(`"resourceUuid"\s*:\s*"([0-9a-f]{32})"`)
+	uuidPattern       = regexp.MustCompile(`^[0-9a-f]{32}$`)
+)
+
+// jobTransport records the API job ZSphere starts for an asynchronous request, from the location of its
+// 202 Accepted response, under the uuids of the resources in the request path and the pre-assigned uuid in
+// its body. It fails the polling of the jobs runJob stopped waiting for, so that the SDK call returns.
+type jobTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *jobTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if m := apiJobPath.FindStringSubmatch(req.URL.Path); m != nil && apiJobs.isAbandoned(m[1]) {
+		return nil, fmt.Errorf("%w: api job %s is no longer waited for", errJobNotFinished, m[1])
+	}
+
+	resourceUuids, err := requestResourceUuids(req)
+	if err != nil {
+		return nil, err
+	}
+	sent := time.Now()
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusAccepted || len(resourceUuids) == 0 {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	var accepted struct {
+		Location string `json:"location"`
+	}
+	if json.Unmarshal(body, &accepted) == nil {
+		if location, err := url.Parse(accepted.Location); err == nil {
+			if m := apiJobPath.FindStringSubmatch(location.Path); m != nil {
+				apiJobs.add(resourceUuids, m[1], sent)
+			}
+		}
+	}
+	return resp, nil
+}
+
+// requestResourceUuids returns the uuids of the resources a request is about, leaving its body readable.
+func requestResourceUuids(req *http.Request) ([]string, error) {
+	if apiJobPath.MatchString(req.URL.Path) {
+		return nil, nil
+	}
+
+	var uuids []string
+	for _, segment := range strings.Split(req.URL.Path, "/") {
+		if uuidPattern.MatchString(segment) {
+			uuids = append(uuids, segment)
+		}
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return uuids, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	for _, m := range resourceUuidField.FindAllSubmatch(body, -1) {
+		uuids = append(uuids, string(m[1]))
+	}
+	return uuids, nil
+}
+
+// newResourceUuid generates a uuid in the format ZSphere uses, 32 hex characters without dashes.
+func newResourceUuid() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,262 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// jobServer is a fake management node running every request as an asynchronous API job.
+type jobServer struct {
+	*httptest.Server
+	jobUuid string
+	// acceptDelay delays the 202 Accepted response of the request starting the job.
+	acceptDelay time.Duration
+	// polls is how often the job is polled before it finishes, it never finishes if zero.
+	polls int32
+	// fail makes the job fail instead of succeeding.
+	fail bool
+
+	polled atomic.Int32
+}
+
+func newJobServer(t *testing.T) *jobServer {
+	t.Helper()
+
+	jobUuid, err := newResourceUuid()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &jobServer{jobUuid: jobUuid}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/api-jobs/"+s.jobUuid) {
+			n := s.polled.Add(1)
+			switch {
+			case s.polls == 0 || n < s.polls:
+				w.WriteHeader(http.StatusAccepted)
+				_, _ = io.WriteString(w, `{}`)
+			case s.fail:
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = io.WriteString(w, `{"error":{"code":"SYS.1006","details":"operation failed"}}`)
+			default:
+				w.WriteHeader(http.StatusOK)
+				_, _ = io.WriteString(w, `{"inventory":{}}`)
+			}
+			return
+		}
+
+		_, _ = io.ReadAll(r.Body)
+		time.Sleep(s.acceptDelay)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = fmt.Fprintf(w, `{"location":"%s/zstack/v1/api-jobs/%s"}`, s.URL, s.jobUuid)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// call sends an action on a vm instance and polls its job like the SDK does.
+func (s *jobServer) call(cli *http.Client, vmUuid string) error {
+	resp, err := cli.Post(s.URL+"/zstack/v1/vm-instances/"+vmUuid+"/actions", "application/json",
+		strings.NewReader(`{"rebootVmInstance":{}}`))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var accepted struct {
+		Location string `json:"location"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&accepted); err != nil {
+		return err
+	}
+
+	for {
+		resp, err := cli.Get(accepted.Location)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusOK:
+			return nil
+		case http.StatusAccepted:
+			time.Sleep(10 * time.Millisecond)
+		default:
+			return fmt.Errorf("job failed with status %d", resp.StatusCode)
+		}
+	}
+}
+
+func newTestJobClient() *http.Client {
+	return &http.Client{Transport: &jobTransport{next: http.DefaultTransport}}
+}
+
+func newTestVmUuid(t *testing.T) string {
+	t.Helper()
+
+	uuid, err := newResourceUuid()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return uuid
+}
+
+func TestRunJob(t *testing.T) {
+	tests := []struct {
+		name    string
+		polls   int32
+		fail    bool
+		timeout time.Duration
+		wantErr error
+	}{
+		{
+			name:    "slow job finishes in time",
+			polls:   5,
+			timeout: 5 * time.Second,
+		},
+		{
+			name:    "failed job",
+			polls:   2,
+			fail:    true,
+			timeout: 5 * time.Second,
+			wantErr: errors.New("job failed"),
+		},
+		{
+			name:    "job does not finish in time",
+			timeout: 100 * time.Millisecond,
+			wantErr: errJobNotFinished,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newJobServer(t)
+			server.polls = tt.polls
+			server.fail = tt.fail
+			cli := newTestJobClient()
+			vmUuid := newTestVmUuid(t)
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			returned := make(chan error, 1)
+			_, err := runJob(ctx, job{name: "reboot vm instance", resourceUuid: vmUuid}, func() (any, error) {
+				err := server.call(cli, vmUuid)
+				returned <- err
+				return nil, err
+			})
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("runJob() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("runJob() error = nil, want %v", tt.wantErr)
+			}
+			if errors.Is(tt.wantErr, errJobNotFinished) != errors.Is(err, errJobNotFinished) {
+				t.Fatalf("runJob() error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), "api job "+server.jobUuid) {
+				t.Errorf("runJob() error = %v, want it to report api job %s", err, server.jobUuid)
+			}
+
+			// the abandoned job is no longer polled, so the call does not outlive runJob
+			select {
+			case <-returned:
+			case <-time.After(2 * time.Second):
+				t.Fatal("the call of the abandoned job did not return")
+			}
+		})
+	}
+}
+
+func TestRunJobCancelled(t *testing.T) {
+	server := newJobServer(t)
+	server.acceptDelay = 200 * time.Millisecond
+	cli := newTestJobClient()
+	vmUuid := newTestVmUuid(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	returned := make(chan error, 1)
+	_, err := runJob(ctx, job{name: "reboot vm instance", resourceUuid: vmUuid}, func() (any, error) {
+		err := server.call(cli, vmUuid)
+		returned <- err
+		return nil, err
+	})
+	if !errors.Is(err, errJobNotFinished) {
+		t.Fatalf("runJob() error = %v, want %v", err, errJobNotFinished)
+	}
+	// the job was not accepted yet when runJob stopped waiting, only the resource can be looked up
+	if !strings.Contains(err.Error(), "look up resource "+vmUuid) {
+		t.Errorf("runJob() error = %v, want it to report resource %s", err, vmUuid)
+	}
+
+	// a job of the same resource started afterwards is not abandoned with the one still being accepted
+	next := newJobServer(t)
+	next.polls = 2
+	_, err = runJob(context.Background(), job{name: "start vm instance", resourceUuid: vmUuid}, func() (any, error) {
+		return nil, next.call(cli, vmUuid)
+	})
+	if err != nil {
+		t.Fatalf("runJob() of the next job error = %v", err)
+	}
+
+	select {
+	case err := <-returned:
+		if !errors.Is(err, errJobNotFinished) {
+			t.Errorf("call of the abandoned job error = %v, want %v", err, errJobNotFinished)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the call of the abandoned job did not return")
+	}
+	if polled := server.polled.Load(); polled != 0 {
+		t.Errorf("the abandoned job was polled %d times, want 0", polled)
+	}
+}
+
+func TestJobTransportKeepsBodies(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = io.WriteString(w, `{"location":"http://zsphere/zstack/v1/api-jobs/0123456789abcdef0123456789abcdef"}`)
+	}))
+	defer server.Close()
+
+	resourceUuid := newTestVmUuid(t)
+	body := `{"params":{"name":"vm","resourceUuid":"` + resourceUuid + `"}}`
+	start := time.Now()
+
+	resp, err := newTestJobClient().Post(server.URL+"/zstack/v1/vm-instances", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+
+	if received != body {
+		t.Errorf("request body = %q, want %q", received, body)
+	}
+	if !strings.Contains(string(respBody), "/api-jobs/0123456789abcdef0123456789abcdef") {
+		t.Errorf("response body = %q, want the job location", respBody)
+	}
+	if got := apiJobs.latest(resourceUuid, start); got != "0123456789abcdef0123456789abcdef" {
+		t.Errorf("job of pre-assigned resource uuid = %q, want 0123456789abcdef0123456789abcdef", got)
+	}
+}
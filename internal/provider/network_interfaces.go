@@ -0,0 +1,221 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+var _ planmodifier.List = networkInterfacesPlanModifier{}
+
+// networkInterfacesPlanModifier keeps the static ip of the NICs that stay on the same port group,
+// so that an unset static_ip does not show as a change when another NIC is added or removed.
+type networkInterfacesPlanModifier struct{}
+
+func (m networkInterfacesPlanModifier) Description(_ context.Context) string {
+	return "Matches the planned network interfaces with the NICs in state by port group."
+}
+
+func (m networkInterfacesPlanModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m networkInterfacesPlanModifier) PlanModifyList(ctx context.Context, req planmodifier.ListRequest, resp *planmodifier.ListResponse) {
+	if req.StateValue.IsNull() || req.PlanValue.IsNull() || req.PlanValue.IsUnknown() {
+		return
+	}
+
+	var planNics, stateNics []NetworkInterfaceModel
+	resp.Diagnostics.Append(req.PlanValue.ElementsAs(ctx, &planNics, false)...)
+	resp.Diagnostics.Append(req.StateValue.ElementsAs(ctx, &stateNics, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for i, j := range matchNetworkInterfaces(planNics, stateNics) {
+		if j >= 0 && planNics[i].StaticIp.IsUnknown() {
+			planNics[i].StaticIp = stateNics[j].StaticIp
+		}
+	}
+
+	planValue, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: networkInterfaceAttrTypes}, planNics)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.PlanValue = planValue
+}
+
+// matchNetworkInterfaces returns, for every planned NIC, the index of the NIC in state on the same port group, or -1 for a new NIC.
+func matchNetworkInterfaces(planNics []NetworkInterfaceModel, stateNics []NetworkInterfaceModel) []int {
+	matches := make([]int, len(planNics))
+	matched := make([]bool, len(stateNics))
+
+	for i := range planNics {
+		matches[i] = -1
+		for j := range stateNics {
+			if !matched[j] && !planNics[i].L3NetworkUuid.IsUnknown() && planNics[i].L3NetworkUuid.Equal(stateNics[j].L3NetworkUuid) {
+				matches[i] = j
+				matched[j] = true
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// updateNetworkInterfaces applies the planned network interfaces to a vm instance: NICs on new port groups
+// are attached first, then the default network and static ips are updated, and NICs no longer planned are
+// detached last, so the default network never points at a detached NIC.
+func updateNetworkInterfaces(ctx context.Context, cli *client.ZSClient, vm *view.VmInstanceInventoryView, planNics []NetworkInterfaceModel, stateNics []NetworkInterfaceModel) error {
+	matches := matchNetworkInterfaces(planNics, stateNics)
+
+	defaultL3Uuid := ""
+	for i, nic := range planNics {
+		l3Uuid := nic.L3NetworkUuid.ValueString()
+		if nic.DefaultL3.ValueBool() {
+			defaultL3Uuid = l3Uuid
+		}
+		if matches[i] >= 0 {
+			continue
+		}
+
+		attachParam := param.AttachL3NetworkToVmParam{}
+		if !nic.StaticIp.IsUnknown() && nic.StaticIp.ValueString() != "" {
+			attachParam.Params.StaticIp = nic.StaticIp.ValueString()
+		}
+
+		tflog.Info(ctx, fmt.Sprintf("attach port group %s to vm instance %s", l3Uuid, vm.UUID))
+		_, err := runJob(ctx, job{
+			name:         "attach port group",
+			resourceUuid: vm.UUID,
+		}, func() (any, error) {
+			return cli.AttachL3NetworkToVm(l3Uuid, vm.UUID, attachParam)
+		})
+		if err != nil {
+			return fmt.Errorf("fail to attach port group %s to vm instance %s, err: %w", l3Uuid, vm.UUID, err)
+		}
+	}
+
+	if defaultL3Uuid != "" && defaultL3Uuid != vm.DefaultL3NetworkUUID {
+		tflog.Info(ctx, fmt.Sprintf("set default port group of vm instance %s to %s", vm.UUID, defaultL3Uuid))
+		_, err := runJob(ctx, job{
+			name:         "set default port group",
+			resourceUuid: vm.UUID,
+		}, func() (any, error) {
+			return cli.UpdateVmInstance(vm.UUID, param.UpdateVmInstanceParam{
+				UpdateVmInstance: param.UpdateVmInstanceDetailParam{
+					Name:                 vm.Name,
+					DefaultL3NetworkUuid: &defaultL3Uuid,
+				},
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("fail to set default port group of vm instance %s to %s, err: %w", vm.UUID, defaultL3Uuid, err)
+		}
+	}
+
+	for i, nic := range planNics {
+		j := matches[i]
+		if j < 0 || nic.StaticIp.IsUnknown() || nic.StaticIp.ValueString() == "" || nic.StaticIp.Equal(stateNics[j].StaticIp) {
+			continue
+		}
+
+		l3Uuid := nic.L3NetworkUuid.ValueString()
+		tflog.Info(ctx, fmt.Sprintf("set static ip of vm instance %s on port group %s to %s", vm.UUID, l3Uuid, nic.StaticIp.ValueString()))
+		_, err := runJob(ctx, job{
+			name:         "set static ip",
+			resourceUuid: vm.UUID,
+		}, func() (any, error) {
+			return nil, cli.SetVmStaticIp(vm.UUID, param.SetVmStaticIpParam{
+				SetVmStaticIp: param.SetVmStaticIpDetailParam{
+					L3NetworkUuid: l3Uuid,
+					Ip:            nic.StaticIp.ValueString(),
+				},
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("fail to set static ip %s of vm instance %s, err: %w", nic.StaticIp.ValueString(), vm.UUID, err)
+		}
+	}
+
+	kept := make([]bool, len(stateNics))
+	for _, j := range matches {
+		if j >= 0 {
+			kept[j] = true
+		}
+	}
+
+	// a port group can be attached more than once, the NIC with the highest device id on it is detached first
+	detached := make(map[string]bool)
+	for j, nic := range stateNics {
+		if kept[j] {
+			continue
+		}
+
+		vmNic := findVmNic(vm, nic.L3NetworkUuid.ValueString(), detached)
+		if vmNic == nil {
+			tflog.Warn(ctx, fmt.Sprintf("vm instance %s has no NIC on port group %s, nothing to detach", vm.UUID, nic.L3NetworkUuid.ValueString()))
+			continue
+		}
+		detached[vmNic.UUID] = true
+
+		tflog.Info(ctx, fmt.Sprintf("detach NIC %s from vm instance %s", vmNic.UUID, vm.UUID))
+		_, err := runJob(ctx, job{
+			name:         "detach NIC",
+			resourceUuid: vmNic.UUID,
+		}, func() (any, error) {
+			return cli.DetachL3NetworkFromVm(vmNic.UUID)
+		})
+		if err != nil {
+			return fmt.Errorf("fail to detach NIC %s from vm instance %s, err: %w", vmNic.UUID, vm.UUID, err)
+		}
+	}
+
+	return nil
+}
+
+// findVmNic returns the NIC of the vm on the port group with the highest device id that is not excluded.
+func findVmNic(vm *view.VmInstanceInventoryView, l3Uuid string, excluded map[string]bool) *view.VmNicInventoryView {
+	var found *view.VmNicInventoryView
+	for i := range vm.VMNics {
+		nic := &vm.VMNics[i]
+		if nic.L3NetworkUUID != l3Uuid || excluded[nic.UUID] {
+			continue
+		}
+		if found == nil || nic.DeviceID > found.DeviceID {
+			found = nic
+		}
+	}
+	return found
+}
+
+// orderVmNics lists the NICs of a vm in the order of the configured network interfaces,
+// followed by the NICs that are not configured in device order.
+func orderVmNics(nics []view.VmNicInventoryView, priorNics []NetworkInterfaceModel) []view.VmNicInventoryView {
+	used := make([]bool, len(nics))
+	ordered := make([]view.VmNicInventoryView, 0, len(nics))
+	for _, prior := range priorNics {
+		for i := range nics {
+			if !used[i] && nics[i].L3NetworkUUID == prior.L3NetworkUuid.ValueString() {
+				used[i] = true
+				ordered = append(ordered, nics[i])
+				break
+			}
+		}
+	}
+	for i := range nics {
+		if !used[i] {
+			ordered = append(ordered, nics[i])
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,21 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+)
+
+// resourceNotFound confirms a failed get by querying the resource collection by uuid.
+// It only reports true when the query itself succeeds and finds nothing, so network,
+// authentication and server errors are never mistaken for a deleted resource.
+func resourceNotFound[T any](query func(param.QueryParam) ([]T, error), uuid string) (bool, error) {
+	qparam := param.NewQueryParam()
+	qparam.AddQ("uuid=" + uuid)
+
+	resources, err := query(qparam)
+	if err != nil {
+		return false, err
+	}
+	return len(resources) == 0, nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ planmodifier.Int64 = disallowShrinkModifier{}
+
+// disallowShrinkModifier rejects a plan that makes a disk size, in gigabytes, smaller than it is in state.
+type disallowShrinkModifier struct{}
+
+func disallowShrink() planmodifier.Int64 {
+	return disallowShrinkModifier{}
+}
+
+func (m disallowShrinkModifier) Description(_ context.Context) string {
+	return "The size can only be grown, shrinking is rejected."
+}
+
+func (m disallowShrinkModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m disallowShrinkModifier) PlanModifyInt64(ctx context.Context, req planmodifier.Int64Request, resp *planmodifier.Int64Response) {
+	if req.StateValue.IsNull() || req.PlanValue.IsNull() || req.PlanValue.IsUnknown() {
+		return
+	}
+
+	if req.PlanValue.ValueInt64() < req.StateValue.ValueInt64() {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Params Error",
+			fmt.Sprintf("disk cannot be shrunk from %d GB to %d GB", req.StateValue.ValueInt64(), req.PlanValue.ValueInt64()),
+		)
+	}
+}
+
+var _ planmodifier.String = clearWhenRemovedModifier{}
+
+// clearWhenRemovedModifier plans an empty value for an optional and computed string that was removed from the
+// configuration. Terraform keeps the value in state for such attributes, so without it the value could not be cleared.
+type clearWhenRemovedModifier struct{}
+
+func clearWhenRemoved() planmodifier.String {
+	return clearWhenRemovedModifier{}
+}
+
+func (m clearWhenRemovedModifier) Description(_ context.Context) string {
+	return "Removing the value from the configuration clears it."
+}
+
+func (m clearWhenRemovedModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m clearWhenRemovedModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if !req.ConfigValue.IsNull() || req.StateValue.IsNull() {
+		return
+	}
+	resp.PlanValue = types.StringValue("")
+}
@@ -0,0 +1,128 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+)
+
+const (
+	vmStateStopped = "Stopped"
+	vmStatePaused  = "Paused"
+
+	vmStopModeGraceful = "graceful"
+	vmStopModeForced   = "forced"
+
+	defaultVmStopTimeout = 300 * time.Second
+)
+
+// setVmPowerState brings a vm instance to the desired power state, Running, Stopped or Paused.
+// It reports whether the power state had to be changed.
+func setVmPowerState(ctx context.Context, cli *client.ZSClient, uuid string, desired string, stopMode string, stopTimeout time.Duration) (bool, error) {
+	vm, err := cli.GetVmInstance(uuid)
+	if err != nil {
+		return false, fmt.Errorf("failed to get vm instance %s, err: %v", uuid, err)
+	}
+	if vm.State == desired {
+		return false, nil
+	}
+
+	start := func() (any, error) {
+		return cli.StartVmInstance(uuid, nil)
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("change power state of vm instance %s from %s to %s", uuid, vm.State, desired))
+	switch desired {
+	case vmStateRunning:
+		if vm.State == vmStatePaused {
+			_, err = runJob(ctx, powerStateJob("resume vm instance", cli, uuid), func() (any, error) {
+				return cli.ResumeVmInstance(uuid)
+			})
+		} else {
+			_, err = runJob(ctx, powerStateJob("start vm instance", cli, uuid), start)
+		}
+	case vmStateStopped:
+		err = powerOffVmInstance(ctx, cli, uuid, stopMode == vmStopModeForced, stopTimeout)
+	case vmStatePaused:
+		// only a running vm can be paused
+		if vm.State != vmStateRunning {
+			if _, err = runJob(ctx, powerStateJob("start vm instance", cli, uuid), start); err != nil {
+				break
+			}
+		}
+		_, err = runJob(ctx, powerStateJob("pause vm instance", cli, uuid), func() (any, error) {
+			return cli.PauseVmInstance(uuid)
+		})
+	default:
+		return false, fmt.Errorf("power state %s is invalid, valid value is Running, Stopped or Paused", desired)
+	}
+
+	if err != nil {
+		return true, fmt.Errorf("failed to change power state of vm instance %s to %s, err: %w", uuid, desired, err)
+	}
+	return true, nil
+}
+
+// powerStateJob describes a job changing the power state of a vm instance.
+func powerStateJob(name string, cli *client.ZSClient, uuid string) job {
+	return job{
+		name:         name,
+		resourceUuid: uuid,
+		progress: func() (string, error) {
+			return vmInstanceProgress(cli, uuid)
+		},
+	}
+}
+
+// vmStopTimeout returns how long a graceful stop of the vm instance may take.
+func vmStopTimeout(model vmInstanceDataSourceModel) time.Duration {
+	if model.StopTimeout.IsNull() || model.StopTimeout.IsUnknown() {
+		return defaultVmStopTimeout
+	}
+	return time.Duration(model.StopTimeout.ValueInt64()) * time.Second
+}
+
+// powerOffVmInstance stops a vm instance. A graceful stop that fails or does not finish within
+// the timeout is followed by a forced stop, like pulling the power cord.
+func powerOffVmInstance(ctx context.Context, cli *client.ZSClient, uuid string, force bool, timeout time.Duration) error {
+	if !force {
+		gracefulCtx, cancel := context.WithTimeout(ctx, timeout)
+		_, err := runJob(gracefulCtx, powerStateJob("stop vm instance gracefully", cli, uuid), func() (any, error) {
+			return cli.StopVmInstance(uuid, param.StopVmInstanceParam{
+				StopVmInstance: param.StopVmInstanceDetailParam{
+					Type:   param.Grace,
+					StopHA: true,
+				},
+			})
+		})
+		cancel()
+
+		switch {
+		case err == nil:
+			return nil
+		case ctx.Err() != nil:
+			return err
+		case errors.Is(err, errJobNotFinished):
+			tflog.Warn(ctx, fmt.Sprintf("vm instance %s did not stop gracefully within %s, force it to stop", uuid, timeout))
+		default:
+			tflog.Warn(ctx, fmt.Sprintf("graceful stop of vm instance %s failed, force it to stop. error: %v", uuid, err))
+		}
+	}
+
+	_, err := runJob(ctx, powerStateJob("stop vm instance", cli, uuid), func() (any, error) {
+		return cli.StopVmInstance(uuid, param.StopVmInstanceParam{
+			StopVmInstance: param.StopVmInstanceDetailParam{
+				Type:   param.Cold,
+				StopHA: true,
+			},
+		})
+	})
+	return err
+}
@@ -0,0 +1,179 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+)
+
+// probeTimeout limits how long Configure waits for the ZSphere API to answer the reachability check.
+const probeTimeout = 15 * time.Second
+
+// configSources records where the values of the provider attributes came from, so that a diagnostic
+// can tell the user which value to fix.
+type configSources map[string]string
+
+// describe lists where the values of the attributes came from.
+func (s configSources) describe(attrs ...string) string {
+	var lines []string
+	for _, attr := range attrs {
+		if source, ok := s[attr]; ok {
+			lines = append(lines, fmt.Sprintf("- %s is set by %s", attr, source))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// probeFailure is a failed probe of the ZSphere API, described for the user.
+type probeFailure struct {
+	summary string
+	detail  string
+}
+
+// checkApiReachable sends a plain HTTP request to the ZSphere API, without retries, to tell network and TLS
+// problems apart from authentication errors. Any HTTP response means the API can be reached.
+func checkApiReachable(ctx context.Context, transport http.RoundTripper, apiUrl string, sources configSources) *probeFailure {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiUrl, nil)
+	if err != nil {
+		return &probeFailure{
+			summary: "Invalid ZSphere API Address",
+			detail:  fmt.Sprintf("%s is not a valid URL, err: %v\n\n%s", apiUrl, err, sources.describe("endpoint", "scheme", "host", "port")),
+		}
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err == nil {
+		resp.Body.Close()
+		return nil
+	}
+
+	var dnsErr *net.DNSError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCert x509.CertificateInvalidError
+	var recordHeaderErr tls.RecordHeaderError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &dnsErr):
+		return &probeFailure{
+			summary: "Unreachable ZSphere API Host",
+			detail: fmt.Sprintf("The host of the ZSphere API cannot be resolved, check the host name. err: %v\n\n%s",
+				err, sources.describe("endpoint", "host")),
+		}
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return &probeFailure{
+			summary: "Wrong ZSphere API Port",
+			detail: fmt.Sprintf("The host of the ZSphere API refused the connection, nothing listens on the port. "+
+				"The ZSphere API listens on port 8080 by default. err: %v\n\n%s", err, sources.describe("endpoint", "host", "port")),
+		}
+	case errors.As(err, &unknownAuthority), errors.As(err, &hostnameErr), errors.As(err, &invalidCert):
+		return &probeFailure{
+			summary: "Untrusted ZSphere API Certificate",
+			detail: fmt.Sprintf("The HTTPS certificate of the ZSphere API cannot be verified. Set ca_cert_file or ca_cert_pem to the CA "+
+				"that issued it, or insecure_skip_verify for testing. err: %v\n\n%s", err, sources.describe("endpoint", "host", "ca_cert_file", "ca_cert_pem")),
+		}
+	case errors.As(err, &recordHeaderErr):
+		return &probeFailure{
+			summary: "Wrong ZSphere API Scheme",
+			detail: fmt.Sprintf("The ZSphere API does not speak HTTPS on this port, set the scheme to http or use the HTTPS port. err: %v\n\n%s",
+				err, sources.describe("endpoint", "scheme", "port")),
+		}
+	case errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH), errors.As(err, &netErr) && netErr.Timeout():
+		return &probeFailure{
+			summary: "Unreachable ZSphere API Host",
+			detail: fmt.Sprintf("The host of the ZSphere API cannot be reached, check the host, the network and the firewall between them. err: %v\n\n%s",
+				err, sources.describe("endpoint", "host", "port", "http_proxy")),
+		}
+	}
+
+	return &probeFailure{
+		summary: "Unreachable ZSphere API",
+		detail:  fmt.Sprintf("The ZSphere API at %s cannot be reached, err: %v\n\n%s", apiUrl, err, sources.describe("endpoint", "scheme", "host", "port", "http_proxy")),
+	}
+}
+
+// probeCredentials makes a cheap authenticated call to check the access key or session of the client.
+func probeCredentials(cli *client.ZSClient) error {
+	qparam := param.NewQueryParam()
+	qparam.Limit(1)
+	_, err := cli.QueryZone(qparam)
+	return err
+}
+
+// authFailure describes a failed authenticated call to a reachable ZSphere API.
+func authFailure(err error, accessKey bool, sources configSources) *probeFailure {
+	message := strings.ToLower(err.Error())
+	contains := func(words ...string) bool {
+		for _, word := range words {
+			if strings.Contains(message, word) {
+				return true
+			}
+		}
+		return false
+	}
+
+	credentials := []string{"account_name", "account_password"}
+	if accessKey {
+		credentials = []string{"access_key_id", "access_key_secret"}
+	}
+
+	switch {
+	case contains("invalid character", "unexpected end of json", "cannot unmarshal", "404 not found", "status code: 404"):
+		return &probeFailure{
+			summary: "Wrong ZSphere API Port",
+			detail: fmt.Sprintf("The server answered, but not as the ZSphere API. Check the port, 8080 by default, and the path of the endpoint. err: %v\n\n%s",
+				err, sources.describe("endpoint", "host", "port")),
+		}
+	case accessKey && contains("expired"):
+		return &probeFailure{
+			summary: "Expired ZSphere AccessKey",
+			detail: fmt.Sprintf("The AccessKey has expired. Create a new one in Operational Management->Access Control->AccessKey Management. err: %v\n\n%s",
+				err, sources.describe("access_key_id")),
+		}
+	case accessKey && contains("disabled", "disable"):
+		return &probeFailure{
+			summary: "Disabled ZSphere AccessKey",
+			detail: fmt.Sprintf("The AccessKey is disabled. Enable it in Operational Management->Access Control->AccessKey Management. err: %v\n\n%s",
+				err, sources.describe("access_key_id")),
+		}
+	case accessKey && contains("signature", "secret", "does not match"):
+		return &probeFailure{
+			summary: "Wrong ZSphere AccessKey Secret",
+			detail: fmt.Sprintf("The AccessKey exists, but the request was signed with the wrong secret. Check access_key_secret. err: %v\n\n%s",
+				err, sources.describe(credentials...)),
+		}
+	case accessKey && contains("not found", "not exist", "invalid accesskey", "unknown accesskey"):
+		return &probeFailure{
+			summary: "Unknown ZSphere AccessKey",
+			detail: fmt.Sprintf("No AccessKey with this access_key_id exists on the ZSphere platform. err: %v\n\n%s",
+				err, sources.describe(credentials...)),
+		}
+	case !accessKey && contains("password", "wrong account", "incorrect", "not found", "not exist"):
+		return &probeFailure{
+			summary: "Wrong ZSphere Account Name or Password",
+			detail: fmt.Sprintf("The ZSphere platform rejected the account name or password. err: %v\n\n%s",
+				err, sources.describe(credentials...)),
+		}
+	}
+
+	return &probeFailure{
+		summary: "Unable to Authenticate to the ZSphere API",
+		detail: fmt.Sprintf("The ZSphere API can be reached, but the authenticated probe failed. err: %v\n\n%s",
+			err, sources.describe(append([]string{"endpoint", "host", "port"}, credentials...)...)),
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// testSources are the sources of the provider attributes the tests expect in diagnostics.
+var testSources = configSources{
+	"host":              `the "host" attribute of the provider configuration`,
+	"port":              `the "port" attribute of the provider configuration`,
+	"account_password":  "the ZSPHERE_ACCOUNT_PASSWORD environment variable",
+	"access_key_id":     `the "access_key_id" attribute of the provider configuration`,
+	"access_key_secret": "the ZSPHERE_ACCESS_KEY_SECRET environment variable",
+}
+
+func TestAuthFailure(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		accessKey  bool
+		summary    string
+		wantSource string
+	}{
+		{
+			name:       "wrong password",
+			err:        errors.New("status code: 400, error: wrong account name or password"),
+			summary:    "Wrong ZSphere Account Name or Password",
+			wantSource: "ZSPHERE_ACCOUNT_PASSWORD",
+		},
+		{
+			name:       "unknown access key",
+			err:        errors.New("status code: 401, error: AccessKey[8nEKmBL2mgMbYaHkpRkU] not found"),
+			accessKey:  true,
+			summary:    "Unknown ZSphere AccessKey",
+			wantSource: `"access_key_id"`,
+		},
+		{
+			name:       "wrong access key secret",
+			err:        errors.New("status code: 401, error: the signature does not match"),
+			accessKey:  true,
+			summary:    "Wrong ZSphere AccessKey Secret",
+			wantSource: "ZSPHERE_ACCESS_KEY_SECRET",
+		},
+		{
+			name:       "expired access key",
+			err:        errors.New("status code: 401, error: AccessKey has expired"),
+			accessKey:  true,
+			summary:    "Expired ZSphere AccessKey",
+			wantSource: `"access_key_id"`,
+		},
+		{
+			name:       "not the ZSphere API",
+			err:        errors.New("invalid character '<' looking for beginning of value"),
+			summary:    "Wrong ZSphere API Port",
+			wantSource: `"port"`,
+		},
+		{
+			name:       "other error",
+			err:        errors.New("status code: 500, error: internal error"),
+			accessKey:  true,
+			summary:    "Unable to Authenticate to the ZSphere API",
+			wantSource: `"host"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failure := authFailure(tt.err, tt.accessKey, testSources)
+			if failure.summary != tt.summary {
+				t.Errorf("authFailure() summary = %q, want %q", failure.summary, tt.summary)
+			}
+			if !strings.Contains(failure.detail, tt.err.Error()) {
+				t.Errorf("authFailure() detail = %q, want it to contain the error", failure.detail)
+			}
+			if !strings.Contains(failure.detail, tt.wantSource) {
+				t.Errorf("authFailure() detail = %q, want it to name %s", failure.detail, tt.wantSource)
+			}
+		})
+	}
+}
+
+// roundTripperFunc is a transport failing or answering every request with a function.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestCheckApiReachable(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer api.Close()
+
+	tlsApi := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer tlsApi.Close()
+
+	// a port nothing listens on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedUrl := fmt.Sprintf("http://%s/zstack", listener.Addr())
+	listener.Close()
+
+	transport, err := newHttpTransport(transportConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		url       string
+		transport http.RoundTripper
+		summary   string
+		// source is the attribute the diagnostic names as the one to fix.
+		source string
+	}{
+		{
+			name:    "reachable",
+			url:     api.URL + "/zstack",
+			summary: "",
+		},
+		{
+			name:    "unknown host",
+			url:     "http://zsphere.invalid:8080/zstack",
+			summary: "Unreachable ZSphere API Host",
+			source:  `"host"`,
+		},
+		{
+			name: "unreachable host",
+			url:  "http://10.0.0.1:8080/zstack",
+			transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+				return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.EHOSTUNREACH}
+			}),
+			summary: "Unreachable ZSphere API Host",
+			source:  `"host"`,
+		},
+		{
+			name:    "nothing listens on the port",
+			url:     closedUrl,
+			summary: "Wrong ZSphere API Port",
+			source:  `"port"`,
+		},
+		{
+			name:    "untrusted certificate",
+			url:     tlsApi.URL + "/zstack",
+			summary: "Untrusted ZSphere API Certificate",
+			source:  `"host"`,
+		},
+		{
+			name:    "https to a plain http port",
+			url:     strings.Replace(api.URL, "http://", "https://", 1) + "/zstack",
+			summary: "Wrong ZSphere API Scheme",
+			source:  `"port"`,
+		},
+		{
+			name:    "invalid address",
+			url:     "http://zsphere host/zstack",
+			summary: "Invalid ZSphere API Address",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := tt.transport
+			if rt == nil {
+				rt = transport
+			}
+
+			failure := checkApiReachable(context.Background(), rt, tt.url, testSources)
+			switch {
+			case tt.summary == "" && failure != nil:
+				t.Fatalf("checkApiReachable() = %q: %s, want nil", failure.summary, failure.detail)
+			case tt.summary == "":
+			case failure == nil:
+				t.Fatalf("checkApiReachable() = nil, want %q", tt.summary)
+			case failure.summary != tt.summary:
+				t.Errorf("checkApiReachable() summary = %q, want %q, detail: %s", failure.summary, tt.summary, failure.detail)
+			case !strings.Contains(failure.detail, tt.source):
+				t.Errorf("checkApiReachable() detail = %q, want it to name %s", failure.detail, tt.source)
+			}
+		})
+	}
+}
@@ -9,9 +9,15 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"strconv"
+	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/function"
@@ -19,6 +25,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
@@ -38,12 +45,29 @@ type ZSphereProvider struct {
 }
 
 type ZSphereProviderModel struct {
-	Host            types.String `tfsdk:"host"`
-	Port            types.Int64  `tfsdk:"port"`
-	AccountName     types.String `tfsdk:"account_name"`
-	AccountPassword types.String `tfsdk:"account_password"`
-	AccessKeyId     types.String `tfsdk:"access_key_id"`
-	AccessKeySecret types.String `tfsdk:"access_key_secret"`
+	Host               types.String `tfsdk:"host"`
+	Port               types.Int64  `tfsdk:"port"`
+	AccountName        types.String `tfsdk:"account_name"`
+	AccountPassword    types.String `tfsdk:"account_password"`
+	AccessKeyId        types.String `tfsdk:"access_key_id"`
+	AccessKeySecret    types.String `tfsdk:"access_key_secret"`
+	MaxRetries         types.Int64  `tfsdk:"max_retries"`
+	RetryMinBackoff    types.String `tfsdk:"retry_min_backoff"`
+	RetryMaxBackoff    types.String `tfsdk:"retry_max_backoff"`
+	Scheme             types.String `tfsdk:"scheme"`
+	Endpoint           types.String `tfsdk:"endpoint"`
+	CaCertFile         types.String `tfsdk:"ca_cert_file"`
+	CaCertPem          types.String `tfsdk:"ca_cert_pem"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+	ClientCertFile     types.String `tfsdk:"client_cert_file"`
+	ClientKeyFile      types.String `tfsdk:"client_key_file"`
+	ClientCertPem      types.String `tfsdk:"client_cert_pem"`
+	ClientKeyPem       types.String `tfsdk:"client_key_pem"`
+	HttpProxy          types.String `tfsdk:"http_proxy"`
+	DebugHttp          types.Bool   `tfsdk:"debug_http"`
+	ReadOnly           types.Bool   `tfsdk:"read_only"`
+	SessionCache       types.Bool   `tfsdk:"session_cache"`
+	Profile            types.String `tfsdk:"profile"`
 }
 
 func (p *ZSphereProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -55,8 +79,9 @@ func (p *ZSphereProvider) Schema(ctx context.Context, req provider.SchemaRequest
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"host": schema.StringAttribute{
-				Description: "ZSphere Cloud MN HOST ip address. May also be provided via ZSphere_HOST environment variable.",
-				Required:    true,
+				Description: "ZSphere Cloud MN HOST ip address. May also be provided via ZSphere_HOST environment variable. " +
+					"Required unless `endpoint` is set.",
+				Optional: true,
 			},
 			"port": schema.Int64Attribute{
 				Description: "ZSphere Cloud MN API port. May also be provided via ZSphere_PORT environment variable.",
@@ -94,6 +119,100 @@ func (p *ZSphereProvider) Schema(ctx context.Context, req provider.SchemaRequest
 				Optional:  true,
 				Sensitive: true,
 			},
+			"max_retries": schema.Int64Attribute{
+				Description: "Maximum number of times a ZSphere API request is retried after a transient error, such as HTTP 503, a reset connection or a busy resource. " +
+					"Set to 0 to disable retries. Defaults to 3. May also be provided via ZSPHERE_MAX_RETRIES environment variable.",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"retry_min_backoff": schema.StringAttribute{
+				Description: "Time to wait before the first retry, such as \"1s\" or \"500ms\". The wait doubles with every retry. Defaults to 1s. " +
+					"May also be provided via ZSPHERE_RETRY_MIN_BACKOFF environment variable.",
+				Optional: true,
+			},
+			"retry_max_backoff": schema.StringAttribute{
+				Description: "Maximum time to wait between two retries, such as \"30s\". Defaults to 30s. " +
+					"May also be provided via ZSPHERE_RETRY_MAX_BACKOFF environment variable.",
+				Optional: true,
+			},
+			"scheme": schema.StringAttribute{
+				Description: "Scheme of the ZSphere API, `http` or `https`. Defaults to `http`. May also be provided via ZSPHERE_SCHEME environment variable.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(schemeHttp, schemeHttps),
+				},
+			},
+			"endpoint": schema.StringAttribute{
+				Description: "Full URL of the ZSphere API, such as `https://zsphere.example.com/zstack`, for a management node behind a reverse proxy. " +
+					"The port defaults to the one of the scheme and the path to `/zstack`. Mutually exclusive with `host`, `port` and `scheme`. " +
+					"May also be provided via ZSPHERE_ENDPOINT environment variable.",
+				Optional: true,
+			},
+			"ca_cert_file": schema.StringAttribute{
+				Description: "Path to a PEM encoded CA certificate used to verify the HTTPS certificate of the ZSphere API, in addition to the system CAs. " +
+					"Mutually exclusive with `ca_cert_pem`. May also be provided via ZSPHERE_CA_CERT_FILE environment variable.",
+				Optional: true,
+			},
+			"ca_cert_pem": schema.StringAttribute{
+				Description: "PEM encoded CA certificate used to verify the HTTPS certificate of the ZSphere API, in addition to the system CAs. " +
+					"Mutually exclusive with `ca_cert_file`. May also be provided via ZSPHERE_CA_CERT_PEM environment variable.",
+				Optional: true,
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				Description: "Skip the verification of the HTTPS certificate of the ZSphere API. Only use it for testing. " +
+					"May also be provided via ZSPHERE_INSECURE_SKIP_VERIFY environment variable.",
+				Optional: true,
+			},
+			"client_cert_file": schema.StringAttribute{
+				Description: "Path to a PEM encoded client certificate for HTTPS client certificate authentication. Requires `client_key_file` or `client_key_pem`. " +
+					"May also be provided via ZSPHERE_CLIENT_CERT_FILE environment variable.",
+				Optional: true,
+			},
+			"client_key_file": schema.StringAttribute{
+				Description: "Path to the PEM encoded private key of the client certificate. " +
+					"May also be provided via ZSPHERE_CLIENT_KEY_FILE environment variable.",
+				Optional: true,
+			},
+			"client_cert_pem": schema.StringAttribute{
+				Description: "PEM encoded client certificate for HTTPS client certificate authentication. Mutually exclusive with `client_cert_file`. " +
+					"May also be provided via ZSPHERE_CLIENT_CERT_PEM environment variable.",
+				Optional: true,
+			},
+			"client_key_pem": schema.StringAttribute{
+				Description: "PEM encoded private key of the client certificate. Mutually exclusive with `client_key_file`. " +
+					"May also be provided via ZSPHERE_CLIENT_KEY_PEM environment variable.",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"http_proxy": schema.StringAttribute{
+				Description: "URL of the proxy used to reach the ZSphere API, such as `http://proxy.example.com:3128`. " +
+					"Defaults to the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables. May also be provided via ZSPHERE_HTTP_PROXY environment variable.",
+				Optional: true,
+			},
+			"debug_http": schema.BoolAttribute{
+				Description: "Log the requests to the ZSphere API and their responses at DEBUG level, visible with TF_LOG=DEBUG. " +
+					"Passwords, secrets, user data and sessions are redacted. Defaults to false. May also be provided via ZSPHERE_DEBUG_HTTP environment variable.",
+				Optional: true,
+			},
+			"read_only": schema.BoolAttribute{
+				Description: "Make the provider read-only: plans, refreshes and data sources work, but every create, update or delete of a resource fails " +
+					"before it changes anything. Use it to run plans against production. Defaults to false. May also be provided via ZSPHERE_READ_ONLY environment variable.",
+				Optional: true,
+			},
+			"session_cache": schema.BoolAttribute{
+				Description: "Keep the session of account authentication in `~/.zsphere/sessions`, per management node and account, so that parallel and later runs reuse it " +
+					"instead of each logging in. Without it, the provider logs out when it shuts down. An expired session is renewed automatically either way. " +
+					"Defaults to false. May also be provided via ZSPHERE_SESSION_CACHE environment variable.",
+				Optional: true,
+			},
+			"profile": schema.StringAttribute{
+				Description: "Name of the profile to read settings from in the config file, `~/.zsphere/config` or the path in ZSPHERE_CONFIG_FILE. " +
+					"Settings in the provider configuration take precedence over the ZSPHERE_* environment variables, which take precedence over the profile. " +
+					"Defaults to the `default` profile if the config file has one. May also be provided via ZSPHERE_PROFILE environment variable.",
+				Optional: true,
+			},
 		},
 	}
 }
@@ -160,57 +279,194 @@ func (p *ZSphereProvider) Configure(ctx context.Context, req provider.ConfigureR
 		)
 	}
 
-	if resp.Diagnostics.HasError() {
-		return
+	if config.MaxRetries.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("max_retries"),
+			"Unknown ZSphere API max_retries",
+			"Either target apply the source of the value first, set the value statically in the configuration, or use the ZSPHERE_MAX_RETRIES environment variable.",
+		)
 	}
 
-	//Defaukt value to environment vairiable, but override
-	//with Terraform configuration value if set.
+	if config.RetryMinBackoff.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry_min_backoff"),
+			"Unknown ZSphere API retry_min_backoff",
+			"Either target apply the source of the value first, set the value statically in the configuration, or use the ZSPHERE_RETRY_MIN_BACKOFF environment variable.",
+		)
+	}
 
-	port := 8080
-	//sessionId := ""
+	if config.RetryMaxBackoff.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry_max_backoff"),
+			"Unknown ZSphere API retry_max_backoff",
+			"Either target apply the source of the value first, set the value statically in the configuration, or use the ZSPHERE_RETRY_MAX_BACKOFF environment variable.",
+		)
+	}
 
-	host := os.Getenv("ZSPHERE_HOST")
-	portstr := os.Getenv("ZSPHERE_PORT")
-	account_name := os.Getenv("ZSPHERE_ACCOUNT_NAME")
-	account_password := os.Getenv("ZSPHERE_ACCOUNT_PASSWORD")
-	access_key_id := os.Getenv("ZSPHERE_ACCESS_KEY_ID")
-	access_key_secret := os.Getenv("ZSPHERE_ACCESS_KEY_SECRET")
+	for attr, value := range map[string]types.String{
+		"scheme":           config.Scheme,
+		"endpoint":         config.Endpoint,
+		"ca_cert_file":     config.CaCertFile,
+		"ca_cert_pem":      config.CaCertPem,
+		"client_cert_file": config.ClientCertFile,
+		"client_key_file":  config.ClientKeyFile,
+		"client_cert_pem":  config.ClientCertPem,
+		"client_key_pem":   config.ClientKeyPem,
+		"http_proxy":       config.HttpProxy,
+		"profile":          config.Profile,
+	} {
+		if value.IsUnknown() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attr),
+				"Unknown ZSphere API "+attr,
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the ZSPHERE_"+strings.ToUpper(attr)+" environment variable.",
+			)
+		}
+	}
 
-	if portstr != "" {
-		if portInt, err := strconv.Atoi(portstr); err == nil {
-			port = portInt
+	for attr, value := range map[string]types.Bool{
+		"insecure_skip_verify": config.InsecureSkipVerify,
+		"debug_http":           config.DebugHttp,
+		"read_only":            config.ReadOnly,
+		"session_cache":        config.SessionCache,
+	} {
+		if value.IsUnknown() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attr),
+				"Unknown ZSphere API "+attr,
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the ZSPHERE_"+strings.ToUpper(attr)+" environment variable.",
+			)
 		}
 	}
 
-	if !config.Host.IsNull() {
-		host = config.Host.ValueString()
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	if !config.Port.IsNull() {
-		port = int(config.Port.ValueInt64())
+	// Settings come from the provider block, then the ZSPHERE_* environment variables,
+	// then the selected profile of the config file.
+	profile := os.Getenv(envName(profileAttr))
+	if !config.Profile.IsNull() {
+		profile = config.Profile.ValueString()
 	}
 
-	if !config.AccountName.IsNull() {
-		account_name = config.AccountName.ValueString()
+	var profileSettings settingsLayer
+	configFile, err := configFilePath(os.Getenv)
+	if err != nil {
+		if profile != "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("profile"),
+				"Unable to Find the ZSphere Config File",
+				fmt.Sprintf("The config file with profile %q cannot be found, set ZSPHERE_CONFIG_FILE to its path. err: %v", profile, err),
+			)
+			return
+		}
+	} else {
+		profileSettings, err = profileLayer(configFile, profile)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("profile"),
+				"Invalid ZSphere Profile",
+				err.Error(),
+			)
+			return
+		}
+	}
+	settings := newProviderSettings(configLayer(config), envLayer(os.Getenv), profileSettings)
+
+	port, err := settings.getInt("port", 8080)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("port"), "Invalid ZSphere API port", err.Error())
+	}
+
+	host := settings.get("host")
+	account_name := settings.get("account_name")
+	account_password := settings.get("account_password")
+	access_key_id := settings.get("access_key_id")
+	access_key_secret := settings.get("access_key_secret")
+
+	sources := settings.sources("host", "port", "scheme", "account_name", "account_password", "access_key_id", "access_key_secret",
+		"http_proxy", "ca_cert_file", "ca_cert_pem")
+	if _, ok := sources["port"]; !ok {
+		sources["port"] = fmt.Sprintf("the default port %d", port)
+	}
+
+	scheme := settings.get("scheme")
+	if scheme == "" {
+		scheme = schemeHttp
 	}
 
-	if !config.AccountPassword.IsNull() {
-		account_password = config.AccountPassword.ValueString()
+	contextPath := defaultContextPath
+	endpoint, err := settings.endpoint()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("endpoint"),
+			"Conflicting ZSphere API endpoint",
+			err.Error(),
+		)
+	}
+	if endpoint != "" {
+		ep, err := parseEndpoint(endpoint)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("endpoint"),
+				"Invalid ZSphere API endpoint",
+				fmt.Sprintf("%s must be a URL such as https://zsphere.example.com/zstack, got: %q, err: %v", settings.source("endpoint"), endpoint, err),
+			)
+		}
+		scheme, host, port, contextPath = ep.scheme, ep.host, ep.port, ep.contextPath
+
+		sources["endpoint"] = settings.source("endpoint")
+		for _, attr := range addressAttrs {
+			delete(sources, attr)
+		}
+	} else if scheme != schemeHttp && scheme != schemeHttps {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("scheme"),
+			"Invalid ZSphere API scheme",
+			fmt.Sprintf("%s must be http or https, got: %q", settings.source("scheme"), scheme),
+		)
 	}
 
-	if !config.AccessKeyId.IsNull() {
-		access_key_id = config.AccessKeyId.ValueString()
+	transportCfg := transportConfig{
+		caCertFile:     settings.get("ca_cert_file"),
+		caCertPem:      settings.get("ca_cert_pem"),
+		clientCertFile: settings.get("client_cert_file"),
+		clientKeyFile:  settings.get("client_key_file"),
+		clientCertPem:  settings.get("client_cert_pem"),
+		clientKeyPem:   settings.get("client_key_pem"),
+		httpProxy:      settings.get("http_proxy"),
 	}
 
-	if !config.AccessKeySecret.IsNull() {
-		access_key_secret = config.AccessKeySecret.ValueString()
+	getBool := func(attr string) bool {
+		b, err := settings.getBool(attr)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root(attr), "Invalid ZSphere API "+attr, err.Error())
+		}
+		return b
 	}
-	/*
-		if !config.SessionId.IsNull() {
-			sessionId = config.SessionId.ValueString()
+	transportCfg.insecureSkipVerify = getBool("insecure_skip_verify")
+	debugHttp := getBool("debug_http")
+	readOnly := getBool("read_only")
+	useSessionCache := getBool("session_cache")
+
+	for _, conflict := range []struct {
+		fileAttr, pemAttr   string
+		fileValue, pemValue string
+	}{
+		{"ca_cert_file", "ca_cert_pem", transportCfg.caCertFile, transportCfg.caCertPem},
+		{"client_cert_file", "client_cert_pem", transportCfg.clientCertFile, transportCfg.clientCertPem},
+		{"client_key_file", "client_key_pem", transportCfg.clientKeyFile, transportCfg.clientKeyPem},
+	} {
+		if conflict.fileValue != "" && conflict.pemValue != "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(conflict.fileAttr),
+				"Conflicting ZSphere API TLS settings",
+				fmt.Sprintf("%s and %s cannot be set at the same time.", settings.source(conflict.fileAttr), settings.source(conflict.pemAttr)),
+			)
 		}
-	*/
+	}
+
 	// If any of the expected configuration are missing, return
 	// errors with provider-sepecific guidance.
 
@@ -219,7 +475,7 @@ func (p *ZSphereProvider) Configure(ctx context.Context, req provider.ConfigureR
 			path.Root("host"),
 			"Missing ZSphere API Host",
 			"The provider cannot create the ZSphere API client as there is a missing or empty value for the ZSphere API host. "+
-				"Set the host value in the configuration or use the ZSphere_HOST environment variable. "+
+				"Set the host or endpoint value in the configuration or the profile, or use the ZSphere_HOST or ZSPHERE_ENDPOINT environment variable. "+
 				"If either is already set, ensure the value is not empty.",
 		)
 	}
@@ -233,17 +489,68 @@ func (p *ZSphereProvider) Configure(ctx context.Context, req provider.ConfigureR
 				"account_name value can be set in the configuration or use the ZSphere_ACCOUNT_NAME environment variable\n"+
 				"account_password value in the configuration or use the ZSphere_ACCOUNT_PASSWORD environment variable\n"+
 				"access_key_id value in the configuration or use the ZSphere_ACCESS_KEY_ID environment variable\n"+
-				"access_key_secret value in the configuration or use the ZSphere_ACCESS_KEY_SECRET environment variable\n")
+				"access_key_secret value in the configuration or use the ZSphere_ACCESS_KEY_SECRET environment variable\n"+
+				"All of them can also be set in a profile of the config file.\n\n"+
+				"The authorization method is taken from the first of the configuration, the environment variables and the profile "+
+				"that sets any of them, and its values are not mixed with the other method.\n"+
+				settings.sources("account_name", "account_password", "access_key_id", "access_key_secret").describe(
+					"account_name", "account_password", "access_key_id", "access_key_secret"))
+	}
+
+	retry := retryPolicy{}
+	if retry.maxRetries, err = settings.getInt("max_retries", defaultMaxRetries); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("max_retries"), "Invalid ZSphere API max_retries", err.Error())
+	}
+	if retry.minBackoff, err = settings.getDuration("retry_min_backoff", defaultRetryMinBackoff); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("retry_min_backoff"), "Invalid ZSphere API retry backoff", err.Error())
+	}
+	if retry.maxBackoff, err = settings.getDuration("retry_max_backoff", defaultRetryMaxBackoff); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("retry_max_backoff"), "Invalid ZSphere API retry backoff", err.Error())
+	}
+	if retry.minBackoff > retry.maxBackoff {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry_min_backoff"),
+			"Invalid ZSphere API retry backoff",
+			fmt.Sprintf("retry_min_backoff %s must not be greater than retry_max_backoff %s", retry.minBackoff, retry.maxBackoff),
+		)
 	}
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	transport, err := newHttpTransport(transportCfg)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid ZSphere API TLS settings",
+			fmt.Sprintf("The provider cannot create the ZSphere API client, err: %v", err),
+		)
+		return
+	}
+	if scheme == schemeHttp && (transportCfg.caCertFile != "" || transportCfg.caCertPem != "" || transportCfg.clientCertFile != "" || transportCfg.clientCertPem != "") {
+		tflog.Warn(ctx, "TLS settings are ignored as the ZSphere API is reached over http, set scheme to https to use them")
+	}
+
+	var apiTransport http.RoundTripper = transport
+	if debugHttp {
+		apiTransport = &debugTransport{ctx: ctx, next: transport}
+	}
+	sessions := &sessionTransport{ctx: ctx, next: apiTransport}
+	httpClient := &http.Client{
+		Transport: &jobTransport{next: newRetryTransport(ctx, sessions, retry)},
+	}
+
 	var cli *client.ZSClient
 
 	ctx = tflog.SetField(ctx, "zsphere_host", host)
 	ctx = tflog.SetField(ctx, "zsphere_port", port)
+	ctx = tflog.SetField(ctx, "zsphere_scheme", scheme)
+
+	apiUrl := fmt.Sprintf("%s://%s/%s", scheme, net.JoinHostPort(host, strconv.Itoa(port)), contextPath)
+	if failure := checkApiReachable(ctx, transport, apiUrl, sources); failure != nil {
+		resp.Diagnostics.AddError(failure.summary, failure.detail)
+		return
+	}
 
 	if account_name != "" && account_password != "" {
 		ctx = tflog.SetField(ctx, "ZSphere_accountName", account_name)
@@ -251,17 +558,54 @@ func (p *ZSphereProvider) Configure(ctx context.Context, req provider.ConfigureR
 		ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, "ZSphere_accountPassword")
 
 		tflog.Debug(ctx, "Creating ZSphere client with account")
-		cli = client.NewZSClient(client.NewZSConfig(host, port, "zstack").LoginAccount(account_name, account_password).ReadOnly(false).Debug(true))
-		_, err := cli.Login()
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Unable to Create ZSphere API Client",
-				"An unexpected error occurred when creating the ZSphere API client. "+
-					"It might be due to an incorrect account name and password being set"+
-					"If the error is not clear, please contact the provider developers.\n\n"+
-					"ZSphere Client Error: "+err.Error(),
-			)
-			return
+		cli = client.NewZSClient(client.NewZSConfig(host, port, contextPath).Scheme(scheme).LoginAccount(account_name, account_password).HttpClient(httpClient).ReadOnly(readOnly).Debug(false))
+		sessions.login = func() (string, error) {
+			session, err := cli.Login()
+			if err != nil {
+				return "", err
+			}
+			return session.UUID, nil
+		}
+
+		if useSessionCache {
+			cache, err := newSessionCache(apiUrl, account_name)
+			if err != nil {
+				tflog.Warn(ctx, fmt.Sprintf("ZSphere session cache is disabled, err: %v", err))
+			}
+			sessions.cache = cache
+		}
+
+		// a cached session is renewed by the session transport if it expired
+		session := ""
+		if sessions.cache != nil {
+			if cached := sessions.cache.load(); cached != "" {
+				sessions.setSession(cached)
+				if err := cli.LoadSession(cached); err == nil && probeCredentials(cli) == nil {
+					tflog.Debug(ctx, "Reusing cached ZSphere session")
+					session = cached
+				}
+			}
+		}
+
+		if session == "" {
+			loginSession, err := cli.Login()
+			if err != nil {
+				failure := authFailure(err, false, sources)
+				resp.Diagnostics.AddError(failure.summary, failure.detail)
+				return
+			}
+			session = loginSession.UUID
+			sessions.setSession(session)
+
+			if sessions.cache != nil {
+				if err := sessions.cache.store(session); err != nil {
+					tflog.Warn(ctx, fmt.Sprintf("failed to cache ZSphere session, err: %v", err))
+				}
+			}
+		}
+
+		if sessions.cache == nil {
+			logoutOnShutdown(cli)
 		}
 	} else if access_key_id != "" && access_key_secret != "" {
 		ctx = tflog.SetField(ctx, "ZSphere_accessKeyId", access_key_id)
@@ -269,11 +613,23 @@ func (p *ZSphereProvider) Configure(ctx context.Context, req provider.ConfigureR
 		ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, "ZSphere_accessKeySecret")
 
 		tflog.Debug(ctx, "Creating ZSphere client with access key")
-		cli = client.NewZSClient(client.NewZSConfig(host, port, "zstack").AccessKey(access_key_id, access_key_secret).ReadOnly(false).Debug(true))
-		// no authorization validation! this access key may be invalid！
+		cli = client.NewZSClient(client.NewZSConfig(host, port, contextPath).Scheme(scheme).AccessKey(access_key_id, access_key_secret).HttpClient(httpClient).ReadOnly(readOnly).Debug(false))
+		err := probeCredentials(cli)
+		if err != nil {
+			failure := authFailure(err, true, sources)
+			resp.Diagnostics.AddError(failure.summary, failure.detail)
+			return
+		}
 	}
+	if readOnly {
+		tflog.Info(ctx, "ZSphere provider is read-only, resources cannot be created, updated or deleted")
+	}
+
 	resp.DataSourceData = cli
-	resp.ResourceData = cli
+	resp.ResourceData = &resourceProviderData{
+		client:   cli,
+		readOnly: readOnly,
+	}
 
 	tflog.Info(ctx, "Configured ZSphere client", map[string]any{"success": true})
 
@@ -283,6 +639,16 @@ func (p *ZSphereProvider) Resources(ctx context.Context) []func() resource.Resou
 	return []func() resource.Resource{
 		ImageResource,
 		InstanceResource,
+		L2NetworkResource,
+		PortGroupResource,
+		SecurityGroupResource,
+		SecurityGroupRuleResource,
+		VipResource,
+		EipResource,
+		EipAttachmentResource,
+		VirtualRouterOfferingResource,
+		VolumeResource,
+		VolumeAttachmentResource,
 	}
 }
 
@@ -300,8 +666,13 @@ func (p *ZSphereProvider) DataSources(ctx context.Context) []func() datasource.D
 		ZSphereImageStorageDataSource,
 		ZSphereImageDataSource,
 		ZSpherevmsDataSource,
+		ZSphereL2NetworkDataSource,
 		ZSphereL3NetworkDataSource,
 		ZSpherePrimaryStorageDataSource,
+		ZSphereSecurityGroupDataSource,
+		ZSphereVirtualRouterOfferingDataSource,
+		ZSphereVirtualRouterInstanceDataSource,
+		ZSphereVolumeDataSource,
 	}
 }
 
@@ -0,0 +1,31 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+)
+
+// resourceProviderData is what the provider passes to its resources: the ZSphere client and the
+// provider settings resources have to honour. Data sources only get the client.
+type resourceProviderData struct {
+	client   *client.ZSClient
+	readOnly bool
+}
+
+// checkWritable adds an error and returns false when the provider is read-only, so that a resource
+// fails before it changes anything on the ZSphere platform.
+func checkWritable(readOnly bool, diags *diag.Diagnostics, resourceType string, operation string) bool {
+	if !readOnly {
+		return true
+	}
+	diags.AddError(
+		"ZSphere Provider is Read-Only",
+		fmt.Sprintf("The provider is configured with read_only, so it cannot %s %s. "+
+			"Plans and data sources work as usual; unset read_only, ZSPHERE_READ_ONLY or read_only of the profile to apply changes.", operation, resourceType),
+	)
+	return false
+}
@@ -0,0 +1,26 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// keepPartiallyCreated adds the error of a create that failed after the resource was created, or while
+// the job creating it still runs, and saves the uuid of the resource in state. Terraform taints a resource
+// left in state by a failed create, so the next apply refreshes and replaces it instead of leaking it.
+func keepPartiallyCreated(ctx context.Context, resp *resource.CreateResponse, uuid string, summary string, detail string) {
+	resp.Diagnostics.AddError(summary, detail)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("uuid"), uuid)...)
+}
+
+// descriptionParam returns the description to send when a resource is updated. A description removed
+// from the configuration is sent empty, so that it is cleared on the platform rather than left unchanged.
+func descriptionParam(description types.String) *string {
+	value := description.ValueString()
+	return &value
+}
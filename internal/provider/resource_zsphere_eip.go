@@ -0,0 +1,278 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+var (
+	_ resource.Resource                = &eipResource{}
+	_ resource.ResourceWithConfigure   = &eipResource{}
+	_ resource.ResourceWithImportState = &eipResource{}
+)
+
+type eipResource struct {
+	client   *client.ZSClient
+	readOnly bool
+}
+
+type eipResourceModel struct {
+	Uuid        types.String `tfsdk:"uuid"`
+	Name        types.String `tfsdk:"name"`
+	Description types.String `tfsdk:"description"`
+	VipUuid     types.String `tfsdk:"vip_uuid"`
+	VipIp       types.String `tfsdk:"vip_ip"`
+	VmNicUuid   types.String `tfsdk:"vm_nic_uuid"`
+	GuestIp     types.String `tfsdk:"guest_ip"`
+	State       types.String `tfsdk:"state"`
+}
+
+func EipResource() resource.Resource {
+	return &eipResource{}
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *eipResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*resourceProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *resourceProviderData, got: %T. Please report this issue to the Provider developer. ", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.client
+	r.readOnly = providerData.readOnly
+}
+
+// Metadata implements resource.Resource.
+func (r *eipResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_eip"
+}
+
+// Schema implements resource.Resource.
+func (r *eipResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "This resource allows you to manage elastic IPs (EIPs) in ZSphere. " +
+			"An EIP maps the IP of a `zsphere_vip` to the IP of a VM NIC, which is bound with `zsphere_eip_attachment`, " +
+			"so the address stays the same when the instance behind it is replaced.",
+		Attributes: map[string]schema.Attribute{
+			"uuid": schema.StringAttribute{
+				Computed:    true,
+				Description: "The unique identifier of the EIP. Automatically generated by ZSphere.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the EIP.",
+			},
+			"description": schema.StringAttribute{
+				Optional:    true,
+				Description: "A description of the EIP.",
+			},
+			"vip_uuid": schema.StringAttribute{
+				Required:    true,
+				Description: "The UUID of the VIP of the EIP. Changing this forces a new EIP to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"vip_ip": schema.StringAttribute{
+				Computed:    true,
+				Description: "The IP address of the VIP of the EIP.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"vm_nic_uuid": schema.StringAttribute{
+				Computed:    true,
+				Description: "The UUID of the VM NIC the EIP is attached to, empty if it is not attached.",
+			},
+			"guest_ip": schema.StringAttribute{
+				Computed:    true,
+				Description: "The IP address of the VM NIC the EIP is attached to, empty if it is not attached.",
+			},
+			"state": schema.StringAttribute{
+				Computed:    true,
+				Description: "The state of the EIP, such as 'Enabled'.",
+			},
+		},
+	}
+}
+
+// Create implements resource.Resource.
+func (r *eipResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_eip", "create") {
+		return
+	}
+
+	var plan eipResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("create eip %s", plan.Name.ValueString()))
+	eip, err := r.client.CreateEip(param.CreateEipParam{
+		Params: param.CreateEipDetailParam{
+			Name:        plan.Name.ValueString(),
+			Description: plan.Description.ValueString(),
+			VipUuid:     plan.VipUuid.ValueString(),
+		},
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not create eip", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	readEipState(eip, &plan)
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read implements resource.Resource.
+func (r *eipResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state eipResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	eip, err := r.client.GetEip(state.Uuid.ValueString())
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryEip, state.Uuid.ValueString())
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("eip %s not found, remove it from state", state.Uuid.ValueString()))
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not read eip", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	readEipState(eip, &state)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *eipResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_eip", "update") {
+		return
+	}
+
+	var plan eipResourceModel
+	var state eipResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid := state.Uuid.ValueString()
+
+	tflog.Info(ctx, fmt.Sprintf("update eip %s", uuid))
+	eip, err := r.client.UpdateEip(uuid, param.UpdateEipParam{
+		UpdateEip: param.UpdateEipDetailParam{
+			Name:        plan.Name.ValueString(),
+			Description: descriptionParam(plan.Description),
+		},
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not update eip",
+			fmt.Sprintf("fail to update eip %s, err: %v", uuid, err),
+		)
+		return
+	}
+
+	readEipState(eip, &plan)
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete implements resource.Resource.
+func (r *eipResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_eip", "delete") {
+		return
+	}
+
+	var state eipResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid := state.Uuid.ValueString()
+	if uuid == "" {
+		tflog.Warn(ctx, "eip uuid is empty, so nothing to delete, skip it")
+		return
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("delete eip %s", uuid))
+	err := r.client.DeleteEip(uuid, param.DeleteModePermissive)
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryEip, uuid)
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("eip %s not found, nothing to delete", uuid))
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not delete eip", "Error: "+err.Error(),
+		)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState.
+func (r *eipResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("uuid"), req, resp)
+}
+
+func readEipState(eip *view.EipInventoryView, model *eipResourceModel) {
+	model.Uuid = types.StringValue(eip.UUID)
+	model.Name = types.StringValue(eip.Name)
+	if eip.Description != "" || !model.Description.IsNull() {
+		model.Description = types.StringValue(eip.Description)
+	}
+	model.VipUuid = types.StringValue(eip.VipUuid)
+	model.VipIp = types.StringValue(eip.VipIp)
+	model.VmNicUuid = types.StringValue(eip.VmNicUuid)
+	model.GuestIp = types.StringValue(eip.GuestIp)
+	model.State = types.StringValue(eip.State)
+}
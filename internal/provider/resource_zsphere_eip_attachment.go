@@ -0,0 +1,218 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+)
+
+var (
+	_ resource.Resource                = &eipAttachmentResource{}
+	_ resource.ResourceWithConfigure   = &eipAttachmentResource{}
+	_ resource.ResourceWithImportState = &eipAttachmentResource{}
+)
+
+type eipAttachmentResource struct {
+	client   *client.ZSClient
+	readOnly bool
+}
+
+type eipAttachmentResourceModel struct {
+	EipUuid   types.String `tfsdk:"eip_uuid"`
+	VmNicUuid types.String `tfsdk:"vm_nic_uuid"`
+}
+
+func EipAttachmentResource() resource.Resource {
+	return &eipAttachmentResource{}
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *eipAttachmentResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*resourceProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *resourceProviderData, got: %T. Please report this issue to the Provider developer. ", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.client
+	r.readOnly = providerData.readOnly
+}
+
+// Metadata implements resource.Resource.
+func (r *eipAttachmentResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_eip_attachment"
+}
+
+// Schema implements resource.Resource.
+func (r *eipAttachmentResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "This resource attaches an EIP to a VM NIC in ZSphere, such as one of the `vm_nics` of a `zsphere_instance`. " +
+			"Destroying the attachment detaches the EIP, the EIP and its VIP are kept for the next NIC.",
+		Attributes: map[string]schema.Attribute{
+			"eip_uuid": schema.StringAttribute{
+				Required:    true,
+				Description: "The UUID of the EIP to attach. Changing this forces a new attachment to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"vm_nic_uuid": schema.StringAttribute{
+				Required:    true,
+				Description: "The UUID of the VM NIC the EIP is attached to. Changing this forces a new attachment to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+	}
+}
+
+// Create implements resource.Resource.
+func (r *eipAttachmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_eip_attachment", "create") {
+		return
+	}
+
+	var plan eipAttachmentResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	eipUuid := plan.EipUuid.ValueString()
+	vmNicUuid := plan.VmNicUuid.ValueString()
+
+	tflog.Info(ctx, fmt.Sprintf("attach eip %s to vm nic %s", eipUuid, vmNicUuid))
+	_, err := r.client.AttachEip(eipUuid, vmNicUuid)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not attach eip",
+			fmt.Sprintf("fail to attach eip %s to vm nic %s, err: %v", eipUuid, vmNicUuid, err),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read implements resource.Resource.
+func (r *eipAttachmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state eipAttachmentResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	eipUuid := state.EipUuid.ValueString()
+	eip, err := r.client.GetEip(eipUuid)
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryEip, eipUuid)
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("eip %s not found, remove its attachment from state", eipUuid))
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not read eip", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	// an imported attachment only knows the eip, the vm nic is taken from the platform
+	if eip.VmNicUuid == "" || (!state.VmNicUuid.IsNull() && state.VmNicUuid.ValueString() != eip.VmNicUuid) {
+		tflog.Warn(ctx, fmt.Sprintf("eip %s is no longer attached to vm nic %s, remove the attachment from state", eipUuid, state.VmNicUuid.ValueString()))
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.VmNicUuid = types.StringValue(eip.VmNicUuid)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource. Every attribute forces replacement, so there is nothing to update in place.
+func (r *eipAttachmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan eipAttachmentResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete implements resource.Resource.
+func (r *eipAttachmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_eip_attachment", "delete") {
+		return
+	}
+
+	var state eipAttachmentResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	eipUuid := state.EipUuid.ValueString()
+	vmNicUuid := state.VmNicUuid.ValueString()
+
+	eip, err := r.client.GetEip(eipUuid)
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryEip, eipUuid)
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("eip %s not found, nothing to detach", eipUuid))
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not read eip", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	// the eip may already be attached to the nic of a replacement vm by another attachment
+	if eip.VmNicUuid != vmNicUuid {
+		tflog.Warn(ctx, fmt.Sprintf("eip %s is not attached to vm nic %s, nothing to detach", eipUuid, vmNicUuid))
+		return
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("detach eip %s from vm nic %s", eipUuid, vmNicUuid))
+	_, err = r.client.DetachEip(eipUuid)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not detach eip",
+			fmt.Sprintf("fail to detach eip %s from vm nic %s, err: %v", eipUuid, vmNicUuid, err),
+		)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState. The import ID is the UUID of an attached EIP.
+func (r *eipAttachmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("eip_uuid"), req, resp)
+}
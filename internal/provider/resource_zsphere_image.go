@@ -4,44 +4,66 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
-
 	"strings"
+	"time"
+
+	"terraform-provider-zsphere/internal/utils"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
 	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
 )
 
 var (
-	_ resource.Resource              = &imageResource{}
-	_ resource.ResourceWithConfigure = &imageResource{}
+	_ resource.Resource                = &imageResource{}
+	_ resource.ResourceWithConfigure   = &imageResource{}
+	_ resource.ResourceWithImportState = &imageResource{}
+)
+
+const (
+	imageStatusDeleted           = "Deleted"
+	imageBootModeSystemTagPrefix = "bootMode::"
+
+	defaultImageCreateTimeout = 60 * time.Minute
+	defaultImageUpdateTimeout = 10 * time.Minute
+	defaultImageDeleteTimeout = 20 * time.Minute
 )
 
 type imageResource struct {
-	client *client.ZSClient
+	client   *client.ZSClient
+	readOnly bool
 }
 
 type imageResourceModel struct {
-	Uuid               types.String `tfsdk:"uuid"`
-	Name               types.String `tfsdk:"name"`
-	Description        types.String `tfsdk:"description"`
-	Url                types.String `tfsdk:"url"`
-	MediaType          types.String `tfsdk:"media_type"`
-	GuestOsType        types.String `tfsdk:"guest_os_type"`
-	System             types.String `tfsdk:"system"`
-	Platform           types.String `tfsdk:"platform"`
-	Format             types.String `tfsdk:"format"`
-	BackupStorageUuids types.List   `tfsdk:"image_storage_uuids"`
-	Architecture       types.String `tfsdk:"architecture"`
-	Virtio             types.Bool   `tfsdk:"virtio"`
-	BootMode           types.String `tfsdk:"boot_mode"`
-	Expunge            types.Bool   `tfsdk:"expunge"`
+	Uuid               types.String   `tfsdk:"uuid"`
+	Name               types.String   `tfsdk:"name"`
+	Description        types.String   `tfsdk:"description"`
+	Url                types.String   `tfsdk:"url"`
+	MediaType          types.String   `tfsdk:"media_type"`
+	GuestOsType        types.String   `tfsdk:"guest_os_type"`
+	System             types.String   `tfsdk:"system"`
+	Platform           types.String   `tfsdk:"platform"`
+	Format             types.String   `tfsdk:"format"`
+	BackupStorageUuids types.List     `tfsdk:"image_storage_uuids"`
+	Architecture       types.String   `tfsdk:"architecture"`
+	Virtio             types.Bool     `tfsdk:"virtio"`
+	BootMode           types.String   `tfsdk:"boot_mode"`
+	Expunge            types.Bool     `tfsdk:"expunge"`
+	Timeouts           timeouts.Value `tfsdk:"timeouts"`
 }
 
 // Configure implements resource.ResourceWithConfigure.
@@ -50,16 +72,17 @@ func (r *imageResource) Configure(ctx context.Context, req resource.ConfigureReq
 		return
 	}
 
-	client, ok := req.ProviderData.(*client.ZSClient)
+	providerData, ok := req.ProviderData.(*resourceProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *client.ZSClient, got: %T. Please report this issue to the Provider developer. ", req.ProviderData),
+			fmt.Sprintf("Expected *resourceProviderData, got: %T. Please report this issue to the Provider developer. ", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = providerData.client
+	r.readOnly = providerData.readOnly
 }
 
 func ImageResource() resource.Resource {
@@ -68,6 +91,10 @@ func ImageResource() resource.Resource {
 
 // Create implements resource.Resource.
 func (r *imageResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_image", "create") {
+		return
+	}
+
 	var imagePlan imageResourceModel
 	diags := req.Plan.Get(ctx, &imagePlan)
 	resp.Diagnostics.Append(diags...)
@@ -75,8 +102,16 @@ func (r *imageResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
+	createTimeout, diags := imagePlan.Timeouts.Create(ctx, defaultImageCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
 	var backupStorageUuids []string
-	if imagePlan.BackupStorageUuids.IsNull() {
+	if imagePlan.BackupStorageUuids.IsNull() || imagePlan.BackupStorageUuids.IsUnknown() {
 		storage, err := r.client.QueryBackupStorage(param.QueryParam{})
 		if err != nil {
 			resp.Diagnostics.AddError(
@@ -92,40 +127,35 @@ func (r *imageResource) Create(ctx context.Context, req resource.CreateRequest,
 
 	var systemTags []string
 
-	if imagePlan.BootMode.IsNull() || imagePlan.BootMode.ValueString() == "" {
-		// if boot mode not set, use uefi in aarch64 and legacy in x86_64
-		if imagePlan.Architecture.ValueString() == "aarch64" {
-			systemTags = append(systemTags, param.SystemTagBootModeUEFI)
-		} else {
-			systemTags = append(systemTags, param.SystemTagBootModeLegacy)
-		}
-	} else {
-		bootMode := strings.ToLower(imagePlan.BootMode.ValueString())
-
-		switch bootMode {
-		case "uefi":
-			systemTags = append(systemTags, param.SystemTagBootModeUEFI)
-		case "legacy":
-			systemTags = append(systemTags, param.SystemTagBootModeLegacy)
-		default:
-			resp.Diagnostics.AddError(
-				"invalid boot mode",
-				fmt.Sprintf("invalid boot mode: %s", bootMode),
-			)
-			return
-		}
+	bootModeTag, err := imageBootModeSystemTag(imagePlan.BootMode, imagePlan.Architecture)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"invalid boot mode",
+			err.Error(),
+		)
+		return
 	}
+	systemTags = append(systemTags, bootModeTag)
 
-	if imagePlan.Description.IsNull() {
+	if imagePlan.Description.IsNull() || imagePlan.Description.IsUnknown() {
 		imagePlan.Description = types.StringValue("")
 	}
-	if imagePlan.GuestOsType.IsNull() {
+	if imagePlan.GuestOsType.IsNull() || imagePlan.GuestOsType.IsUnknown() {
 		imagePlan.GuestOsType = types.StringValue("Linux")
 	}
-	if imagePlan.Platform.IsNull() {
+	if imagePlan.Platform.IsNull() || imagePlan.Platform.IsUnknown() {
 		imagePlan.Platform = types.StringValue("Linux")
 	}
 
+	resourceUuid, err := newResourceUuid()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not Add image to ZSphere Image storage",
+			fmt.Sprintf("failed to generate image uuid, err: %v", err),
+		)
+		return
+	}
+
 	tflog.Info(ctx, "Configuring ZStack client")
 	imageParam := param.AddImageParam{
 		BaseParam: param.BaseParam{
@@ -142,28 +172,37 @@ func (r *imageResource) Create(ctx context.Context, req resource.CreateRequest,
 			Platform:           imagePlan.Platform.ValueString(),
 			BackupStorageUuids: backupStorageUuids,
 			//Type:               imagePlan.Type.ValueString(),
-			ResourceUuid: "",
+			ResourceUuid: resourceUuid,
 			Architecture: param.Architecture(imagePlan.Architecture.ValueString()),
 			Virtio:       imagePlan.Virtio.ValueBool(),
 		},
 	}
 
 	ctx = tflog.SetField(ctx, "url", imagePlan.Url)
-	image, err := r.client.AddImage(imageParam)
+	image, err := runJob(ctx, job{
+		name:         "add image",
+		resourceUuid: resourceUuid,
+		progress: func() (string, error) {
+			return imageProgress(r.client, resourceUuid)
+		},
+	}, func() (*view.ImageView, error) {
+		return r.client.AddImage(imageParam)
+	})
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Could not Add image to ZSphere Image storage"+image.Name, "Error "+err.Error(),
-		)
+		summary := "Could not Add image to ZSphere Image storage " + imagePlan.Name.ValueString()
+		if errors.Is(err, errJobNotFinished) {
+			keepPartiallyCreated(ctx, resp, resourceUuid, summary, "Error "+err.Error())
+			return
+		}
+		resp.Diagnostics.AddError(summary, "Error "+err.Error())
 		return
 	}
 
-	imagePlan.Uuid = types.StringValue(image.UUID)
-	imagePlan.Name = types.StringValue(image.Name)
-	imagePlan.Description = types.StringValue(image.Description)
-	imagePlan.Url = types.StringValue(image.Url)
-	imagePlan.GuestOsType = types.StringValue(image.GuestOsType)
-	imagePlan.System = types.StringValue(image.System)
-	imagePlan.Platform = types.StringValue(image.Platform)
+	diags = readImageState(ctx, r, image, &imagePlan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	ctx = tflog.SetField(ctx, "url", image.Url)
 	diags = resp.State.Set(ctx, imagePlan)
@@ -175,6 +214,10 @@ func (r *imageResource) Create(ctx context.Context, req resource.CreateRequest,
 
 // Delete implements resource.Resource.
 func (r *imageResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_image", "delete") {
+		return
+	}
+
 	var state imageResourceModel
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -192,9 +235,28 @@ func (r *imageResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
-	err := r.client.DeleteImage(state.Uuid.ValueString(), param.DeleteModeEnforcing)
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultImageDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	_, err := runJob(ctx, job{
+		name:         "delete image",
+		resourceUuid: state.Uuid.ValueString(),
+	}, func() (any, error) {
+		return nil, r.client.DeleteImage(state.Uuid.ValueString(), param.DeleteModeEnforcing)
+	})
 
 	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryImage, state.Uuid.ValueString())
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("image %s not found, nothing to delete", state.Uuid.ValueString()))
+			return
+		}
+
 		resp.Diagnostics.AddError("fail to delete image", ""+err.Error())
 		return
 	}
@@ -202,7 +264,12 @@ func (r *imageResource) Delete(ctx context.Context, req resource.DeleteRequest,
 	if expunge {
 		tflog.Info(ctx, fmt.Sprintf("expunge image %s", state.Uuid.ValueString()))
 
-		err = r.client.ExpungeImage(state.Uuid.ValueString())
+		_, err = runJob(ctx, job{
+			name:         "expunge image",
+			resourceUuid: state.Uuid.ValueString(),
+		}, func() (any, error) {
+			return nil, r.client.ExpungeImage(state.Uuid.ValueString())
+		})
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Failed to expunge image", "Error: "+err.Error(),
@@ -225,26 +292,36 @@ func (r *imageResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	if state.Uuid.ValueString() == "" {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
 	image, err := r.client.GetImage(state.Uuid.ValueString())
 	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryImage, state.Uuid.ValueString())
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("image %s not found, remove it from state", state.Uuid.ValueString()))
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
 		resp.Diagnostics.AddError(
 			"Error getting ZStack Image uuid", "Could not read image uuid"+err.Error(),
 		)
 		return
 	}
 
-	state.Uuid = types.StringValue(image.UUID)
-	state.Name = types.StringValue(image.Name)
-	state.Url = types.StringValue(image.Url)
-
-	if !state.Description.IsNull() {
-		state.Description = types.StringValue(image.Description)
-	}
-	if !state.GuestOsType.IsNull() {
-		state.GuestOsType = types.StringValue(image.GuestOsType)
+	if image.Status == imageStatusDeleted {
+		tflog.Warn(ctx, fmt.Sprintf("image %s has been deleted, remove it from state", image.UUID))
+		resp.State.RemoveResource(ctx)
+		return
 	}
-	if !state.Platform.IsNull() {
-		state.Platform = types.StringValue(image.Platform)
+
+	diags = readImageState(ctx, r, image, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
 	diags = resp.State.Set(ctx, &state)
@@ -254,8 +331,13 @@ func (r *imageResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	}
 }
 
+// ImportState implements resource.ResourceWithImportState.
+func (r *imageResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("uuid"), req, resp)
+}
+
 // Schema implements resource.Resource.
-func (r *imageResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *imageResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "This resource allows you to manage images in ZSphere. " +
 			"An image represents a virtual machine image format qcow2, raw, vmdk or an ISO file that can be used to create or boot virtual machines. " +
@@ -264,6 +346,9 @@ func (r *imageResource) Schema(_ context.Context, req resource.SchemaRequest, re
 			"uuid": schema.StringAttribute{
 				Computed:    true,
 				Description: "The unique identifier of the image. Automatically generated by ZSphere.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"name": schema.StringAttribute{
 				Required:    true,
@@ -272,14 +357,21 @@ func (r *imageResource) Schema(_ context.Context, req resource.SchemaRequest, re
 			"description": schema.StringAttribute{
 				Optional:    true,
 				Computed:    true,
-				Description: "A description of the image, providing additional context or details.",
+				Description: "A description of the image, providing additional context or details. Removing it clears the description.",
+				PlanModifiers: []planmodifier.String{
+					clearWhenRemoved(),
+				},
 			},
 			"url": schema.StringAttribute{
 				Required:    true,
-				Description: "The URL where the image is located. This can be a file path or an HTTP link.",
+				Description: "The URL where the image is located. This can be a file path or an HTTP link. Changing this forces a new image to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"media_type": schema.StringAttribute{
 				Optional:    true,
+				Computed:    true,
 				Description: "The type of media for the image. Examples include 'ISO' or 'RootVolumeTemplate' or DataVolumeTemplate.",
 				Validators: []validator.String{
 					stringvalidator.OneOf("ISO", "RootVolumeTemplate", "DataVolumeTemplate"),
@@ -293,6 +385,9 @@ func (r *imageResource) Schema(_ context.Context, req resource.SchemaRequest, re
 			"system": schema.StringAttribute{
 				Computed:    true,
 				Description: "Indicates if the image is a system image. Set automatically by ZStack.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"platform": schema.StringAttribute{
 				Optional:    true,
@@ -304,42 +399,285 @@ func (r *imageResource) Schema(_ context.Context, req resource.SchemaRequest, re
 			},
 			"format": schema.StringAttribute{
 				Required:    true,
-				Description: "The format of the image file, such as 'qcow2', 'raw', or 'vmdk'.",
+				Description: "The format of the image file, such as 'qcow2', 'raw', or 'vmdk'. Changing this forces a new image to be created.",
 				Validators: []validator.String{
 					stringvalidator.OneOf("qcow2", "iso", "raw", "vmdk"),
 				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"image_storage_uuids": schema.ListAttribute{
 				ElementType: types.StringType,
 				Optional:    true,
-				Description: "A list of UUIDs for the image storages where the image is stored.",
+				Computed:    true,
+				Description: "A list of UUIDs for the image storages where the image is stored. Changing this forces a new image to be created.",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+					listplanmodifier.RequiresReplace(),
+				},
 			},
 			"architecture": schema.StringAttribute{
 				Optional:    true,
-				Description: "The architecture of the image, such as 'x86_64' or 'aarch64'.",
+				Computed:    true,
+				Description: "The architecture of the image, such as 'x86_64' or 'aarch64'. Changing this forces a new image to be created.",
 				Validators: []validator.String{
 					stringvalidator.OneOf("x86_64", "aarch64", "mips64el", "loongarch64"),
 				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"virtio": schema.BoolAttribute{
 				Optional:    true,
+				Computed:    true,
 				Description: "Indicates if the VirtIO drivers are required for the image.",
 			},
 			"expunge": schema.BoolAttribute{
 				Optional:    true,
-				Description: "Indicates if the image should be expunged after deletion.",
+				Description: "Indicates if the image should be expunged after deletion. It is not stored on the platform, so it shows as an in-place change after import when set.",
 			},
 			"boot_mode": schema.StringAttribute{
 				Optional:    true,
+				Computed:    true,
 				Description: "The boot mode supported by the image, such as 'Legacy' or 'UEFI'.",
 				Validators: []validator.String{
 					stringvalidator.OneOf("Legacy", "UEFI"),
 				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
 func (r *imageResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_image", "update") {
+		return
+	}
+
+	var plan imageResourceModel
+	var state imageResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultImageUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	uuid := state.Uuid.ValueString()
+	plan.Uuid = state.Uuid
+
+	updateParam := param.UpdateImageParam{
+		UpdateImage: param.UpdateImageDetailParam{
+			Name: plan.Name.ValueString(),
+		},
+	}
+	changed := !plan.Name.Equal(state.Name)
+
+	if !plan.Description.IsUnknown() && !plan.Description.Equal(state.Description) {
+		updateParam.UpdateImage.Description = plan.Description.ValueStringPointer()
+		changed = true
+	}
+	if !plan.GuestOsType.IsUnknown() && !plan.GuestOsType.IsNull() && !plan.GuestOsType.Equal(state.GuestOsType) {
+		updateParam.UpdateImage.GuestOsType = plan.GuestOsType.ValueStringPointer()
+		changed = true
+	}
+	if !plan.Platform.IsUnknown() && !plan.Platform.IsNull() && !plan.Platform.Equal(state.Platform) {
+		updateParam.UpdateImage.Platform = plan.Platform.ValueStringPointer()
+		changed = true
+	}
+	if !plan.MediaType.IsUnknown() && !plan.MediaType.IsNull() && !plan.MediaType.Equal(state.MediaType) {
+		updateParam.UpdateImage.MediaType = plan.MediaType.ValueStringPointer()
+		changed = true
+	}
+	if !plan.Virtio.IsUnknown() && !plan.Virtio.IsNull() && !plan.Virtio.Equal(state.Virtio) {
+		virtio := plan.Virtio.ValueBool()
+		updateParam.UpdateImage.Virtio = &virtio
+		changed = true
+	}
+
+	if changed {
+		tflog.Info(ctx, fmt.Sprintf("update image %s", uuid))
+		_, err := runJob(ctx, job{
+			name:         "update image",
+			resourceUuid: uuid,
+		}, func() (*view.ImageView, error) {
+			return r.client.UpdateImage(uuid, updateParam)
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"fail to update image",
+				fmt.Sprintf("fail to update image %s, err: %v", uuid, err),
+			)
+			return
+		}
+	}
+
+	if !plan.BootMode.IsUnknown() && !plan.BootMode.IsNull() && !plan.BootMode.Equal(state.BootMode) {
+		bootModeTag, err := imageBootModeSystemTag(plan.BootMode, plan.Architecture)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"invalid boot mode",
+				err.Error(),
+			)
+			return
+		}
+
+		err = setImageBootModeSystemTag(r, uuid, bootModeTag)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"fail to update image boot mode",
+				fmt.Sprintf("fail to set boot mode of image %s, err: %v", uuid, err),
+			)
+			return
+		}
+	}
+
+	image, err := r.client.GetImage(uuid)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting ZStack Image uuid", "Could not read image uuid"+err.Error(),
+		)
+		return
+	}
+
+	diags = readImageState(ctx, r, image, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// imageBootModeSystemTag returns the boot mode system tag for an image.
+// If boot mode is not set, uefi is used in aarch64 and legacy in x86_64.
+func imageBootModeSystemTag(bootMode types.String, architecture types.String) (string, error) {
+	if bootMode.IsNull() || bootMode.ValueString() == "" {
+		if architecture.ValueString() == "aarch64" {
+			return param.SystemTagBootModeUEFI, nil
+		}
+		return param.SystemTagBootModeLegacy, nil
+	}
+
+	switch strings.ToLower(bootMode.ValueString()) {
+	case "uefi":
+		return param.SystemTagBootModeUEFI, nil
+	case "legacy":
+		return param.SystemTagBootModeLegacy, nil
+	default:
+		return "", fmt.Errorf("invalid boot mode: %s", bootMode.ValueString())
+	}
+}
+
+// setImageBootModeSystemTag rewrites the bootMode system tag of an image, or creates it if the image has none.
+func setImageBootModeSystemTag(r *imageResource, imageUuid string, bootModeTag string) error {
+	qparam := param.NewQueryParam()
+	qparam.AddQ("resourceUuid=" + imageUuid)
+	qparam.AddQ("tag~=" + imageBootModeSystemTagPrefix + "%")
+	tags, err := r.client.QuerySystemTags(qparam)
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		_, err = r.client.CreateSystemTag(param.CreateTagParam{
+			Params: param.CreateTagDetailParam{
+				ResourceType: "ImageVO",
+				ResourceUuid: imageUuid,
+				Tag:          bootModeTag,
+			},
+		})
+		return err
+	}
+
+	for _, tag := range tags {
+		if tag.Tag == bootModeTag {
+			continue
+		}
+		_, err = r.client.UpdateSystemTag(tag.UUID, param.UpdateSystemTagParam{
+			UpdateSystemTag: param.UpdateSystemTagDetailParam{
+				Tag: bootModeTag,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readImageState refreshes the model from the image inventory and its boot mode system tag.
+func readImageState(ctx context.Context, r *imageResource, image *view.ImageView, model *imageResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	imageTags, err := querySystemTags(r.client, []string{image.UUID}, "")
+	if err != nil {
+		diags.AddError(
+			"Could not read image system tags", "Error: "+err.Error(),
+		)
+		return diags
+	}
+
+	model.Uuid = types.StringValue(image.UUID)
+	model.Name = types.StringValue(image.Name)
+	model.Description = types.StringValue(image.Description)
+	model.Url = types.StringValue(image.Url)
+	model.MediaType = types.StringValue(image.MediaType)
+	model.GuestOsType = types.StringValue(image.GuestOsType)
+	model.System = types.StringValue(image.System)
+	model.Platform = types.StringValue(image.Platform)
+	model.Format = types.StringValue(image.Format)
+	model.Architecture = types.StringValue(string(image.Architecture))
+	model.Virtio = types.BoolValue(image.Virtio)
+
+	model.BootMode = types.StringNull()
+	for _, tag := range imageTags[image.UUID] {
+		if strings.HasPrefix(tag, imageBootModeSystemTagPrefix) {
+			model.BootMode = types.StringValue(strings.TrimPrefix(tag, imageBootModeSystemTagPrefix))
+		}
+	}
+
+	var backupStorageUuids []string
+	for _, ref := range image.BackupStorageRefs {
+		backupStorageUuids = append(backupStorageUuids, ref.BackupStorageUuid)
+	}
+
+	var d diag.Diagnostics
+	model.BackupStorageUuids, d = types.ListValueFrom(ctx, types.StringType, backupStorageUuids)
+	diags.Append(d...)
+
+	return diags
+}
+
+// imageProgress reports the status and downloaded size of an image while it is added.
+func imageProgress(cli *client.ZSClient, uuid string) (string, error) {
+	qparam := param.NewQueryParam()
+	qparam.AddQ("uuid=" + uuid)
+	images, err := cli.QueryImage(qparam)
+	if err != nil {
+		return "", err
+	}
+	if len(images) == 0 {
+		return "image not created yet", nil
+	}
+	return fmt.Sprintf("image is %s, %d GB downloaded", images[0].Status, utils.BytesToGB(images[0].ActualSize)), nil
 }
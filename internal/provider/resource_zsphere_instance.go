@@ -4,26 +4,60 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"terraform-provider-zsphere/internal/utils"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
 	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
 )
 
 type vmResource struct {
-	client *client.ZSClient
+	client   *client.ZSClient
+	readOnly bool
 }
 
 var (
-	_ resource.Resource              = &vmResource{}
-	_ resource.ResourceWithConfigure = &vmResource{}
+	_ resource.Resource                = &vmResource{}
+	_ resource.ResourceWithConfigure   = &vmResource{}
+	_ resource.ResourceWithImportState = &vmResource{}
+	_ resource.ResourceWithModifyPlan  = &vmResource{}
+)
+
+const (
+	vmStateRunning   = "Running"
+	vmStateDestroyed = "Destroyed"
+
+	defaultVmCreateTimeout = 30 * time.Minute
+	defaultVmUpdateTimeout = 30 * time.Minute
+	defaultVmDeleteTimeout = 20 * time.Minute
+
+	vmNeverStopSystemTag      = "ha::NeverStop"
+	vmUserDataSystemTagPrefix = "userdata::"
+	vmStaticIpSystemTagPrefix = "staticIp::"
+	volumeVirtioSCSISystemTag = "capability::virtio-scsi"
 )
 
 var networkModelAttrTypes = map[string]attr.Type{
@@ -33,8 +67,24 @@ var networkModelAttrTypes = map[string]attr.Type{
 	"gateway": types.StringType,
 }
 
+var networkInterfaceAttrTypes = map[string]attr.Type{
+	"port_group_uuid": types.StringType,
+	"default_l3":      types.BoolType,
+	"static_ip":       types.StringType,
+}
+
+var diskModelAttrTypes = map[string]attr.Type{
+	"uuid": types.StringType,
+	//"offering_uuid":        types.StringType,
+	"size":                 types.Int64Type,
+	"primary_storage_uuid": types.StringType,
+	"ceph_pool_name":       types.StringType,
+	"virtio_scsi":          types.BoolType,
+}
+
 type diskModel struct {
-	Size types.Int64 `tfsdk:"size"`
+	Uuid types.String `tfsdk:"uuid"`
+	Size types.Int64  `tfsdk:"size"`
 	//	OfferingUuid       types.String `tfsdk:"offering_uuid"`
 	VirtioSCSI         types.Bool   `tfsdk:"virtio_scsi"`
 	PrimaryStorageUuid types.String `tfsdk:"primary_storage_uuid"`
@@ -53,13 +103,19 @@ type vmInstanceDataSourceModel struct {
 	HostUuid          types.String `tfsdk:"host_uuid"`
 	Description       types.String `tfsdk:"description"`
 	//InstanceOfferingUuid types.String `tfsdk:"instance_offering_uuid"`
-	Strategy   types.String `tfsdk:"strategy"`
-	MemorySize types.Int64  `tfsdk:"memory_size"`
-	CPUNum     types.Int64  `tfsdk:"cpu_num"`
-	NeverStop  types.Bool   `tfsdk:"never_stop"`
-	UserData   types.String `tfsdk:"user_data"`
-	VMNics     types.List   `tfsdk:"vm_nics"`
-	Expunge    types.Bool   `tfsdk:"expunge"`
+	Strategy   types.String   `tfsdk:"strategy"`
+	MemorySize types.Int64    `tfsdk:"memory_size"`
+	CPUNum     types.Int64    `tfsdk:"cpu_num"`
+	NeverStop  types.Bool     `tfsdk:"never_stop"`
+	UserData   types.String   `tfsdk:"user_data"`
+	VMNics     types.List     `tfsdk:"vm_nics"`
+	Expunge    types.Bool     `tfsdk:"expunge"`
+	Timeouts   timeouts.Value `tfsdk:"timeouts"`
+
+	PowerState    types.String `tfsdk:"power_state"`
+	StopMode      types.String `tfsdk:"stop_mode"`
+	StopTimeout   types.Int64  `tfsdk:"stop_timeout"`
+	RebootTrigger types.String `tfsdk:"reboot_trigger"`
 }
 
 type NicsModel struct {
@@ -85,17 +141,18 @@ func (r *vmResource) Configure(_ context.Context, req resource.ConfigureRequest,
 		return
 	}
 
-	client, ok := req.ProviderData.(*client.ZSClient)
+	providerData, ok := req.ProviderData.(*resourceProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *client.ZSClient, got: %T. Please report this issue to the Provider developer.", req.ProviderData),
+			fmt.Sprintf("Expected *resourceProviderData, got: %T. Please report this issue to the Provider developer.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = providerData.client
+	r.readOnly = providerData.readOnly
 }
 
 // Metadata implements resource.Resource.
@@ -113,14 +170,21 @@ func (r *vmResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp
 			"uuid": schema.StringAttribute{
 				Computed:    true,
 				Description: "The unique identifier of the VM instance.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"name": schema.StringAttribute{
 				Required:    true,
 				Description: "The name of the VM instance.",
 			},
 			"network_interfaces": schema.ListNestedAttribute{
-				Optional:    true,
-				Description: "Defines network interfaces attached to the VM. Each NIC corresponds to an L3 network, and optionally configures a static IP.",
+				Optional: true,
+				Description: "Defines network interfaces attached to the VM. Each NIC corresponds to an L3 network, and optionally configures a static IP. " +
+					"Adding a port group attaches a new NIC and removing one detaches its NIC, without recreating the VM.",
+				PlanModifiers: []planmodifier.List{
+					networkInterfacesPlanModifier{},
+				},
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"port_group_uuid": schema.StringAttribute{
@@ -129,12 +193,13 @@ func (r *vmResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp
 						},
 						"default_l3": schema.BoolAttribute{
 							Required:    true,
-							Description: "Whether this NIC is the default route NIC.",
+							Description: "Whether this NIC is the default route NIC. Changing it updates the default network of the VM.",
 						},
 						"static_ip": schema.StringAttribute{
-							Optional:    true,
-							Computed:    true,
-							Description: "Static IP address to assign. The format will be converted to system tag `staticIp::<l3_uuid>::<ip>`.",
+							Optional: true,
+							Computed: true,
+							Description: "Static IP address to assign. The format will be converted to system tag `staticIp::<l3_uuid>::<ip>`. " +
+								"Changing it updates the IP of the NIC, the guest may need to renew its lease or be rebooted to pick it up.",
 						},
 					},
 				},
@@ -162,6 +227,9 @@ func (r *vmResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp
 				},
 				Computed:    true,
 				Description: "The IP address assigned to the VM instance.",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
 			},
 			/*
 				"instance_offering_uuid": schema.StringAttribute{
@@ -171,10 +239,20 @@ func (r *vmResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp
 				},*/
 			"image_uuid": schema.StringAttribute{
 				Required:    true,
-				Description: "The UUID of the image used to create the VM instance.",
+				Description: "The UUID of the image used to create the VM instance. Changing it replaces the VM instance.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"root_disk": schema.SingleNestedAttribute{
 				Attributes: map[string]schema.Attribute{
+					"uuid": schema.StringAttribute{
+						Computed:    true,
+						Description: "The UUID of the root volume.",
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.UseStateForUnknown(),
+						},
+					},
 					/*
 						"offering_uuid": schema.StringAttribute{
 							Optional:    true,
@@ -183,27 +261,49 @@ func (r *vmResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp
 					*/
 					"size": schema.Int64Attribute{
 						Optional:    true,
-						Description: "The size of the root disk in gigabytes (GB).",
+						Computed:    true,
+						Description: "The size of the root disk in gigabytes (GB). Growing it resizes the root volume in place, shrinking is rejected.",
+						PlanModifiers: []planmodifier.Int64{
+							int64planmodifier.UseStateForUnknown(),
+							disallowShrink(),
+						},
 					},
 					"primary_storage_uuid": schema.StringAttribute{
-						Optional:    true,
-						Description: "The UUID of the primary storage for the root disk.",
+						Optional: true,
+						Computed: true,
+						Description: "The UUID of the primary storage for the root disk. Changing it migrates the root volume to the new primary storage, " +
+							"live when the VM is running and cold when it is stopped.",
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.UseStateForUnknown(),
+						},
 					},
 					"ceph_pool_name": schema.StringAttribute{
 						Optional:    true,
+						Computed:    true,
 						Description: "The Ceph pool name for the root disk.",
 					},
 					"virtio_scsi": schema.BoolAttribute{
 						Optional:    true,
+						Computed:    true,
 						Description: "Whether the root disk uses Virtio-SCSI.",
 					},
 				},
 				Optional:    true,
+				Computed:    true,
 				Description: "The configuration for the root disk of the VM instance.",
+				PlanModifiers: []planmodifier.Object{
+					objectplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"data_disks": schema.ListNestedAttribute{
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
+						"uuid": schema.StringAttribute{
+							Optional: true,
+							Computed: true,
+							Description: "The UUID of the data volume. Set it to the UUID of a data volume already in `data_disks` to select that volume, " +
+								"e.g. to choose which of several data disks with the same settings is removed.",
+						},
 						/*
 							"offering_uuid": schema.StringAttribute{
 								Optional:    true,
@@ -211,76 +311,157 @@ func (r *vmResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp
 							},*/
 						"size": schema.Int64Attribute{
 							Optional:    true,
-							Description: "The size of the data disk in gigabytes (GB).",
+							Description: "The size of the data disk in gigabytes (GB). Growing it resizes the data volume in place, shrinking is not supported.",
 						},
 
 						"primary_storage_uuid": schema.StringAttribute{
+							Optional:    true,
 							Computed:    true,
-							Description: "The UUID of the primary storage for the data disk.",
+							Description: "The UUID of the primary storage for the data disk. Changing it migrates the data volume to the new primary storage.",
 						},
 
 						"ceph_pool_name": schema.StringAttribute{
 							Optional:    true,
-							Description: "The Ceph pool name for the data disk.",
+							Computed:    true,
+							Description: "The Ceph pool name for the data disk. It cannot be changed once the data volume exists.",
 						},
 						"virtio_scsi": schema.BoolAttribute{
 							Optional:    true,
-							Description: "Whether the data disk uses Virtio-SCSI.",
+							Computed:    true,
+							Description: "Whether the data disk uses Virtio-SCSI. It cannot be changed once the data volume exists.",
 						},
 					},
 				},
-				Optional:    true,
-				Description: "The configuration for additional data disks.",
+				Optional: true,
+				Description: "The configuration for additional data disks. Adding a disk creates and attaches a new data volume, removing one detaches and deletes it. " +
+					"Disks are matched to the existing data volumes by their `uuid` if set, then by their settings, never by their position. " +
+					"Plans that cannot tell which data volume is removed, or that change `ceph_pool_name` or `virtio_scsi` of a data disk, are rejected. " +
+					"On import every data volume attached to the VM is listed here. Data volumes taken over on import, or from state written by " +
+					"provider versions that did not record the volumes they created, are never deleted: removing them only stops managing them.",
+				PlanModifiers: []planmodifier.List{
+					dataDisksPlanModifier{},
+				},
 			},
 			"datacenter_uuid": schema.StringAttribute{
 				Optional:    true,
+				Computed:    true,
 				Description: "The UUID of the zone where the VM instance is deployed.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"cluster_uuid": schema.StringAttribute{
 				Optional:    true,
+				Computed:    true,
 				Description: "The UUID of the cluster where the VM instance is deployed.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"host_uuid": schema.StringAttribute{
 				Optional:    true,
+				Computed:    true,
 				Description: "The UUID of the host where the VM instance is running.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"description": schema.StringAttribute{
 				Optional:    true,
 				Computed:    true,
-				Description: "A description of the VM instance.",
+				Description: "A description of the VM instance. Removing it clears the description.",
+				PlanModifiers: []planmodifier.String{
+					clearWhenRemoved(),
+				},
 			},
 			"memory_size": schema.Int64Attribute{
-				Optional:    true,
-				Computed:    true,
-				Description: "The memory size allocated to the VM instance in megabytes (MB). When used together with `cpu_num`, the `instance_offering_uuid` is not required.",
+				Optional: true,
+				Computed: true,
+				Description: "The memory size allocated to the VM instance in megabytes (MB). When used together with `cpu_num`, the `instance_offering_uuid` is not required. " +
+					"Changing it on a running VM is tried online first, and the VM is stopped and started again when the change cannot be made online.",
 			},
 			"cpu_num": schema.Int64Attribute{
+				Optional: true,
+				Computed: true,
+				Description: "The number of CPUs allocated to the VM instance.  When used together with `memory_size`, the `instance_offering_uuid` is not required. " +
+					"Changing it on a running VM is tried online first, and the VM is stopped and started again when the change cannot be made online.",
+			},
+			"strategy": schema.StringAttribute{
 				Optional:    true,
 				Computed:    true,
-				Description: "The number of CPUs allocated to the VM instance.  When used together with `memory_size`, the `instance_offering_uuid` is not required.",
+				Description: "The deployment strategy for the VM instance. Only used at creation time, imported instances report `InstantStart`.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"strategy": schema.StringAttribute{
+			"power_state": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Description: "The desired power state of the VM instance, `Running`, `Stopped` or `Paused`. It is reconciled on every apply, " +
+					"so a VM stopped outside of Terraform is started again. When not set, the current power state is only reported.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(vmStateRunning, vmStateStopped, vmStatePaused),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"stop_mode": schema.StringAttribute{
+				Optional: true,
+				Description: "How the VM instance is stopped when `power_state` is set to `Stopped`. `graceful` (default) asks the guest to shut down " +
+					"and forces the VM off when it has not stopped within `stop_timeout`, `forced` powers the VM off at once.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(vmStopModeGraceful, vmStopModeForced),
+				},
+			},
+			"stop_timeout": schema.Int64Attribute{
 				Optional:    true,
-				Description: "The deployment strategy for the VM instance.",
+				Description: "The number of seconds to wait for a graceful stop before the VM instance is forced off. Defaults to 300.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"reboot_trigger": schema.StringAttribute{
+				Optional:    true,
+				Description: "An arbitrary value. Changing it reboots the VM instance if it is running, which allows VMs to be rolled deliberately.",
 			},
 			"user_data": schema.StringAttribute{
 				Optional:    true,
-				Description: "User data injected into the VM instance at boot time.",
+				Description: "User data injected into the VM instance at boot time. Changing it replaces the VM instance.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"never_stop": schema.BoolAttribute{
 				Optional:    true,
-				Description: "Whether the VM instance should never stop automatically.",
+				Computed:    true,
+				Description: "Whether the VM instance should never stop automatically. Changing it replaces the VM instance.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+					boolplanmodifier.RequiresReplace(),
+				},
 			},
 			"expunge": schema.BoolAttribute{
 				Optional:    true,
-				Description: "Indicates if the instance should be expunged after deletion.",
+				Description: "Indicates if the instance should be expunged after deletion. It is not stored on the platform, so it shows as an in-place change after import when set.",
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
 // Create implements resource.Resource.
 func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_instance", "create") {
+		return
+	}
+
 	var plan vmInstanceDataSourceModel
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
@@ -288,6 +469,14 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 		return
 	}
 
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultVmCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
 	var rootDiskPlan diskModel
 	var dataDisksPlan []diskModel
 
@@ -296,20 +485,16 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 	clusterUuid := ""
 	zoneUuid := ""
 	var rootDiskSystemTags []string
-	var dataDiskSizes []int64
-	var dataDiskOfferingUuids []string
-	var dataVolumeSystemTagsOnIndex []string
-	var dataDiskSystemTags []string
 
 	// SET ROOT DISK
-	if !plan.RootDisk.IsNull() {
+	if !plan.RootDisk.IsNull() && !plan.RootDisk.IsUnknown() {
 		diags = plan.RootDisk.As(ctx, &rootDiskPlan, basetypes.ObjectAsOptions{})
 		resp.Diagnostics.Append(diags...)
 		if resp.Diagnostics.HasError() {
 			return
 		}
 
-		err := isDiskParamValid(r, rootDiskPlan)
+		err := isDiskParamValid(r.client, rootDiskPlan)
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Params Error",
@@ -325,33 +510,32 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 			rootDiskSystemTags = append(rootDiskSystemTags, fmt.Sprintf("ceph::rootPoolName::%s", rootDiskPlan.CephPoolName.ValueString()))
 		}
 
-		if !rootDiskPlan.Size.IsNull() {
+		if rootDiskPlan.VirtioSCSI.ValueBool() {
+			rootDiskSystemTags = append(rootDiskSystemTags, volumeVirtioSCSISystemTag)
+		}
+
+		if !rootDiskPlan.Size.IsNull() && !rootDiskPlan.Size.IsUnknown() {
 			rootDiskPlan.Size = types.Int64Value(utils.GBToBytes(rootDiskPlan.Size.ValueInt64()))
+		} else {
+			rootDiskPlan.Size = types.Int64Null()
 		}
 	}
 
 	// SET DATA DISK
+	// data disks are created and attached one by one once the vm exists, so each disk keeps its own settings
 	if !plan.DataDisks.IsNull() {
 		plan.DataDisks.ElementsAs(ctx, &dataDisksPlan, false)
 
 		for _, disk := range dataDisksPlan {
-			if !disk.Size.IsNull() {
-				dataDiskSizes = append(dataDiskSizes, utils.GBToBytes(disk.Size.ValueInt64()))
-				if disk.VirtioSCSI.ValueBool() {
-					dataVolumeSystemTagsOnIndex = append(dataVolumeSystemTagsOnIndex, "capability::virtio-scsi")
-				}
-			} else {
+			if disk.Size.IsNull() {
 				resp.Diagnostics.AddError(
 					"Params Error",
 					"dataDisk offering_uuid and size cannot be null at the same time",
 				)
 				return
 			}
-		}
 
-		//only support one type data disk now
-		if len(dataDisksPlan) > 0 {
-			err := isDiskParamValid(r, dataDisksPlan[0])
+			err := isDiskParamValid(r.client, disk)
 			if err != nil {
 				resp.Diagnostics.AddError(
 					"Params Error",
@@ -359,10 +543,6 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 				)
 				return
 			}
-			if !dataDisksPlan[0].CephPoolName.IsNull() && dataDisksPlan[0].CephPoolName.ValueString() != "" {
-				dataDiskSystemTags = append(dataDiskSystemTags, fmt.Sprintf("ceph::pool::%s", dataDisksPlan[0].CephPoolName.ValueString()))
-			}
-			dataDiskSystemTags = append(dataDiskSystemTags, dataVolumeSystemTagsOnIndex...)
 		}
 	}
 
@@ -386,7 +566,6 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 	var defaultL3Uuid string
 	var systemTags []string
 
-	var createNics []NetworkInterfaceModel
 	for _, nic := range inputNics {
 		l3uuid := nic.L3NetworkUuid.ValueString()
 		l3NetworkUuids = append(l3NetworkUuids, l3uuid)
@@ -395,19 +574,9 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 			defaultL3Uuid = l3uuid
 		}
 
-		var staticIp types.String
-		if nic.StaticIp.IsNull() || nic.StaticIp.ValueString() == "" {
-			staticIp = types.StringNull()
-		} else {
-			staticIp = nic.StaticIp
-			systemTags = append(systemTags, fmt.Sprintf("staticIp::%s::%s", l3uuid, staticIp.ValueString()))
+		if !nic.StaticIp.IsNull() && nic.StaticIp.ValueString() != "" {
+			systemTags = append(systemTags, fmt.Sprintf("%s%s::%s", vmStaticIpSystemTagPrefix, l3uuid, nic.StaticIp.ValueString()))
 		}
-
-		createNics = append(createNics, NetworkInterfaceModel{
-			L3NetworkUuid: nic.L3NetworkUuid,
-			DefaultL3:     nic.DefaultL3,
-			StaticIp:      staticIp,
-		})
 	}
 
 	// SET IMAGE
@@ -455,15 +624,19 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 	//systemTags := []string{"resourceConfig::vm::vm.clock.track::guest", "cdroms::Empty::None::None"}
 
 	if !plan.NeverStop.IsNull() && plan.NeverStop.ValueBool() {
-		systemTags = append(systemTags, "ha::NeverStop")
+		systemTags = append(systemTags, vmNeverStopSystemTag)
 	}
 
 	if !plan.UserData.IsNull() && plan.UserData.ValueString() != "" {
-		systemTags = append(systemTags, fmt.Sprintf("userdata::%s", plan.UserData.ValueString()))
+		systemTags = append(systemTags, vmUserDataSystemTagPrefix+plan.UserData.ValueString())
 	}
 
 	//SET OTHER PARAM
-	if !plan.Strategy.IsNull() {
+	if (plan.Strategy.IsNull() || plan.Strategy.IsUnknown()) && plan.PowerState.ValueString() == vmStateStopped {
+		plan.Strategy = types.StringValue(string(param.CreateStopped))
+	} else if plan.Strategy.IsNull() || plan.Strategy.IsUnknown() {
+		plan.Strategy = types.StringValue(string(param.InstantStart))
+	} else {
 		strategyValue := plan.Strategy.ValueString()
 		if strategyValue != string(param.InstantStart) && strategyValue != string(param.CreateStopped) {
 			resp.Diagnostics.AddError(
@@ -496,8 +669,6 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 			//RootDiskOfferingUuid:            rootDiskPlan.OfferingUuid.ValueString(),
 			RootDiskSize:                    rootDiskPlan.Size.ValueInt64Pointer(),
 			PrimaryStorageUuidForRootVolume: primaryStorageUuidForRootVolume,
-			DataDiskSizes:                   dataDiskSizes,
-			DataDiskOfferingUuids:           dataDiskOfferingUuids,
 			ZoneUuid:                        zoneUuid,
 			ClusterUUID:                     clusterUuid,
 			HostUuid:                        hostUuid,
@@ -508,204 +679,468 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 			MemorySize:                      memorySize,
 			CpuNum:                          cpuNum,
 			RootVolumeSystemTags:            rootDiskSystemTags,
-			DataVolumeSystemTags:            dataDiskSystemTags,
 		},
 	}
 
-	instance, err := r.client.CreateVmInstance(createVmInstanceParam)
+	resourceUuid, err := newResourceUuid()
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Create VmInstance Error",
-			fmt.Sprintf("failed to create vminstance, err: %v", err),
+			fmt.Sprintf("failed to generate vminstance uuid, err: %v", err),
 		)
 		return
 	}
+	createVmInstanceParam.Params.ResourceUuid = resourceUuid
 
-	plan.Uuid = types.StringValue(instance.UUID)
-	plan.Name = types.StringValue(instance.Name)
-	plan.Description = types.StringValue(instance.Description)
-	plan.MemorySize = types.Int64Value(utils.BytesToMB(instance.MemorySize))
-	plan.CPUNum = types.Int64Value(int64(instance.CPUNum))
-
-	var updatedNics []NetworkInterfaceModel
-	for _, nic := range createNics {
-		var realIP string
-		for _, vmNic := range instance.VMNics {
-			if vmNic.L3NetworkUUID == nic.L3NetworkUuid.ValueString() {
-				realIP = vmNic.IP
-				break
-			}
+	instance, err := runJob(ctx, job{
+		name:         "create vm instance",
+		resourceUuid: resourceUuid,
+		progress: func() (string, error) {
+			return vmInstanceProgress(r.client, resourceUuid)
+		},
+	}, func() (*view.VmInstanceInventoryView, error) {
+		return r.client.CreateVmInstance(createVmInstanceParam)
+	})
+	if err != nil {
+		detail := fmt.Sprintf("failed to create vminstance, err: %v", err)
+		if errors.Is(err, errJobNotFinished) {
+			keepPartiallyCreated(ctx, resp, resourceUuid, "Create VmInstance Error", detail)
+			return
 		}
+		resp.Diagnostics.AddError("Create VmInstance Error", detail)
+		return
+	}
 
-		staticIp := nic.StaticIp
-		if staticIp.IsNull() || staticIp.ValueString() == "" {
-			staticIp = types.StringValue(realIP)
+	plan.Uuid = types.StringValue(instance.UUID)
+
+	owned := make(map[string]bool)
+	for i := range dataDisksPlan {
+		volume, err := createDataDiskVolume(ctx, r.client, instance, dataDisksPlan[i])
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Could not create data disk",
+				fmt.Sprintf("failed to create data disk %d of vminstance %s, err: %v", i, instance.UUID, err),
+			)
+			break
 		}
+		dataDisksPlan[i].Uuid = types.StringValue(volume.UUID)
+		owned[volume.UUID] = true
+	}
+	resp.Diagnostics.Append(setOwnedDataVolumes(ctx, resp.Private, owned)...)
 
-		updatedNics = append(updatedNics, NetworkInterfaceModel{
-			L3NetworkUuid: nic.L3NetworkUuid,
-			DefaultL3:     nic.DefaultL3,
-			StaticIp:      staticIp,
-		})
+	if len(dataDisksPlan) > 0 {
+		plan.DataDisks, diags = types.ListValueFrom(ctx, types.ObjectType{AttrTypes: diskModelAttrTypes}, dataDisksPlan)
+		resp.Diagnostics.Append(diags...)
+	}
+
+	// SET POWER STATE
+	powerStateChanged := false
+	if !resp.Diagnostics.HasError() && !plan.PowerState.IsNull() && !plan.PowerState.IsUnknown() {
+		powerStateChanged, err = setVmPowerState(ctx, r.client, instance.UUID, plan.PowerState.ValueString(), plan.StopMode.ValueString(), vmStopTimeout(plan))
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Create VmInstance Error",
+				err.Error(),
+			)
+		}
 	}
 
-	networkInterfaceAttrTypes := map[string]attr.Type{
-		"port_group_uuid": types.StringType,
-		"default_l3":      types.BoolType,
-		"static_ip":       types.StringType,
+	if len(dataDisksPlan) > 0 || powerStateChanged {
+		vm, err := r.client.GetVmInstance(instance.UUID)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Could not read vm instance", "Error: "+err.Error(),
+			)
+		} else {
+			instance = vm
+		}
 	}
 
-	networkInterfacesList, diags := types.ListValueFrom(ctx,
-		types.ObjectType{AttrTypes: networkInterfaceAttrTypes},
-		updatedNics)
+	// like keepPartiallyCreated, the vm is saved even if one of its data disks failed
+	diags = readVmInstanceState(ctx, r, instance, &plan)
 	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
+	if diags.HasError() {
 		return
 	}
 
-	plan.NetworkInterfaces = networkInterfacesList
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
 
-	var diskModelAttrTypes = map[string]attr.Type{
-		//"offering_uuid":        types.StringType,
-		"size":                 types.Int64Type,
-		"primary_storage_uuid": types.StringType,
-		"ceph_pool_name":       types.StringType,
-		"virtio_scsi":          types.BoolType,
-	}
+// Read implements resource.Resource.
+func (r *vmResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state vmInstanceDataSourceModel
 
-	if !plan.DataDisks.IsNull() {
-		var dataDisksPlan []diskModel
-		plan.DataDisks.ElementsAs(ctx, &dataDisksPlan, false)
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-		for i, disk := range instance.AllVolumes {
-			if i < len(dataDisksPlan) {
-				dataDisksPlan[i].PrimaryStorageUuid = types.StringValue(disk.PrimaryStorageUUID)
-			}
-		}
+	// state written by older versions used an empty uuid for vms deleted out of band
+	if state.Uuid.ValueString() == "" {
+		resp.State.RemoveResource(ctx)
+		return
+	}
 
-		dataDisksList, diags := types.ListValueFrom(ctx, types.ObjectType{
-			AttrTypes: diskModelAttrTypes,
-		}, dataDisksPlan)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
+	vm, err := r.client.GetVmInstance(state.Uuid.ValueString())
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryVmInstance, state.Uuid.ValueString())
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("vm instance %s not found, remove it from state", state.Uuid.ValueString()))
+			resp.State.RemoveResource(ctx)
 			return
 		}
-		plan.DataDisks = dataDisksList
 
+		resp.Diagnostics.AddError(
+			"Could not read vm instance", "Error: "+err.Error(),
+		)
+		return
 	}
 
-	var vmNics []NicsModel
-	for _, nic := range instance.VMNics {
-		vmNics = append(vmNics, NicsModel{
-			Uuid:    types.StringValue(nic.UUID),
-			Ip:      types.StringValue(nic.IP),
-			Netmask: types.StringValue(nic.Netmask),
-			Gateway: types.StringValue(nic.Gateway),
-		})
+	if vm.State == vmStateDestroyed {
+		tflog.Warn(ctx, fmt.Sprintf("vm instance %s has been destroyed, remove it from state", vm.UUID))
+		resp.State.RemoveResource(ctx)
+		return
 	}
 
-	plan.VMNics, _ = types.ListValueFrom(ctx, types.ObjectType{AttrTypes: networkModelAttrTypes}, vmNics)
+	diags = readVmInstanceState(ctx, r, vm, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	diags = resp.State.Set(ctx, &plan)
+	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+}
+
+// ImportState implements resource.ResourceWithImportState.
+func (r *vmResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("uuid"), req, resp)
+}
+
+// ModifyPlan implements resource.ResourceWithModifyPlan.
+func (r *vmResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to do on create or destroy
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
 
+	var plan, state vmInstanceDataSourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	// vm_nics keeps its state value unless NICs are attached, detached or get another ip
+	if !plan.NetworkInterfaces.Equal(state.NetworkInterfaces) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("vm_nics"), types.ListUnknown(types.ObjectType{AttrTypes: networkModelAttrTypes}))...)
+	}
+
+	// without power_state in the config the power state is only reported, and it may have changed by the end of the apply
+	var configPowerState types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("power_state"), &configPowerState)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if configPowerState.IsNull() && !req.Plan.Raw.Equal(req.State.Raw) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("power_state"), types.StringUnknown())...)
+	}
+	powerStateChanged := !configPowerState.IsNull() && !plan.PowerState.Equal(state.PowerState)
+
+	// starting the vm, or restarting it to resize it, can place it on another host
+	if powerStateChanged || !plan.CPUNum.Equal(state.CPUNum) || !plan.MemorySize.Equal(state.MemorySize) {
+		var configHostUuid types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("host_uuid"), &configHostUuid)...)
+		if configHostUuid.IsNull() {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("host_uuid"), types.StringUnknown())...)
+		}
+	}
 }
 
-// Read implements resource.Resource.
-func (r *vmResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+func (r *vmResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_instance", "update") {
+		return
+	}
+
+	var plan vmInstanceDataSourceModel
 	var state vmInstanceDataSourceModel
 
-	diags := req.State.Get(ctx, &state)
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	vm, err := r.client.GetVmInstance(state.Uuid.ValueString())
-	if err != nil {
-		tflog.Warn(ctx, "cannot read vm, maybe it has been deleted, set uuid to 'empty'. vm was no longer managed by terraform. error: "+err.Error())
-		state.Uuid = types.StringValue("")
-		diags = resp.State.Set(ctx, &state)
-		resp.Diagnostics.Append(diags...)
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultVmUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	uuid := state.Uuid.ValueString()
+	plan.Uuid = state.Uuid
+
+	// UPDATE NAME AND DESCRIPTION
+	nameChanged := !plan.Name.Equal(state.Name)
+	descriptionChanged := !plan.Description.IsUnknown() && !plan.Description.Equal(state.Description)
+	if nameChanged || descriptionChanged {
+		updateParam := param.UpdateVmInstanceParam{
+			UpdateVmInstance: param.UpdateVmInstanceDetailParam{
+				Name: plan.Name.ValueString(),
+			},
+		}
+		if descriptionChanged {
+			updateParam.UpdateVmInstance.Description = plan.Description.ValueStringPointer()
+		}
 
-	state.Uuid = types.StringValue(vm.UUID)
-	state.Name = types.StringValue(vm.Name)
-	state.Description = types.StringValue(vm.Description)
-	state.ImageUuid = types.StringValue(vm.ImageUUID)
-	state.MemorySize = types.Int64Value(utils.BytesToMB(vm.MemorySize))
-	state.CPUNum = types.Int64Value(int64(vm.CPUNum))
-
-	var vmNics []NicsModel
-	for _, nic := range vm.VMNics {
-		vmNics = append(vmNics, NicsModel{
-			Uuid:    types.StringValue(nic.UUID),
-			Ip:      types.StringValue(nic.IP),
-			Netmask: types.StringValue(nic.Netmask),
-			Gateway: types.StringValue(nic.Gateway),
+		tflog.Info(ctx, fmt.Sprintf("update vm instance %s name and description", uuid))
+		_, err := runJob(ctx, job{
+			name:         "update vm instance",
+			resourceUuid: uuid,
+		}, func() (any, error) {
+			return r.client.UpdateVmInstance(uuid, updateParam)
 		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Update VmInstance Error",
+				fmt.Sprintf("failed to update vm instance %s, err: %v", uuid, err),
+			)
+			return
+		}
 	}
 
-	state.VMNics, _ = types.ListValueFrom(ctx, types.ObjectType{AttrTypes: networkModelAttrTypes}, vmNics)
-	resp.Diagnostics.Append(diags...)
+	// UPDATE CPU AND MEMORY
+	var cpuNum *int
+	var memorySize *int64
+	if !plan.CPUNum.IsUnknown() && !plan.CPUNum.IsNull() && !plan.CPUNum.Equal(state.CPUNum) {
+		cpuNum = utils.TfInt64ToIntPointer(plan.CPUNum)
+	}
+	if !plan.MemorySize.IsUnknown() && !plan.MemorySize.IsNull() && !plan.MemorySize.Equal(state.MemorySize) {
+		memory := utils.MBToBytes(plan.MemorySize.ValueInt64())
+		memorySize = &memory
+	}
+	if cpuNum != nil || memorySize != nil {
+		err := resizeVmInstance(ctx, r, uuid, cpuNum, memorySize)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Update VmInstance Error",
+				fmt.Sprintf("failed to change cpu or memory of vm instance %s, err: %v", uuid, err),
+			)
+			return
+		}
+	}
 
-	var networkInterfaces []NetworkInterfaceModel
-	originalNetworkInterfaces := make(map[string]string)
-	if !state.NetworkInterfaces.IsNull() && len(state.NetworkInterfaces.Elements()) > 0 {
-		var oldNics []NetworkInterfaceModel
-		diags := state.NetworkInterfaces.ElementsAs(ctx, &oldNics, false)
-		resp.Diagnostics.Append(diags...)
+	// UPDATE POWER STATE
+	// the actual power state is checked on every apply, not only when the planned value changed.
+	// Without power_state in the config the plan carries the last reported state, which is not managed.
+	var configPowerState types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("power_state"), &configPowerState)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	powerStateChanged := false
+	if !configPowerState.IsNull() && !plan.PowerState.IsUnknown() {
+		var err error
+		powerStateChanged, err = setVmPowerState(ctx, r.client, uuid, plan.PowerState.ValueString(), plan.StopMode.ValueString(), vmStopTimeout(plan))
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Update VmInstance Error",
+				err.Error(),
+			)
+			return
+		}
+	}
+
+	// REBOOT
+	if !plan.RebootTrigger.Equal(state.RebootTrigger) && !powerStateChanged {
+		vm, err := r.client.GetVmInstance(uuid)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Could not read vm instance", "Error: "+err.Error(),
+			)
+			return
+		}
+
+		if vm.State == vmStateRunning {
+			tflog.Info(ctx, fmt.Sprintf("reboot vm instance %s", uuid))
+			_, err = runJob(ctx, job{
+				name:         "reboot vm instance",
+				resourceUuid: uuid,
+				progress: func() (string, error) {
+					return vmInstanceProgress(r.client, uuid)
+				},
+			}, func() (any, error) {
+				return r.client.RebootVmInstance(uuid)
+			})
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Update VmInstance Error",
+					fmt.Sprintf("failed to reboot vm instance %s, err: %v", uuid, err),
+				)
+				return
+			}
+		}
+	}
+
+	// UPDATE NETWORK INTERFACES
+	if !plan.NetworkInterfaces.IsUnknown() && !plan.NetworkInterfaces.Equal(state.NetworkInterfaces) {
+		var planNics, stateNics []NetworkInterfaceModel
+		if !plan.NetworkInterfaces.IsNull() {
+			resp.Diagnostics.Append(plan.NetworkInterfaces.ElementsAs(ctx, &planNics, false)...)
+		}
+		if !state.NetworkInterfaces.IsNull() {
+			resp.Diagnostics.Append(state.NetworkInterfaces.ElementsAs(ctx, &stateNics, false)...)
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if len(planNics) == 0 {
+			resp.Diagnostics.AddError(
+				"Parameter Error",
+				"`network_interfaces` cannot be null or empty. At least one L3 network must be specified.",
+			)
+			return
+		}
+
+		vm, err := r.client.GetVmInstance(uuid)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Could not read vm instance", "Error: "+err.Error(),
+			)
+			return
+		}
+
+		err = updateNetworkInterfaces(ctx, r.client, vm, planNics, stateNics)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Update VmInstance Error",
+				fmt.Sprintf("failed to update network interfaces of vm instance %s, err: %v", uuid, err),
+			)
+			return
+		}
+	}
+
+	// UPDATE ROOT DISK
+	if !plan.RootDisk.IsUnknown() && !plan.RootDisk.IsNull() && !state.RootDisk.IsNull() && !plan.RootDisk.Equal(state.RootDisk) {
+		var planRootDisk, stateRootDisk diskModel
+		resp.Diagnostics.Append(plan.RootDisk.As(ctx, &planRootDisk, basetypes.ObjectAsOptions{})...)
+		resp.Diagnostics.Append(state.RootDisk.As(ctx, &stateRootDisk, basetypes.ObjectAsOptions{})...)
 		if resp.Diagnostics.HasError() {
 			return
 		}
-		for _, oldNic := range oldNics {
-			if !oldNic.StaticIp.IsNull() && oldNic.StaticIp.ValueString() != "" {
-				originalNetworkInterfaces[oldNic.L3NetworkUuid.ValueString()] = oldNic.StaticIp.ValueString()
+
+		rootVolumeUuid := stateRootDisk.Uuid.ValueString()
+		if !planRootDisk.Size.IsUnknown() && planRootDisk.Size.ValueInt64() > stateRootDisk.Size.ValueInt64() {
+			tflog.Info(ctx, fmt.Sprintf("resize root volume %s of vm instance %s to %d GB", rootVolumeUuid, uuid, planRootDisk.Size.ValueInt64()))
+			_, err := runJob(ctx, job{
+				name:         "resize root volume",
+				resourceUuid: rootVolumeUuid,
+			}, func() (any, error) {
+				return r.client.ResizeRootVolume(rootVolumeUuid, param.ResizeRootVolumeParam{
+					ResizeRootVolume: param.ResizeRootVolumeDetailParam{
+						Size: utils.GBToBytes(planRootDisk.Size.ValueInt64()),
+					},
+				})
+			})
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Update VmInstance Error",
+					fmt.Sprintf("failed to resize root volume %s of vm instance %s, err: %v", rootVolumeUuid, uuid, err),
+				)
+				return
+			}
+		}
+
+		if !planRootDisk.PrimaryStorageUuid.IsUnknown() && planRootDisk.PrimaryStorageUuid.ValueString() != "" &&
+			!planRootDisk.PrimaryStorageUuid.Equal(stateRootDisk.PrimaryStorageUuid) {
+			err := migrateRootVolume(ctx, r, uuid, rootVolumeUuid, planRootDisk.PrimaryStorageUuid.ValueString())
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Update VmInstance Error",
+					fmt.Sprintf("failed to migrate root volume of vm instance %s, err: %v", uuid, err),
+				)
+				return
 			}
 		}
 	}
 
-	for _, nic := range vm.VMNics {
-		staticIP := types.StringNull()
-		if ip, ok := originalNetworkInterfaces[nic.L3NetworkUUID]; ok && ip == nic.IP {
-			staticIP = types.StringValue(ip)
+	// UPDATE DATA DISKS
+	// data disks that were already changed are saved to state even if a later one fails
+	if !plan.DataDisks.IsUnknown() && !plan.DataDisks.Equal(state.DataDisks) {
+		var planDisks, stateDisks []diskModel
+		if !plan.DataDisks.IsNull() {
+			resp.Diagnostics.Append(plan.DataDisks.ElementsAs(ctx, &planDisks, false)...)
+		}
+		if !state.DataDisks.IsNull() {
+			resp.Diagnostics.Append(state.DataDisks.ElementsAs(ctx, &stateDisks, false)...)
+		}
+		if resp.Diagnostics.HasError() {
+			return
 		}
 
-		networkInterfaces = append(networkInterfaces, NetworkInterfaceModel{
-			L3NetworkUuid: types.StringValue(nic.L3NetworkUUID),
-			DefaultL3:     types.BoolValue(vm.DefaultL3NetworkUUID != "" && nic.L3NetworkUUID == vm.DefaultL3NetworkUUID),
-			StaticIp:      staticIP,
-		})
+		vm, err := r.client.GetVmInstance(uuid)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Could not read vm instance", "Error: "+err.Error(),
+			)
+			return
+		}
+
+		owned, diags := ownedDataVolumes(ctx, req.Private)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		disks, err := updateDataDisks(ctx, r.client, vm, planDisks, stateDisks, owned, plan.Expunge.ValueBool())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Update VmInstance Error",
+				fmt.Sprintf("failed to update data disks of vm instance %s, err: %v", uuid, err),
+			)
+		}
+		resp.Diagnostics.Append(setOwnedDataVolumes(ctx, resp.Private, owned)...)
+		if len(disks) > 0 || !plan.DataDisks.IsNull() {
+			plan.DataDisks, diags = types.ListValueFrom(ctx, types.ObjectType{AttrTypes: diskModelAttrTypes}, disks)
+			resp.Diagnostics.Append(diags...)
+		}
 	}
-	state.NetworkInterfaces, _ = types.ListValueFrom(ctx, types.ObjectType{
-		AttrTypes: map[string]attr.Type{
-			"port_group_uuid": types.StringType,
-			"default_l3":      types.BoolType,
-			"static_ip":       types.StringType,
-		},
-	}, networkInterfaces)
 
-	resp.Diagnostics.Append(diags...)
+	vm, err := r.client.GetVmInstance(uuid)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not read vm instance", "Error: "+err.Error(),
+		)
+		return
+	}
 
-	diags = resp.State.Set(ctx, &state)
+	diags = readVmInstanceState(ctx, r, vm, &plan)
 	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
+	if diags.HasError() {
 		return
 	}
 
-}
-
-func (r *vmResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
 }
 
 // Delete implements resource.Resource.
 func (r *vmResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_instance", "delete") {
+		return
+	}
+
 	var state vmInstanceDataSourceModel
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -718,27 +1153,56 @@ func (r *vmResource) Delete(ctx context.Context, req resource.DeleteRequest, res
 		return
 	}
 
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultVmDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
 	//TODO: query vm instance again in delete function is not smart. Update vm instance's data disk state in read function is a better way
 	vm, err := r.client.GetVmInstance(state.Uuid.ValueString())
 	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryVmInstance, state.Uuid.ValueString())
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("vm instance %s not found, nothing to delete", state.Uuid.ValueString()))
+			return
+		}
+
 		resp.Diagnostics.AddError(
 			"Could not read vm instance", "Error: "+err.Error(),
 		)
 		return
 	}
 
+	// Only the data volumes created through data_disks are deleted with the vm. Volumes attached by
+	// zsphere_volume_attachment, or taken over on import, are detached by the platform and outlive it.
+	owned, diags := ownedDataVolumes(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var volumeUuids []string
 	for _, volume := range vm.AllVolumes {
-		if volume.Type != "Data" {
-			continue
+		if volume.Type == "Data" && owned[volume.UUID] {
+			volumeUuids = append(volumeUuids, volume.UUID)
 		}
-		volumeUuids = append(volumeUuids, volume.UUID)
 	}
 
 	tflog.Info(ctx, "Deleting vm instance "+state.Uuid.String())
 
 	//Delete existing vm instance
-	err = r.client.DestroyVmInstance(state.Uuid.ValueString(), param.DeleteModePermissive)
+	_, err = runJob(ctx, job{
+		name:         "destroy vm instance",
+		resourceUuid: state.Uuid.ValueString(),
+		progress: func() (string, error) {
+			return vmInstanceProgress(r.client, state.Uuid.ValueString())
+		},
+	}, func() (any, error) {
+		return nil, r.client.DestroyVmInstance(state.Uuid.ValueString(), param.DeleteModePermissive)
+	})
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Could not destroy vm instance", "Error: "+err.Error(),
@@ -748,7 +1212,12 @@ func (r *vmResource) Delete(ctx context.Context, req resource.DeleteRequest, res
 
 	//Delete vm data volume
 	for _, uuid := range volumeUuids {
-		err = r.client.DeleteDataVolume(uuid, param.DeleteModePermissive)
+		_, err = runJob(ctx, job{
+			name:         "delete data volume",
+			resourceUuid: uuid,
+		}, func() (any, error) {
+			return nil, r.client.DeleteDataVolume(uuid, param.DeleteModePermissive)
+		})
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Could not delete data volume", "Error: "+err.Error(),
@@ -765,7 +1234,12 @@ func (r *vmResource) Delete(ctx context.Context, req resource.DeleteRequest, res
 	if expunge {
 		tflog.Info(ctx, fmt.Sprintf("expunge instance %s", state.Uuid.ValueString()))
 		//Expunge vm instance
-		err = r.client.ExpungeVmInstance(state.Uuid.ValueString())
+		_, err = runJob(ctx, job{
+			name:         "expunge vm instance",
+			resourceUuid: state.Uuid.ValueString(),
+		}, func() (any, error) {
+			return nil, r.client.ExpungeVmInstance(state.Uuid.ValueString())
+		})
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Could not expunge vm instance", "Error: "+err.Error(),
@@ -775,7 +1249,12 @@ func (r *vmResource) Delete(ctx context.Context, req resource.DeleteRequest, res
 
 		//Expunge vm data volume
 		for _, uuid := range volumeUuids {
-			err = r.client.ExpungeDataVolume(uuid)
+			_, err = runJob(ctx, job{
+				name:         "expunge data volume",
+				resourceUuid: uuid,
+			}, func() (any, error) {
+				return nil, r.client.ExpungeDataVolume(uuid)
+			})
 			if err != nil {
 				resp.Diagnostics.AddError(
 					"Could not expunge data volume", "Error: "+err.Error(),
@@ -787,7 +1266,7 @@ func (r *vmResource) Delete(ctx context.Context, req resource.DeleteRequest, res
 
 }
 
-func isDiskParamValid(r *vmResource, model diskModel) error {
+func isDiskParamValid(cli *client.ZSClient, model diskModel) error {
 	if model.PrimaryStorageUuid.IsNull() || model.PrimaryStorageUuid.ValueString() == "" {
 		return nil
 	}
@@ -799,7 +1278,7 @@ func isDiskParamValid(r *vmResource, model diskModel) error {
 	qparam.AddQ("uuid=" + dataDiskPrimaryStorageUuid)
 	qparam.AddQ("state=Enabled")
 	qparam.Limit(1)
-	primaryStorages, err := r.client.QueryPrimaryStorage(qparam)
+	primaryStorages, err := cli.QueryPrimaryStorage(qparam)
 	if err != nil {
 		return fmt.Errorf("failed to get primary storage %s, err: %v", dataDiskPrimaryStorageUuid, err)
 	}
@@ -822,3 +1301,407 @@ func isDiskParamValid(r *vmResource, model diskModel) error {
 	}
 	return nil
 }
+
+// onlineChangeRefusedMessages mark an error of an online cpu/memory change as one that can be made by
+// stopping the vm, e.g. when the guest does not support hot plugging cpus or memory.
+var onlineChangeRefusedMessages = []string{
+	"online",
+	"hot plug",
+	"hotplug",
+	"hot-plug",
+	"numa",
+}
+
+// isOnlineChangeRefused reports whether a failed online cpu/memory change can be made on the stopped vm instead.
+func isOnlineChangeRefused(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, refused := range onlineChangeRefusedMessages {
+		if strings.Contains(message, refused) {
+			return true
+		}
+	}
+	return false
+}
+
+// resizeVmInstance changes the cpu number and/or memory size of a vm instance.
+// A running vm is resized online first; if the platform cannot make the change online
+// the vm is stopped, resized and started again, also when the change of the stopped vm fails.
+func resizeVmInstance(ctx context.Context, r *vmResource, uuid string, cpuNum *int, memorySize *int64) error {
+	vm, err := r.client.GetVmInstance(uuid)
+	if err != nil {
+		return fmt.Errorf("failed to get vm instance %s, err: %v", uuid, err)
+	}
+
+	resizeParam := param.UpdateVmInstanceParam{
+		UpdateVmInstance: param.UpdateVmInstanceDetailParam{
+			Name:       vm.Name,
+			CpuNum:     cpuNum,
+			MemorySize: memorySize,
+		},
+	}
+
+	resize := func() (any, error) {
+		return r.client.UpdateVmInstance(uuid, resizeParam)
+	}
+	resizeJob := job{
+		name:         "resize vm instance",
+		resourceUuid: uuid,
+	}
+
+	if vm.State != vmStateRunning {
+		_, err = runJob(ctx, resizeJob, resize)
+		return err
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("change cpu/memory of running vm instance %s online", uuid))
+	_, onlineErr := runJob(ctx, resizeJob, resize)
+	if onlineErr == nil || errors.Is(onlineErr, errJobNotFinished) || !isOnlineChangeRefused(onlineErr) {
+		return onlineErr
+	}
+
+	tflog.Warn(ctx, fmt.Sprintf("vm instance %s cannot be changed online, stop and start it to apply the change. error: %v", uuid, onlineErr))
+	if err = stopVmInstance(ctx, r, uuid); err != nil {
+		return fmt.Errorf("online change failed: %v; %w", onlineErr, err)
+	}
+
+	_, offlineErr := runJob(ctx, resizeJob, resize)
+	if errors.Is(offlineErr, errJobNotFinished) {
+		return fmt.Errorf("online change failed: %v; change of the stopped vm instance: %w. The vm instance is left stopped", onlineErr, offlineErr)
+	}
+
+	if err = startVmInstance(ctx, r, uuid); err != nil {
+		if offlineErr != nil {
+			return fmt.Errorf("online change failed: %v; change of the stopped vm instance failed: %v; "+
+				"vm instance %s could not be started again, err: %w", onlineErr, offlineErr, uuid, err)
+		}
+		return fmt.Errorf("vm instance %s was resized but could not be started again, err: %w", uuid, err)
+	}
+	if offlineErr != nil {
+		return fmt.Errorf("online change failed: %v; change of the stopped vm instance failed: %w. The vm instance was started again unchanged",
+			onlineErr, offlineErr)
+	}
+	return nil
+}
+
+// migrateRootVolume moves the root volume of a vm instance to another primary storage. A running vm
+// is migrated live by moving its root volume; a stopped vm is migrated cold, without its data volumes.
+func migrateRootVolume(ctx context.Context, r *vmResource, uuid string, rootVolumeUuid string, primaryStorageUuid string) error {
+	vm, err := r.client.GetVmInstance(uuid)
+	if err != nil {
+		return fmt.Errorf("failed to get vm instance %s, err: %v", uuid, err)
+	}
+
+	if vm.State == vmStateRunning {
+		return migrateVolume(ctx, r.client, rootVolumeUuid, primaryStorageUuid)
+	}
+
+	err = isDiskParamValid(r.client, diskModel{
+		PrimaryStorageUuid: types.StringValue(primaryStorageUuid),
+	})
+	if err != nil {
+		return err
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("migrate stopped vm instance %s to primary storage %s", uuid, primaryStorageUuid))
+	_, err = runJob(ctx, job{
+		name:         "migrate vm instance",
+		resourceUuid: uuid,
+		progress: func() (string, error) {
+			return vmInstanceProgress(r.client, uuid)
+		},
+	}, func() (any, error) {
+		return nil, r.client.PrimaryStorageMigrateVm(param.PrimaryStorageMigrateVmParam{
+			PrimaryStorageMigrateVm: param.PrimaryStorageMigrateVmDetailParam{
+				VmInstanceUuid:        uuid,
+				DstPrimaryStorageUuid: primaryStorageUuid,
+			},
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to migrate vm instance %s to primary storage %s, err: %w", uuid, primaryStorageUuid, err)
+	}
+	return nil
+}
+
+// vmInstanceProgress reports the state of a vm instance while a job works on it.
+func vmInstanceProgress(cli *client.ZSClient, uuid string) (string, error) {
+	qparam := param.NewQueryParam()
+	qparam.AddQ("uuid=" + uuid)
+	vms, err := cli.QueryVmInstance(qparam)
+	if err != nil {
+		return "", err
+	}
+	if len(vms) == 0 {
+		return "vm instance not created yet", nil
+	}
+	return "vm instance is " + vms[0].State, nil
+}
+
+// stopVmInstance gracefully stops a vm instance.
+func stopVmInstance(ctx context.Context, r *vmResource, uuid string) error {
+	_, err := runJob(ctx, job{
+		name:         "stop vm instance",
+		resourceUuid: uuid,
+		progress: func() (string, error) {
+			return vmInstanceProgress(r.client, uuid)
+		},
+	}, func() (any, error) {
+		return r.client.StopVmInstance(uuid, param.StopVmInstanceParam{
+			StopVmInstance: param.StopVmInstanceDetailParam{
+				Type:   param.Grace,
+				StopHA: true,
+			},
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to stop vm instance %s, err: %w", uuid, err)
+	}
+	return nil
+}
+
+// startVmInstance starts a vm instance.
+func startVmInstance(ctx context.Context, r *vmResource, uuid string) error {
+	_, err := runJob(ctx, job{
+		name:         "start vm instance",
+		resourceUuid: uuid,
+		progress: func() (string, error) {
+			return vmInstanceProgress(r.client, uuid)
+		},
+	}, func() (any, error) {
+		return r.client.StartVmInstance(uuid, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start vm instance %s, err: %w", uuid, err)
+	}
+	return nil
+}
+
+// readVmInstanceState refreshes the model from the vm inventory and the system tags of the vm and its volumes.
+// Values the platform does not report back, such as expunge, are kept as they are in the model.
+func readVmInstanceState(ctx context.Context, r *vmResource, vm *view.VmInstanceInventoryView, model *vmInstanceDataSourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// root_disk is always known once the vm has been read, so a null value means the vm is being imported
+	importing := model.RootDisk.IsNull()
+
+	vmTags, err := querySystemTags(r.client, []string{vm.UUID}, "")
+	if err != nil {
+		diags.AddError(
+			"Could not read vm instance system tags", "Error: "+err.Error(),
+		)
+		return diags
+	}
+
+	model.Uuid = types.StringValue(vm.UUID)
+	model.Name = types.StringValue(vm.Name)
+	model.Description = types.StringValue(vm.Description)
+	model.ImageUuid = types.StringValue(vm.ImageUUID)
+	model.MemorySize = types.Int64Value(utils.BytesToMB(vm.MemorySize))
+	model.CPUNum = types.Int64Value(int64(vm.CPUNum))
+	model.ZoneUuid = types.StringValue(vm.ZoneUUID)
+	model.ClusterUuid = types.StringValue(vm.ClusterUUID)
+	// a stopped vm has no host, report the host it last ran on
+	if vm.HostUUID != "" {
+		model.HostUuid = types.StringValue(vm.HostUUID)
+	} else {
+		model.HostUuid = types.StringValue(vm.LastHostUUID)
+	}
+	if model.Strategy.IsNull() || model.Strategy.IsUnknown() {
+		model.Strategy = types.StringValue(string(param.InstantStart))
+	}
+	model.PowerState = types.StringValue(vm.State)
+
+	// SYSTEM TAGS
+	model.NeverStop = types.BoolValue(false)
+	model.UserData = types.StringNull()
+	staticIps := make(map[string]string)
+	for _, tag := range vmTags[vm.UUID] {
+		switch {
+		case tag == vmNeverStopSystemTag:
+			model.NeverStop = types.BoolValue(true)
+		case strings.HasPrefix(tag, vmUserDataSystemTagPrefix):
+			model.UserData = types.StringValue(strings.TrimPrefix(tag, vmUserDataSystemTagPrefix))
+		case strings.HasPrefix(tag, vmStaticIpSystemTagPrefix):
+			// staticIp::<l3_uuid>::<ip>
+			parts := strings.SplitN(strings.TrimPrefix(tag, vmStaticIpSystemTagPrefix), "::", 2)
+			if len(parts) == 2 {
+				staticIps[parts[0]] = parts[1]
+			}
+		}
+	}
+
+	// NETWORK
+	nics := append([]view.VmNicInventoryView{}, vm.VMNics...)
+	sort.SliceStable(nics, func(i, j int) bool {
+		return nics[i].DeviceID < nics[j].DeviceID
+	})
+
+	var priorNics []NetworkInterfaceModel
+	if !model.NetworkInterfaces.IsNull() && !model.NetworkInterfaces.IsUnknown() {
+		diags.Append(model.NetworkInterfaces.ElementsAs(ctx, &priorNics, false)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+	nics = orderVmNics(nics, priorNics)
+
+	var networkInterfaces []NetworkInterfaceModel
+	var vmNics []NicsModel
+	for _, nic := range nics {
+		staticIp := nic.IP
+		if ip, ok := staticIps[nic.L3NetworkUUID]; ok {
+			staticIp = ip
+		}
+
+		networkInterfaces = append(networkInterfaces, NetworkInterfaceModel{
+			L3NetworkUuid: types.StringValue(nic.L3NetworkUUID),
+			DefaultL3:     types.BoolValue(vm.DefaultL3NetworkUUID != "" && nic.L3NetworkUUID == vm.DefaultL3NetworkUUID),
+			StaticIp:      types.StringValue(staticIp),
+		})
+		vmNics = append(vmNics, NicsModel{
+			Uuid:    types.StringValue(nic.UUID),
+			Ip:      types.StringValue(nic.IP),
+			Netmask: types.StringValue(nic.Netmask),
+			Gateway: types.StringValue(nic.Gateway),
+		})
+	}
+
+	if len(networkInterfaces) > 0 || !model.NetworkInterfaces.IsNull() {
+		var d diag.Diagnostics
+		model.NetworkInterfaces, d = types.ListValueFrom(ctx, types.ObjectType{AttrTypes: networkInterfaceAttrTypes}, networkInterfaces)
+		diags.Append(d...)
+	}
+
+	var d diag.Diagnostics
+	model.VMNics, d = types.ListValueFrom(ctx, types.ObjectType{AttrTypes: networkModelAttrTypes}, vmNics)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	// DISKS
+	var rootVolume *view.VolumeView
+	var dataVolumes []view.VolumeView
+	var volumeUuids []string
+	for i := range vm.AllVolumes {
+		volume := vm.AllVolumes[i]
+		switch {
+		case volume.UUID == vm.RootVolumeUUID || volume.Type == "Root":
+			rootVolume = &volume
+		case volume.Type == "Data":
+			dataVolumes = append(dataVolumes, volume)
+		default:
+			continue
+		}
+		volumeUuids = append(volumeUuids, volume.UUID)
+	}
+	sort.SliceStable(dataVolumes, func(i, j int) bool {
+		return dataVolumes[i].DeviceID < dataVolumes[j].DeviceID
+	})
+
+	virtioVolumes, err := querySystemTags(r.client, volumeUuids, volumeVirtioSCSISystemTag)
+	if err != nil {
+		diags.AddError(
+			"Could not read volume system tags", "Error: "+err.Error(),
+		)
+		return diags
+	}
+
+	if rootVolume != nil {
+		model.RootDisk, d = types.ObjectValueFrom(ctx, diskModelAttrTypes, volumeToDiskModel(*rootVolume, virtioVolumes))
+		diags.Append(d...)
+	} else {
+		model.RootDisk = types.ObjectNull(diskModelAttrTypes)
+	}
+
+	var priorDisks []diskModel
+	if !model.DataDisks.IsNull() && !model.DataDisks.IsUnknown() {
+		diags.Append(model.DataDisks.ElementsAs(ctx, &priorDisks, false)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	// Disks already known by uuid are refreshed in place, so volumes attached by others
+	// (e.g. zsphere_volume_attachment) are left alone. On import every data volume of the vm
+	// is taken, and state written before data disks carried a uuid takes the volumes with the
+	// settings of its disks. Neither is recorded as owned, so they are never deleted.
+	volumesByUuid := make(map[string]view.VolumeView)
+	for _, volume := range dataVolumes {
+		volumesByUuid[volume.UUID] = volume
+	}
+	taken := make(map[string]bool)
+	var dataDisks []diskModel
+	for _, disk := range priorDisks {
+		if disk.Uuid.IsNull() || disk.Uuid.IsUnknown() {
+			continue
+		}
+		volume, ok := volumesByUuid[disk.Uuid.ValueString()]
+		if !ok {
+			continue
+		}
+		taken[volume.UUID] = true
+		dataDisks = append(dataDisks, volumeToDiskModel(volume, virtioVolumes))
+	}
+	for _, disk := range priorDisks {
+		if !disk.Uuid.IsNull() && !disk.Uuid.IsUnknown() {
+			continue
+		}
+		for _, volume := range dataVolumes {
+			candidate := volumeToDiskModel(volume, virtioVolumes)
+			if !taken[volume.UUID] && sameLegacyDataDisk(disk, candidate) {
+				taken[volume.UUID] = true
+				dataDisks = append(dataDisks, candidate)
+				break
+			}
+		}
+	}
+	if importing {
+		for _, volume := range dataVolumes {
+			if !taken[volume.UUID] {
+				dataDisks = append(dataDisks, volumeToDiskModel(volume, virtioVolumes))
+			}
+		}
+	}
+
+	if len(dataDisks) > 0 || !model.DataDisks.IsNull() {
+		model.DataDisks, d = types.ListValueFrom(ctx, types.ObjectType{AttrTypes: diskModelAttrTypes}, dataDisks)
+		diags.Append(d...)
+	}
+
+	return diags
+}
+
+// sameLegacyDataDisk reports whether a volume matches the settings of a data disk from state written before data
+// disks carried a uuid. Settings missing from that state match anything.
+func sameLegacyDataDisk(prior diskModel, volume diskModel) bool {
+	same := func(a, b attr.Value) bool {
+		return a.IsNull() || a.IsUnknown() || a.Equal(b)
+	}
+	return same(prior.Size, volume.Size) && same(prior.PrimaryStorageUuid, volume.PrimaryStorageUuid) &&
+		same(prior.CephPoolName, volume.CephPoolName) && same(prior.VirtioSCSI, volume.VirtioSCSI)
+}
+
+// volumeToDiskModel converts a volume inventory to the disk model used by root_disk and data_disks.
+func volumeToDiskModel(volume view.VolumeView, virtioVolumes map[string][]string) diskModel {
+	return diskModel{
+		Uuid:               types.StringValue(volume.UUID),
+		Size:               types.Int64Value(utils.BytesToGB(int64(volume.Size))),
+		PrimaryStorageUuid: types.StringValue(volume.PrimaryStorageUUID),
+		CephPoolName:       cephPoolNameFromInstallPath(volume.InstallPath),
+		VirtioSCSI:         types.BoolValue(len(virtioVolumes[volume.UUID]) > 0),
+	}
+}
+
+// cephPoolNameFromInstallPath returns the pool of a ceph volume, whose install path looks like ceph://<pool>/<volume_uuid>.
+func cephPoolNameFromInstallPath(installPath string) types.String {
+	if !strings.HasPrefix(installPath, "ceph://") {
+		return types.StringNull()
+	}
+
+	pool := strings.SplitN(strings.TrimPrefix(installPath, "ceph://"), "/", 2)[0]
+	if pool == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(pool)
+}
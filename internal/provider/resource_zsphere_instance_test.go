@@ -0,0 +1,30 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsOnlineChangeRefused(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"online change not supported", errors.New("resize vm instance failed, err: cannot change the cpu of vm[uuid:1] online"), true},
+		{"memory hot plug disabled", errors.New("memory hot-plug is not enabled for the vm"), true},
+		{"numa disabled", errors.New("NUMA is not enabled in the global config"), true},
+		{"quota exceeded", errors.New("quota exceeded: vm.cpuNum, requested 64, quota 32"), false},
+		{"invalid value", errors.New("invalid value[0] of field[cpuNum]"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isOnlineChangeRefused(tt.err); got != tt.want {
+				t.Errorf("isOnlineChangeRefused() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
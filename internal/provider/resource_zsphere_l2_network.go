@@ -0,0 +1,573 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+var (
+	_ resource.Resource                = &l2NetworkResource{}
+	_ resource.ResourceWithConfigure   = &l2NetworkResource{}
+	_ resource.ResourceWithImportState = &l2NetworkResource{}
+)
+
+// Types of L2 networks.
+const (
+	l2NetworkTypeNoVlan    = "L2NoVlanNetwork"
+	l2NetworkTypeVlan      = "L2VlanNetwork"
+	l2NetworkTypeVxlanPool = "VxlanNetworkPool"
+)
+
+type l2NetworkResource struct {
+	client   *client.ZSClient
+	readOnly bool
+}
+
+type l2NetworkResourceModel struct {
+	Uuid                 types.String `tfsdk:"uuid"`
+	Name                 types.String `tfsdk:"name"`
+	Description          types.String `tfsdk:"description"`
+	Type                 types.String `tfsdk:"type"`
+	ZoneUuid             types.String `tfsdk:"zone_uuid"`
+	PhysicalInterface    types.String `tfsdk:"physical_interface"`
+	Vlan                 types.Int64  `tfsdk:"vlan"`
+	StartVni             types.Int64  `tfsdk:"start_vni"`
+	EndVni               types.Int64  `tfsdk:"end_vni"`
+	VtepCidr             types.String `tfsdk:"vtep_cidr"`
+	AttachedClusterUuids types.Set    `tfsdk:"attached_cluster_uuids"`
+}
+
+func L2NetworkResource() resource.Resource {
+	return &l2NetworkResource{}
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *l2NetworkResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*resourceProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *resourceProviderData, got: %T. Please report this issue to the Provider developer. ", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.client
+	r.readOnly = providerData.readOnly
+}
+
+// Metadata implements resource.Resource.
+func (r *l2NetworkResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_l2_network"
+}
+
+// Schema implements resource.Resource.
+func (r *l2NetworkResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "This resource allows you to manage L2 networks in ZSphere: no-VLAN and VLAN networks on a physical interface, and VXLAN network pools. " +
+			"Port groups are created on an L2 network, which must be attached to the clusters of the hosts that use it.",
+		Attributes: map[string]schema.Attribute{
+			"uuid": schema.StringAttribute{
+				Computed:    true,
+				Description: "The unique identifier of the L2 network. Automatically generated by ZSphere.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the L2 network.",
+			},
+			"description": schema.StringAttribute{
+				Optional:    true,
+				Description: "A description of the L2 network.",
+			},
+			"type": schema.StringAttribute{
+				Required: true,
+				Description: "The type of the L2 network: 'L2NoVlanNetwork', 'L2VlanNetwork' or 'VxlanNetworkPool'. " +
+					"Changing this forces a new L2 network to be created.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(l2NetworkTypeNoVlan, l2NetworkTypeVlan, l2NetworkTypeVxlanPool),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"zone_uuid": schema.StringAttribute{
+				Required:    true,
+				Description: "The UUID of the zone of the L2 network. Changing this forces a new L2 network to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"physical_interface": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Description: "The physical interface of the hosts the L2 network is bridged to, such as 'eth0'. " +
+					"Required for 'L2NoVlanNetwork' and 'L2VlanNetwork'. Changing this forces a new L2 network to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"vlan": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The VLAN ID of an 'L2VlanNetwork', from 1 to 4094. Changing this forces a new L2 network to be created.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 4094),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"start_vni": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The first VNI of the VNI range of a 'VxlanNetworkPool'. Changing this forces a new L2 network to be created.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 16777214),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"end_vni": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The last VNI of the VNI range of a 'VxlanNetworkPool'. Changing this forces a new L2 network to be created.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 16777214),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"vtep_cidr": schema.StringAttribute{
+				Optional: true,
+				Description: "The CIDR of the VTEP IPs of the hosts, such as '172.20.0.0/16', required to attach a 'VxlanNetworkPool' to clusters. " +
+					"It is only used when a cluster is attached.",
+			},
+			"attached_cluster_uuids": schema.SetAttribute{
+				Optional:    true,
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "The UUIDs of the clusters the L2 network is attached to. Clusters are attached and detached in place. " +
+					"If not set, the clusters are not managed by Terraform.",
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+// Create implements resource.Resource.
+func (r *l2NetworkResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_l2_network", "create") {
+		return
+	}
+
+	var plan l2NetworkResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// physical_interface is computed, so it is unknown in the plan when it is not configured
+	params := plan
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("physical_interface"), &params.PhysicalInterface)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := checkL2NetworkParams(params); err != nil {
+		resp.Diagnostics.AddError("Params Error", fmt.Sprintf("invalid L2 network param, err: %v", err))
+		return
+	}
+
+	var clusterUuids []string
+	if !plan.AttachedClusterUuids.IsUnknown() {
+		resp.Diagnostics.Append(plan.AttachedClusterUuids.ElementsAs(ctx, &clusterUuids, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	physicalInterface := ""
+	if !plan.PhysicalInterface.IsUnknown() {
+		physicalInterface = plan.PhysicalInterface.ValueString()
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("create %s %s", plan.Type.ValueString(), plan.Name.ValueString()))
+	var l2Network *view.L2NetworkInventoryView
+	var err error
+	switch plan.Type.ValueString() {
+	case l2NetworkTypeVlan:
+		l2Network, err = r.client.CreateL2VlanNetwork(param.CreateL2VlanNetworkParam{
+			Params: param.CreateL2VlanNetworkDetailParam{
+				Vlan:              int(plan.Vlan.ValueInt64()),
+				Name:              plan.Name.ValueString(),
+				Description:       plan.Description.ValueString(),
+				ZoneUuid:          plan.ZoneUuid.ValueString(),
+				PhysicalInterface: physicalInterface,
+			},
+		})
+	case l2NetworkTypeVxlanPool:
+		l2Network, err = r.client.CreateL2VxlanNetworkPool(param.CreateL2VxlanNetworkPoolParam{
+			Params: param.CreateL2VxlanNetworkPoolDetailParam{
+				Name:              plan.Name.ValueString(),
+				Description:       plan.Description.ValueString(),
+				ZoneUuid:          plan.ZoneUuid.ValueString(),
+				PhysicalInterface: physicalInterface,
+			},
+		})
+	default:
+		l2Network, err = r.client.CreateL2NoVlanNetwork(param.CreateL2NoVlanNetworkParam{
+			Params: param.CreateL2NoVlanNetworkDetailParam{
+				Name:              plan.Name.ValueString(),
+				Description:       plan.Description.ValueString(),
+				ZoneUuid:          plan.ZoneUuid.ValueString(),
+				PhysicalInterface: physicalInterface,
+			},
+		})
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not create L2 network", "Error: "+err.Error(),
+		)
+		return
+	}
+	uuid := l2Network.UUID
+
+	if isSetInt64(plan.StartVni) {
+		tflog.Info(ctx, fmt.Sprintf("create VNI range %d-%d of L2 network %s", plan.StartVni.ValueInt64(), plan.EndVni.ValueInt64(), uuid))
+		_, err = r.client.CreateVniRange(uuid, param.CreateVniRangeParam{
+			Params: param.CreateVniRangeDetailParam{
+				Name:     plan.Name.ValueString(),
+				StartVni: int(plan.StartVni.ValueInt64()),
+				EndVni:   int(plan.EndVni.ValueInt64()),
+			},
+		})
+		if err != nil {
+			err = fmt.Errorf("fail to create VNI range of L2 network %s, err: %v", uuid, err)
+		}
+	}
+	if err == nil {
+		err = r.updateClusters(ctx, uuid, plan.VtepCidr.ValueString(), nil, clusterUuids)
+	}
+	if err != nil {
+		keepPartiallyCreated(ctx, resp, uuid, "Could not configure L2 network", err.Error())
+		return
+	}
+
+	l2Network, err = r.client.GetL2Network(uuid)
+	if err != nil {
+		keepPartiallyCreated(ctx, resp, uuid, "Could not read L2 network", "Error: "+err.Error())
+		return
+	}
+
+	diags = readL2NetworkState(ctx, r.client, l2Network, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read implements resource.Resource.
+func (r *l2NetworkResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state l2NetworkResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	l2Network, err := r.client.GetL2Network(state.Uuid.ValueString())
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryL2Network, state.Uuid.ValueString())
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("L2 network %s not found, remove it from state", state.Uuid.ValueString()))
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not read L2 network", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = readL2NetworkState(ctx, r.client, l2Network, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *l2NetworkResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_l2_network", "update") {
+		return
+	}
+
+	var plan l2NetworkResourceModel
+	var state l2NetworkResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid := state.Uuid.ValueString()
+	plan.Uuid = state.Uuid
+
+	if !plan.Name.Equal(state.Name) || !plan.Description.Equal(state.Description) {
+		tflog.Info(ctx, fmt.Sprintf("update L2 network %s", uuid))
+		_, err := r.client.UpdateL2Network(uuid, param.UpdateL2NetworkParam{
+			UpdateL2Network: param.UpdateL2NetworkDetailParam{
+				Name:        plan.Name.ValueString(),
+				Description: descriptionParam(plan.Description),
+			},
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Could not update L2 network",
+				fmt.Sprintf("fail to update L2 network %s, err: %v", uuid, err),
+			)
+			return
+		}
+	}
+
+	if !plan.AttachedClusterUuids.IsUnknown() && !plan.AttachedClusterUuids.Equal(state.AttachedClusterUuids) {
+		var oldClusters, newClusters []string
+		resp.Diagnostics.Append(state.AttachedClusterUuids.ElementsAs(ctx, &oldClusters, false)...)
+		resp.Diagnostics.Append(plan.AttachedClusterUuids.ElementsAs(ctx, &newClusters, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if plan.Type.ValueString() == l2NetworkTypeVxlanPool && plan.VtepCidr.ValueString() == "" && len(newClusters) > 0 {
+			resp.Diagnostics.AddAttributeError(path.Root("vtep_cidr"), "Params Error", "vtep_cidr is required to attach a VxlanNetworkPool to clusters")
+			return
+		}
+
+		if err := r.updateClusters(ctx, uuid, plan.VtepCidr.ValueString(), oldClusters, newClusters); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("attached_cluster_uuids"), "Could not update L2 network clusters", err.Error())
+			return
+		}
+	}
+
+	l2Network, err := r.client.GetL2Network(uuid)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not read L2 network", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = readL2NetworkState(ctx, r.client, l2Network, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete implements resource.Resource.
+func (r *l2NetworkResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_l2_network", "delete") {
+		return
+	}
+
+	var state l2NetworkResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid := state.Uuid.ValueString()
+	if uuid == "" {
+		tflog.Warn(ctx, "L2 network uuid is empty, so nothing to delete, skip it")
+		return
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("delete L2 network %s", uuid))
+	err := r.client.DeleteL2Network(uuid, param.DeleteModePermissive)
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryL2Network, uuid)
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("L2 network %s not found, nothing to delete", uuid))
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not delete L2 network", "Error: "+err.Error(),
+		)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState.
+func (r *l2NetworkResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("uuid"), req, resp)
+}
+
+// updateClusters detaches an L2 network from the old clusters that are not in the new ones, and attaches it to the new clusters.
+// A VXLAN pool is attached with the CIDR of the VTEPs of the cluster hosts.
+func (r *l2NetworkResource) updateClusters(ctx context.Context, uuid string, vtepCidr string, oldClusters []string, newClusters []string) error {
+	for _, clusterUuid := range oldClusters {
+		if slices.Contains(newClusters, clusterUuid) {
+			continue
+		}
+		tflog.Info(ctx, fmt.Sprintf("detach L2 network %s from cluster %s", uuid, clusterUuid))
+		if _, err := r.client.DetachL2NetworkFromCluster(uuid, clusterUuid); err != nil {
+			return fmt.Errorf("fail to detach L2 network %s from cluster %s, err: %v", uuid, clusterUuid, err)
+		}
+	}
+
+	for _, clusterUuid := range newClusters {
+		if slices.Contains(oldClusters, clusterUuid) {
+			continue
+		}
+
+		var systemTags []string
+		if vtepCidr != "" {
+			systemTags = append(systemTags, fmt.Sprintf("l2NetworkUuid::%s::clusterUuid::%s::cidr::{%s}", uuid, clusterUuid, vtepCidr))
+		}
+
+		tflog.Info(ctx, fmt.Sprintf("attach L2 network %s to cluster %s", uuid, clusterUuid))
+		_, err := r.client.AttachL2NetworkToCluster(uuid, clusterUuid, param.AttachL2NetworkToClusterParam{
+			BaseParam: param.BaseParam{
+				SystemTags: systemTags,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("fail to attach L2 network %s to cluster %s, err: %v", uuid, clusterUuid, err)
+		}
+	}
+	return nil
+}
+
+// checkL2NetworkParams checks the attributes each type of L2 network needs.
+func checkL2NetworkParams(plan l2NetworkResourceModel) error {
+	l2Type := plan.Type.ValueString()
+	physicalInterface := plan.PhysicalInterface.ValueString()
+
+	if l2Type != l2NetworkTypeVxlanPool {
+		if physicalInterface == "" && !plan.PhysicalInterface.IsUnknown() {
+			return fmt.Errorf("physical_interface is required for %s", l2Type)
+		}
+		if isSetInt64(plan.StartVni) || isSetInt64(plan.EndVni) || !plan.VtepCidr.IsNull() {
+			return fmt.Errorf("start_vni, end_vni and vtep_cidr are only supported by %s", l2NetworkTypeVxlanPool)
+		}
+	}
+
+	if l2Type == l2NetworkTypeVlan && plan.Vlan.IsNull() {
+		return fmt.Errorf("vlan is required for %s", l2Type)
+	}
+	if l2Type != l2NetworkTypeVlan && !plan.Vlan.IsNull() {
+		return fmt.Errorf("vlan is only supported by %s", l2NetworkTypeVlan)
+	}
+
+	if l2Type == l2NetworkTypeVxlanPool {
+		if isSetInt64(plan.StartVni) != isSetInt64(plan.EndVni) {
+			return fmt.Errorf("start_vni and end_vni must be set together")
+		}
+		if plan.StartVni.ValueInt64() > plan.EndVni.ValueInt64() {
+			return fmt.Errorf("start_vni %d is greater than end_vni %d", plan.StartVni.ValueInt64(), plan.EndVni.ValueInt64())
+		}
+		if plan.VtepCidr.ValueString() == "" && !plan.AttachedClusterUuids.IsUnknown() && len(plan.AttachedClusterUuids.Elements()) > 0 {
+			return fmt.Errorf("vtep_cidr is required to attach a %s to clusters", l2Type)
+		}
+	}
+	return nil
+}
+
+func readL2NetworkState(ctx context.Context, cli *client.ZSClient, l2Network *view.L2NetworkInventoryView, model *l2NetworkResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	model.Uuid = types.StringValue(l2Network.UUID)
+	model.Name = types.StringValue(l2Network.Name)
+	if l2Network.Description != "" || !model.Description.IsNull() {
+		model.Description = types.StringValue(l2Network.Description)
+	}
+	model.Type = types.StringValue(l2Network.Type)
+	model.ZoneUuid = types.StringValue(l2Network.ZoneUuid)
+	model.PhysicalInterface = types.StringValue(l2Network.PhysicalInterface)
+	if l2Network.Type == l2NetworkTypeVlan {
+		model.Vlan = types.Int64Value(int64(l2Network.Vlan))
+	}
+
+	model.StartVni = types.Int64Null()
+	model.EndVni = types.Int64Null()
+	if l2Network.Type == l2NetworkTypeVxlanPool {
+		qparam := param.NewQueryParam()
+		qparam.AddQ("l2NetworkUuid=" + l2Network.UUID)
+		vniRanges, err := cli.QueryVniRange(qparam)
+		if err != nil {
+			diags.AddError(
+				"Could not read VNI ranges of L2 network", "Error: "+err.Error(),
+			)
+			return diags
+		}
+
+		if len(vniRanges) > 0 {
+			model.StartVni = types.Int64Value(int64(vniRanges[0].StartVni))
+			model.EndVni = types.Int64Value(int64(vniRanges[0].EndVni))
+		}
+	}
+
+	clusterUuids := l2Network.AttachedClusterUuids
+	if clusterUuids == nil {
+		clusterUuids = []string{}
+	}
+	var d diag.Diagnostics
+	model.AttachedClusterUuids, d = types.SetValueFrom(ctx, types.StringType, clusterUuids)
+	diags.Append(d...)
+
+	return diags
+}
+
+// isSetInt64 reports whether an optional and computed attribute is set in the plan.
+func isSetInt64(value types.Int64) bool {
+	return !value.IsNull() && !value.IsUnknown()
+}
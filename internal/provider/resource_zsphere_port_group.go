@@ -0,0 +1,812 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+var (
+	_ resource.Resource                = &portGroupResource{}
+	_ resource.ResourceWithConfigure   = &portGroupResource{}
+	_ resource.ResourceWithImportState = &portGroupResource{}
+)
+
+const (
+	portGroupDhcpServiceType = "DHCP"
+	// flatNetworkProviderType is the network service provider of the DHCP service of flat networks.
+	flatNetworkProviderType = "Flat"
+	// portGroupDhcpServerTagPrefix is followed by the DHCP server IP and "::ipUuid::<uuid of the IP>".
+	portGroupDhcpServerTagPrefix = "flatNetwork::DhcpServer::"
+)
+
+var dnsModelAttrTypes = map[string]attr.Type{
+	"dns_model": types.StringType,
+}
+
+var ipRangeModelAttrTypes = map[string]attr.Type{
+	"ip_range_name": types.StringType,
+	"start_ip":      types.StringType,
+	"end_ip":        types.StringType,
+	"netmask":       types.StringType,
+	"gateway":       types.StringType,
+	"cidr":          types.StringType,
+}
+
+type portGroupResource struct {
+	client   *client.ZSClient
+	readOnly bool
+}
+
+type portGroupResourceModel struct {
+	Uuid          types.String            `tfsdk:"uuid"`
+	Name          types.String            `tfsdk:"name"`
+	Description   types.String            `tfsdk:"description"`
+	L2NetworkUuid types.String            `tfsdk:"l2_network_uuid"`
+	Category      types.String            `tfsdk:"category"`
+	DnsDomain     types.String            `tfsdk:"dns_domain"`
+	DnsServers    types.List              `tfsdk:"dns_servers"`
+	EnableDhcp    types.Bool              `tfsdk:"enable_dhcp"`
+	DhcpServerIp  types.String            `tfsdk:"dhcp_server_ip"`
+	IpRanges      []portGroupIpRangeModel `tfsdk:"ip_ranges"`
+	Dns           types.List              `tfsdk:"dns"`
+	IpRange       types.List              `tfsdk:"ip_range"`
+}
+
+// portGroupIpRangeModel is an IP range of a port group, given either by cidr or by start_ip, end_ip, netmask and gateway.
+type portGroupIpRangeModel struct {
+	Name    types.String `tfsdk:"name"`
+	Cidr    types.String `tfsdk:"cidr"`
+	StartIp types.String `tfsdk:"start_ip"`
+	EndIp   types.String `tfsdk:"end_ip"`
+	Netmask types.String `tfsdk:"netmask"`
+	Gateway types.String `tfsdk:"gateway"`
+}
+
+func PortGroupResource() resource.Resource {
+	return &portGroupResource{}
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *portGroupResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*resourceProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *resourceProviderData, got: %T. Please report this issue to the Provider developer. ", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.client
+	r.readOnly = providerData.readOnly
+}
+
+// Metadata implements resource.Resource.
+func (r *portGroupResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_port_group"
+}
+
+// Schema implements resource.Resource.
+func (r *portGroupResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "This resource allows you to manage port groups (L3 networks) in ZSphere. " +
+			"A port group is created on an L2 network, and provides the IP ranges, DNS servers and DHCP service of the instances attached to it.",
+		Attributes: map[string]schema.Attribute{
+			"uuid": schema.StringAttribute{
+				Computed:    true,
+				Description: "The unique identifier of the port group. Automatically generated by ZSphere.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the port group.",
+			},
+			"description": schema.StringAttribute{
+				Optional:    true,
+				Description: "A description of the port group.",
+			},
+			"l2_network_uuid": schema.StringAttribute{
+				Required:    true,
+				Description: "The UUID of the L2 network the port group is created on. Changing this forces a new port group to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"category": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("Private"),
+				Description: "The category of the port group: 'Private', 'Public' or 'System'. Defaults to 'Private'.",
+				Validators: []validator.String{
+					stringvalidator.OneOf("Private", "Public", "System"),
+				},
+			},
+			"dns_domain": schema.StringAttribute{
+				Optional:    true,
+				Description: "The DNS domain of the port group.",
+			},
+			"dns_servers": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "The DNS servers of the port group, in the order they are offered to instances.",
+			},
+			"enable_dhcp": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Whether the flat network DHCP service is enabled on the port group. Defaults to true.",
+			},
+			"dhcp_server_ip": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Description: "The IP address of the DHCP server of the port group. " +
+					"If not set, ZSphere picks a free IP of the IP ranges when DHCP is enabled, which may change with the IP ranges.",
+			},
+			"dns": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "List of DNS servers of the port group, as returned by the `zsphere_port_groups` data source.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"dns_model": schema.StringAttribute{
+							Computed:    true,
+							Description: "DNS server address.",
+						},
+					},
+				},
+			},
+			"ip_range": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "List of IP ranges of the port group, as returned by the `zsphere_port_groups` data source.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"ip_range_name": schema.StringAttribute{
+							Computed:    true,
+							Description: "Name of the IP range.",
+						},
+						"start_ip": schema.StringAttribute{
+							Computed:    true,
+							Description: "Starting IP address of the range.",
+						},
+						"end_ip": schema.StringAttribute{
+							Computed:    true,
+							Description: "Ending IP address of the range.",
+						},
+						"netmask": schema.StringAttribute{
+							Computed:    true,
+							Description: "Netmask of the IP range.",
+						},
+						"gateway": schema.StringAttribute{
+							Computed:    true,
+							Description: "Gateway of the IP range.",
+						},
+						"cidr": schema.StringAttribute{
+							Computed:    true,
+							Description: "CIDR of the IP range.",
+						},
+					},
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"ip_ranges": schema.ListNestedBlock{
+				Description: "The IP ranges of the port group. Each range is given either by `cidr`, or by `start_ip`, `end_ip`, `netmask` and `gateway`. " +
+					"A changed range is deleted and added again, which fails while one of its IPs is in use. " +
+					"Imported ranges are shown by `start_ip`, `end_ip`, `netmask` and `gateway`.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Required:    true,
+							Description: "The name of the IP range.",
+						},
+						"cidr": schema.StringAttribute{
+							Optional:    true,
+							Description: "The CIDR of the IP range, such as '192.168.10.0/24'. All IPs of the CIDR but the gateway are allocated to instances.",
+						},
+						"start_ip": schema.StringAttribute{
+							Optional:    true,
+							Description: "The first IP of the range.",
+						},
+						"end_ip": schema.StringAttribute{
+							Optional:    true,
+							Description: "The last IP of the range.",
+						},
+						"netmask": schema.StringAttribute{
+							Optional:    true,
+							Description: "The netmask of the range.",
+						},
+						"gateway": schema.StringAttribute{
+							Optional:    true,
+							Description: "The gateway of the range. Required with `start_ip`, and defaults to the first IP of the CIDR with `cidr`.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Create implements resource.Resource.
+func (r *portGroupResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_port_group", "create") {
+		return
+	}
+
+	var plan portGroupResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := checkIpRanges(plan.IpRanges); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("ip_ranges"), "Params Error", err.Error())
+		return
+	}
+
+	var dnsServers []string
+	resp.Diagnostics.Append(plan.DnsServers.ElementsAs(ctx, &dnsServers, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var systemTags []string
+	if !plan.DhcpServerIp.IsUnknown() && plan.DhcpServerIp.ValueString() != "" {
+		systemTags = append(systemTags, portGroupDhcpServerTag(plan.DhcpServerIp.ValueString()))
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("create port group %s", plan.Name.ValueString()))
+	l3Network, err := r.client.CreateL3Network(param.CreateL3NetworkParam{
+		BaseParam: param.BaseParam{
+			SystemTags: systemTags,
+		},
+		Params: param.CreateL3NetworkDetailParam{
+			Name:          plan.Name.ValueString(),
+			Description:   plan.Description.ValueString(),
+			Type:          "L3BasicNetwork",
+			L2NetworkUuid: plan.L2NetworkUuid.ValueString(),
+			Category:      plan.Category.ValueString(),
+			IpVersion:     4,
+			DnsDomain:     plan.DnsDomain.ValueString(),
+		},
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not create port group", "Error: "+err.Error(),
+		)
+		return
+	}
+	uuid := l3Network.UUID
+
+	err = r.setDnsServers(ctx, uuid, nil, dnsServers)
+	if err == nil {
+		err = r.updateIpRanges(ctx, l3Network, nil, plan.IpRanges)
+	}
+	if err == nil && plan.EnableDhcp.ValueBool() {
+		err = r.setDhcp(ctx, uuid, true)
+	}
+	if err != nil {
+		keepPartiallyCreated(ctx, resp, uuid, "Could not configure port group", err.Error())
+		return
+	}
+
+	l3Network, err = r.client.GetL3Network(uuid)
+	if err != nil {
+		keepPartiallyCreated(ctx, resp, uuid, "Could not read port group", "Error: "+err.Error())
+		return
+	}
+
+	diags = readPortGroupState(ctx, r.client, l3Network, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read implements resource.Resource.
+func (r *portGroupResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state portGroupResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	l3Network, err := r.client.GetL3Network(state.Uuid.ValueString())
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryL3Network, state.Uuid.ValueString())
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("port group %s not found, remove it from state", state.Uuid.ValueString()))
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not read port group", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = readPortGroupState(ctx, r.client, l3Network, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *portGroupResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_port_group", "update") {
+		return
+	}
+
+	var plan portGroupResourceModel
+	var state portGroupResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid := state.Uuid.ValueString()
+	plan.Uuid = state.Uuid
+
+	if err := checkIpRanges(plan.IpRanges); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("ip_ranges"), "Params Error", err.Error())
+		return
+	}
+
+	if !plan.Name.Equal(state.Name) || !plan.Description.Equal(state.Description) ||
+		!plan.Category.Equal(state.Category) || !plan.DnsDomain.Equal(state.DnsDomain) {
+		// like the description, a dns_domain removed from the configuration is cleared
+		dnsDomain := plan.DnsDomain.ValueString()
+
+		tflog.Info(ctx, fmt.Sprintf("update port group %s", uuid))
+		_, err := r.client.UpdateL3Network(uuid, param.UpdateL3NetworkParam{
+			UpdateL3Network: param.UpdateL3NetworkDetailParam{
+				Name:        plan.Name.ValueString(),
+				Description: descriptionParam(plan.Description),
+				Category:    plan.Category.ValueStringPointer(),
+				DnsDomain:   &dnsDomain,
+			},
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Could not update port group",
+				fmt.Sprintf("fail to update port group %s, err: %v", uuid, err),
+			)
+			return
+		}
+	}
+
+	if !plan.DnsServers.Equal(state.DnsServers) {
+		var oldServers, newServers []string
+		resp.Diagnostics.Append(state.DnsServers.ElementsAs(ctx, &oldServers, false)...)
+		resp.Diagnostics.Append(plan.DnsServers.ElementsAs(ctx, &newServers, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := r.setDnsServers(ctx, uuid, oldServers, newServers); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("dns_servers"), "Could not update port group DNS servers", err.Error())
+			return
+		}
+	}
+
+	l3Network, err := r.client.GetL3Network(uuid)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not read port group", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	if err := r.updateIpRanges(ctx, l3Network, state.IpRanges, plan.IpRanges); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("ip_ranges"), "Could not update port group IP ranges", err.Error())
+		return
+	}
+
+	if !plan.EnableDhcp.Equal(state.EnableDhcp) {
+		if err := r.setDhcp(ctx, uuid, plan.EnableDhcp.ValueBool()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("enable_dhcp"), "Could not update port group DHCP", err.Error())
+			return
+		}
+	}
+
+	if !plan.DhcpServerIp.IsUnknown() && plan.DhcpServerIp.ValueString() != "" && !plan.DhcpServerIp.Equal(state.DhcpServerIp) {
+		tflog.Info(ctx, fmt.Sprintf("set DHCP server of port group %s to %s", uuid, plan.DhcpServerIp.ValueString()))
+		if err := setPortGroupDhcpServerSystemTag(r.client, uuid, plan.DhcpServerIp.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("dhcp_server_ip"),
+				"Could not update port group DHCP server",
+				fmt.Sprintf("fail to set DHCP server of port group %s, err: %v", uuid, err),
+			)
+			return
+		}
+	}
+
+	l3Network, err = r.client.GetL3Network(uuid)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not read port group", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = readPortGroupState(ctx, r.client, l3Network, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete implements resource.Resource.
+func (r *portGroupResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_port_group", "delete") {
+		return
+	}
+
+	var state portGroupResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid := state.Uuid.ValueString()
+	if uuid == "" {
+		tflog.Warn(ctx, "port group uuid is empty, so nothing to delete, skip it")
+		return
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("delete port group %s", uuid))
+	err := r.client.DeleteL3Network(uuid, param.DeleteModePermissive)
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryL3Network, uuid)
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("port group %s not found, nothing to delete", uuid))
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not delete port group", "Error: "+err.Error(),
+		)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState.
+func (r *portGroupResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("uuid"), req, resp)
+}
+
+// setDnsServers replaces the DNS servers of a port group. All old servers are removed first, so the new ones keep their order.
+func (r *portGroupResource) setDnsServers(ctx context.Context, uuid string, oldServers []string, newServers []string) error {
+	for _, dns := range oldServers {
+		tflog.Info(ctx, fmt.Sprintf("remove DNS %s from port group %s", dns, uuid))
+		if _, err := r.client.RemoveDnsFromL3Network(uuid, dns); err != nil {
+			return fmt.Errorf("fail to remove DNS %s from port group %s, err: %v", dns, uuid, err)
+		}
+	}
+
+	for _, dns := range newServers {
+		tflog.Info(ctx, fmt.Sprintf("add DNS %s to port group %s", dns, uuid))
+		_, err := r.client.AddDnsToL3Network(uuid, param.AddDnsToL3NetworkParam{
+			Params: param.AddDnsToL3NetworkDetailParam{
+				Dns: dns,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("fail to add DNS %s to port group %s, err: %v", dns, uuid, err)
+		}
+	}
+	return nil
+}
+
+// updateIpRanges deletes the IP ranges of the state that are not planned any more, then adds the planned ranges that are not in the state.
+func (r *portGroupResource) updateIpRanges(ctx context.Context, l3Network *view.L3NetworkInventoryView, stateRanges []portGroupIpRangeModel, planRanges []portGroupIpRangeModel) error {
+	uuid := l3Network.UUID
+
+	for _, stateRange := range stateRanges {
+		if containsIpRange(planRanges, stateRange) {
+			continue
+		}
+		ipRange := findIpRange(l3Network.IpRanges, stateRange)
+		if ipRange == nil {
+			continue
+		}
+
+		tflog.Info(ctx, fmt.Sprintf("delete IP range %s of port group %s", ipRange.UUID, uuid))
+		if err := r.client.DeleteIpRange(ipRange.UUID, param.DeleteModePermissive); err != nil {
+			return fmt.Errorf("fail to delete IP range %s of port group %s, err: %v", ipRange.UUID, uuid, err)
+		}
+	}
+
+	for _, planRange := range planRanges {
+		if containsIpRange(stateRanges, planRange) {
+			continue
+		}
+
+		var err error
+		if planRange.Cidr.ValueString() != "" {
+			tflog.Info(ctx, fmt.Sprintf("add IP range %s to port group %s", planRange.Cidr.ValueString(), uuid))
+			_, err = r.client.AddIpRangeByNetworkCidr(uuid, param.AddIpRangeByNetworkCidrParam{
+				Params: param.AddIpRangeByNetworkCidrDetailParam{
+					Name:        planRange.Name.ValueString(),
+					NetworkCidr: planRange.Cidr.ValueString(),
+					Gateway:     planRange.Gateway.ValueString(),
+				},
+			})
+		} else {
+			tflog.Info(ctx, fmt.Sprintf("add IP range %s-%s to port group %s", planRange.StartIp.ValueString(), planRange.EndIp.ValueString(), uuid))
+			_, err = r.client.AddIpRange(uuid, param.AddIpRangeParam{
+				Params: param.AddIpRangeDetailParam{
+					Name:    planRange.Name.ValueString(),
+					StartIp: planRange.StartIp.ValueString(),
+					EndIp:   planRange.EndIp.ValueString(),
+					Netmask: planRange.Netmask.ValueString(),
+					Gateway: planRange.Gateway.ValueString(),
+				},
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("fail to add IP range %s to port group %s, err: %v", planRange.Name.ValueString(), uuid, err)
+		}
+	}
+	return nil
+}
+
+// setDhcp attaches the flat network DHCP service to a port group, or detaches it.
+func (r *portGroupResource) setDhcp(ctx context.Context, uuid string, enable bool) error {
+	qparam := param.NewQueryParam()
+	qparam.AddQ("type=" + flatNetworkProviderType)
+	providers, err := r.client.QueryNetworkServiceProvider(qparam)
+	if err != nil {
+		return fmt.Errorf("fail to query network service provider %s, err: %v", flatNetworkProviderType, err)
+	}
+	if len(providers) == 0 {
+		return fmt.Errorf("network service provider %s not found", flatNetworkProviderType)
+	}
+	services := map[string][]string{providers[0].UUID: {portGroupDhcpServiceType}}
+
+	if enable {
+		tflog.Info(ctx, fmt.Sprintf("attach DHCP service to port group %s", uuid))
+		err = r.client.AttachNetworkServiceToL3Network(uuid, param.AttachNetworkServiceToL3NetworkParam{
+			Params: param.AttachNetworkServiceToL3NetworkDetailParam{NetworkServices: services},
+		})
+	} else {
+		tflog.Info(ctx, fmt.Sprintf("detach DHCP service from port group %s", uuid))
+		err = r.client.DetachNetworkServiceFromL3Network(uuid, param.DetachNetworkServiceFromL3NetworkParam{
+			Params: param.DetachNetworkServiceFromL3NetworkDetailParam{NetworkServices: services},
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("fail to set DHCP service of port group %s, err: %v", uuid, err)
+	}
+	return nil
+}
+
+// checkIpRanges checks that each IP range is given either by cidr or by start_ip, end_ip, netmask and gateway.
+func checkIpRanges(ipRanges []portGroupIpRangeModel) error {
+	for _, ipRange := range ipRanges {
+		// values from other resources are only known when applying
+		if ipRange.Cidr.IsUnknown() || ipRange.StartIp.IsUnknown() || ipRange.EndIp.IsUnknown() ||
+			ipRange.Netmask.IsUnknown() || ipRange.Gateway.IsUnknown() {
+			continue
+		}
+
+		name := ipRange.Name.ValueString()
+		if ipRange.Cidr.ValueString() != "" {
+			if ipRange.StartIp.ValueString() != "" || ipRange.EndIp.ValueString() != "" || ipRange.Netmask.ValueString() != "" {
+				return fmt.Errorf("IP range %s has a cidr, so start_ip, end_ip and netmask must not be set", name)
+			}
+			continue
+		}
+		if ipRange.StartIp.ValueString() == "" || ipRange.EndIp.ValueString() == "" ||
+			ipRange.Netmask.ValueString() == "" || ipRange.Gateway.ValueString() == "" {
+			return fmt.Errorf("IP range %s needs either a cidr, or start_ip, end_ip, netmask and gateway", name)
+		}
+	}
+	return nil
+}
+
+// containsIpRange reports whether ipRanges has a range with the same attributes.
+func containsIpRange(ipRanges []portGroupIpRangeModel, ipRange portGroupIpRangeModel) bool {
+	for _, r := range ipRanges {
+		if r == ipRange {
+			return true
+		}
+	}
+	return false
+}
+
+// findIpRange returns the IP range of the inventory that was added for a range of the model, by its cidr or its first and last IP.
+func findIpRange(ipRanges []view.IpRangeInventoryView, ipRange portGroupIpRangeModel) *view.IpRangeInventoryView {
+	for i, r := range ipRanges {
+		if ipRange.Cidr.ValueString() != "" {
+			if r.NetworkCidr == ipRange.Cidr.ValueString() {
+				return &ipRanges[i]
+			}
+		} else if r.StartIp == ipRange.StartIp.ValueString() && r.EndIp == ipRange.EndIp.ValueString() {
+			return &ipRanges[i]
+		}
+	}
+	return nil
+}
+
+func portGroupDhcpServerTag(ip string) string {
+	return portGroupDhcpServerTagPrefix + ip + "::ipUuid::NULL"
+}
+
+// setPortGroupDhcpServerSystemTag rewrites the DHCP server system tag of a port group, or creates it if the port group has none.
+func setPortGroupDhcpServerSystemTag(cli *client.ZSClient, uuid string, ip string) error {
+	tags, err := queryPortGroupDhcpServerTags(cli, uuid)
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		_, err = cli.CreateSystemTag(param.CreateTagParam{
+			Params: param.CreateTagDetailParam{
+				ResourceType: "L3NetworkVO",
+				ResourceUuid: uuid,
+				Tag:          portGroupDhcpServerTag(ip),
+			},
+		})
+		return err
+	}
+
+	for _, tag := range tags {
+		_, err = cli.UpdateSystemTag(tag.UUID, param.UpdateSystemTagParam{
+			UpdateSystemTag: param.UpdateSystemTagDetailParam{
+				Tag: portGroupDhcpServerTag(ip),
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func queryPortGroupDhcpServerTags(cli *client.ZSClient, uuid string) ([]view.SystemTagView, error) {
+	qparam := param.NewQueryParam()
+	qparam.AddQ("resourceUuid=" + uuid)
+	qparam.AddQ("tag~=" + portGroupDhcpServerTagPrefix + "%")
+	return cli.QuerySystemTags(qparam)
+}
+
+func readPortGroupState(ctx context.Context, cli *client.ZSClient, l3Network *view.L3NetworkInventoryView, model *portGroupResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	dhcpServerTags, err := queryPortGroupDhcpServerTags(cli, l3Network.UUID)
+	if err != nil {
+		diags.AddError(
+			"Could not read port group system tags", "Error: "+err.Error(),
+		)
+		return diags
+	}
+
+	model.Uuid = types.StringValue(l3Network.UUID)
+	model.Name = types.StringValue(l3Network.Name)
+	if l3Network.Description != "" || !model.Description.IsNull() {
+		model.Description = types.StringValue(l3Network.Description)
+	}
+	model.L2NetworkUuid = types.StringValue(l3Network.L2NetworkUuid)
+	model.Category = types.StringValue(l3Network.Category)
+	if l3Network.DnsDomain != "" || !model.DnsDomain.IsNull() {
+		model.DnsDomain = types.StringValue(l3Network.DnsDomain)
+	}
+
+	dhcp := false
+	for _, service := range l3Network.NetworkServices {
+		dhcp = dhcp || service.NetworkServiceType == portGroupDhcpServiceType
+	}
+	model.EnableDhcp = types.BoolValue(dhcp)
+
+	model.DhcpServerIp = types.StringNull()
+	for _, tag := range dhcpServerTags {
+		ip, _, _ := strings.Cut(strings.TrimPrefix(tag.Tag, portGroupDhcpServerTagPrefix), "::")
+		model.DhcpServerIp = types.StringValue(ip)
+	}
+
+	var d diag.Diagnostics
+	if len(l3Network.Dns) > 0 || !model.DnsServers.IsNull() {
+		model.DnsServers, d = types.ListValueFrom(ctx, types.StringType, l3Network.Dns)
+		diags.Append(d...)
+	}
+
+	dns := make([]dnsModel, len(l3Network.Dns))
+	for i, server := range l3Network.Dns {
+		dns[i] = dnsModel{Dns: types.StringValue(server)}
+	}
+	model.Dns, d = types.ListValueFrom(ctx, types.ObjectType{AttrTypes: dnsModelAttrTypes}, dns)
+	diags.Append(d...)
+
+	ipRanges := make([]ipRangeModel, len(l3Network.IpRanges))
+	for i, ipRange := range l3Network.IpRanges {
+		ipRanges[i] = ipRangeModel{
+			Name:        types.StringValue(ipRange.Name),
+			StartIp:     types.StringValue(ipRange.StartIp),
+			EndIp:       types.StringValue(ipRange.EndIp),
+			Netmask:     types.StringValue(ipRange.Netmask),
+			Gateway:     types.StringValue(ipRange.Gateway),
+			NetworkCidr: types.StringValue(ipRange.NetworkCidr),
+		}
+	}
+	model.IpRange, d = types.ListValueFrom(ctx, types.ObjectType{AttrTypes: ipRangeModelAttrTypes}, ipRanges)
+	diags.Append(d...)
+
+	model.IpRanges = readPortGroupIpRanges(model.IpRanges, l3Network.IpRanges)
+
+	return diags
+}
+
+// readPortGroupIpRanges keeps the configured IP ranges that still exist in the form they were given, in their order,
+// and appends the other ranges of the port group, e.g. after import, by start_ip, end_ip, netmask and gateway.
+func readPortGroupIpRanges(modelRanges []portGroupIpRangeModel, ipRanges []view.IpRangeInventoryView) []portGroupIpRangeModel {
+	var result []portGroupIpRangeModel
+	found := make(map[string]bool)
+
+	for _, modelRange := range modelRanges {
+		ipRange := findIpRange(ipRanges, modelRange)
+		if ipRange == nil || found[ipRange.UUID] {
+			continue
+		}
+		found[ipRange.UUID] = true
+		modelRange.Name = types.StringValue(ipRange.Name)
+		result = append(result, modelRange)
+	}
+
+	for _, ipRange := range ipRanges {
+		if found[ipRange.UUID] {
+			continue
+		}
+		result = append(result, portGroupIpRangeModel{
+			Name:    types.StringValue(ipRange.Name),
+			Cidr:    types.StringNull(),
+			StartIp: types.StringValue(ipRange.StartIp),
+			EndIp:   types.StringValue(ipRange.EndIp),
+			Netmask: types.StringValue(ipRange.Netmask),
+			Gateway: types.StringValue(ipRange.Gateway),
+		})
+	}
+	return result
+}
@@ -0,0 +1,586 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+var (
+	_ resource.Resource                = &securityGroupResource{}
+	_ resource.ResourceWithConfigure   = &securityGroupResource{}
+	_ resource.ResourceWithImportState = &securityGroupResource{}
+)
+
+var securityGroupRuleModelAttrTypes = map[string]attr.Type{
+	"uuid":                       types.StringType,
+	"type":                       types.StringType,
+	"protocol":                   types.StringType,
+	"action":                     types.StringType,
+	"state":                      types.StringType,
+	"priority":                   types.Int64Type,
+	"ip_version":                 types.Int64Type,
+	"src_ip_range":               types.StringType,
+	"dst_ip_range":               types.StringType,
+	"dst_port_range":             types.StringType,
+	"remote_security_group_uuid": types.StringType,
+	"description":                types.StringType,
+}
+
+type securityGroupResource struct {
+	client   *client.ZSClient
+	readOnly bool
+}
+
+type securityGroupResourceModel struct {
+	Uuid                   types.String `tfsdk:"uuid"`
+	Name                   types.String `tfsdk:"name"`
+	Description            types.String `tfsdk:"description"`
+	IpVersion              types.Int64  `tfsdk:"ip_version"`
+	State                  types.String `tfsdk:"state"`
+	AttachedL3NetworkUuids types.Set    `tfsdk:"attached_l3_network_uuids"`
+	VmNicUuids             types.Set    `tfsdk:"vm_nic_uuids"`
+	Rules                  types.Set    `tfsdk:"rules"`
+}
+
+// securityGroupRuleModel is a rule of a security group as returned by ZSphere, shared by the security group resource and data source.
+type securityGroupRuleModel struct {
+	Uuid                    types.String `tfsdk:"uuid"`
+	Type                    types.String `tfsdk:"type"`
+	Protocol                types.String `tfsdk:"protocol"`
+	Action                  types.String `tfsdk:"action"`
+	State                   types.String `tfsdk:"state"`
+	Priority                types.Int64  `tfsdk:"priority"`
+	IpVersion               types.Int64  `tfsdk:"ip_version"`
+	SrcIpRange              types.String `tfsdk:"src_ip_range"`
+	DstIpRange              types.String `tfsdk:"dst_ip_range"`
+	DstPortRange            types.String `tfsdk:"dst_port_range"`
+	RemoteSecurityGroupUuid types.String `tfsdk:"remote_security_group_uuid"`
+	Description             types.String `tfsdk:"description"`
+}
+
+func SecurityGroupResource() resource.Resource {
+	return &securityGroupResource{}
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *securityGroupResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*resourceProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *resourceProviderData, got: %T. Please report this issue to the Provider developer. ", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.client
+	r.readOnly = providerData.readOnly
+}
+
+// Metadata implements resource.Resource.
+func (r *securityGroupResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_security_group"
+}
+
+// Schema implements resource.Resource.
+func (r *securityGroupResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "This resource allows you to manage security groups in ZSphere, the port groups they are attached to and the VM NICs bound to them. " +
+			"The rules of a security group are managed with `zsphere_security_group_rule`.",
+		Attributes: map[string]schema.Attribute{
+			"uuid": schema.StringAttribute{
+				Computed:    true,
+				Description: "The unique identifier of the security group. Automatically generated by ZSphere.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the security group.",
+			},
+			"description": schema.StringAttribute{
+				Optional:    true,
+				Description: "A description of the security group.",
+			},
+			"ip_version": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(4),
+				Description: "The IP version of the security group, 4 or 6. Defaults to 4. Changing this forces a new security group to be created.",
+				Validators: []validator.Int64{
+					int64validator.OneOf(4, 6),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"state": schema.StringAttribute{
+				Computed:    true,
+				Description: "The state of the security group, such as 'Enabled'.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"attached_l3_network_uuids": schema.SetAttribute{
+				Optional:    true,
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "The UUIDs of the port groups the security group is attached to. Port groups are attached and detached in place. " +
+					"If not set, the port groups are not managed by Terraform.",
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"vm_nic_uuids": schema.SetAttribute{
+				Optional:    true,
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "The UUIDs of the VM NICs bound to the security group, such as the `vm_nics` of a `zsphere_instance`. " +
+					"A NIC can only be bound once its port group is attached to the security group. If not set, the NICs are not managed by Terraform.",
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"rules": schema.SetNestedAttribute{
+				Computed:    true,
+				Description: "The rules of the security group, including the default rules created by ZSphere.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: securityGroupRuleAttributes(),
+				},
+			},
+		},
+	}
+}
+
+func securityGroupRuleAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"uuid": schema.StringAttribute{
+			Computed:    true,
+			Description: "UUID of the rule.",
+		},
+		"type": schema.StringAttribute{
+			Computed:    true,
+			Description: "Direction of the rule, 'Ingress' or 'Egress'.",
+		},
+		"protocol": schema.StringAttribute{
+			Computed:    true,
+			Description: "Protocol of the rule: 'TCP', 'UDP', 'ICMP' or 'ALL'.",
+		},
+		"action": schema.StringAttribute{
+			Computed:    true,
+			Description: "Action of the rule, 'ACCEPT' or 'DROP'.",
+		},
+		"state": schema.StringAttribute{
+			Computed:    true,
+			Description: "State of the rule, 'Enabled' or 'Disabled'.",
+		},
+		"priority": schema.Int64Attribute{
+			Computed:    true,
+			Description: "Priority of the rule among the rules of the same direction, 1 is evaluated first.",
+		},
+		"ip_version": schema.Int64Attribute{
+			Computed:    true,
+			Description: "IP version of the rule.",
+		},
+		"src_ip_range": schema.StringAttribute{
+			Computed:    true,
+			Description: "Source IPs of an ingress rule.",
+		},
+		"dst_ip_range": schema.StringAttribute{
+			Computed:    true,
+			Description: "Destination IPs of an egress rule.",
+		},
+		"dst_port_range": schema.StringAttribute{
+			Computed:    true,
+			Description: "Destination ports of a TCP or UDP rule.",
+		},
+		"remote_security_group_uuid": schema.StringAttribute{
+			Computed:    true,
+			Description: "UUID of the security group whose members the rule applies to.",
+		},
+		"description": schema.StringAttribute{
+			Computed:    true,
+			Description: "Description of the rule.",
+		},
+	}
+}
+
+// Create implements resource.Resource.
+func (r *securityGroupResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_security_group", "create") {
+		return
+	}
+
+	var plan securityGroupResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var l3NetworkUuids, vmNicUuids []string
+	if !plan.AttachedL3NetworkUuids.IsUnknown() {
+		resp.Diagnostics.Append(plan.AttachedL3NetworkUuids.ElementsAs(ctx, &l3NetworkUuids, false)...)
+	}
+	if !plan.VmNicUuids.IsUnknown() {
+		resp.Diagnostics.Append(plan.VmNicUuids.ElementsAs(ctx, &vmNicUuids, false)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("create security group %s", plan.Name.ValueString()))
+	securityGroup, err := r.client.CreateSecurityGroup(param.CreateSecurityGroupParam{
+		Params: param.CreateSecurityGroupDetailParam{
+			Name:        plan.Name.ValueString(),
+			Description: plan.Description.ValueString(),
+			IpVersion:   int(plan.IpVersion.ValueInt64()),
+		},
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not create security group", "Error: "+err.Error(),
+		)
+		return
+	}
+	uuid := securityGroup.UUID
+
+	err = r.updateL3Networks(ctx, uuid, nil, l3NetworkUuids)
+	if err == nil {
+		err = r.updateVmNics(ctx, uuid, nil, vmNicUuids)
+	}
+	if err != nil {
+		keepPartiallyCreated(ctx, resp, uuid, "Could not configure security group", err.Error())
+		return
+	}
+
+	securityGroup, err = r.client.GetSecurityGroup(uuid)
+	if err != nil {
+		keepPartiallyCreated(ctx, resp, uuid, "Could not read security group", "Error: "+err.Error())
+		return
+	}
+
+	diags = readSecurityGroupState(ctx, r.client, securityGroup, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read implements resource.Resource.
+func (r *securityGroupResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state securityGroupResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	securityGroup, err := r.client.GetSecurityGroup(state.Uuid.ValueString())
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QuerySecurityGroup, state.Uuid.ValueString())
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("security group %s not found, remove it from state", state.Uuid.ValueString()))
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not read security group", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = readSecurityGroupState(ctx, r.client, securityGroup, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *securityGroupResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_security_group", "update") {
+		return
+	}
+
+	var plan securityGroupResourceModel
+	var state securityGroupResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid := state.Uuid.ValueString()
+	plan.Uuid = state.Uuid
+
+	if !plan.Name.Equal(state.Name) || !plan.Description.Equal(state.Description) {
+		tflog.Info(ctx, fmt.Sprintf("update security group %s", uuid))
+		_, err := r.client.UpdateSecurityGroup(uuid, param.UpdateSecurityGroupParam{
+			UpdateSecurityGroup: param.UpdateSecurityGroupDetailParam{
+				Name:        plan.Name.ValueString(),
+				Description: descriptionParam(plan.Description),
+			},
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Could not update security group",
+				fmt.Sprintf("fail to update security group %s, err: %v", uuid, err),
+			)
+			return
+		}
+	}
+
+	// NICs are unbound before their port groups are detached, and bound after their port groups are attached
+	var oldNics, newNics []string
+	updateNics := !plan.VmNicUuids.IsUnknown() && !plan.VmNicUuids.Equal(state.VmNicUuids)
+	if updateNics {
+		resp.Diagnostics.Append(state.VmNicUuids.ElementsAs(ctx, &oldNics, false)...)
+		resp.Diagnostics.Append(plan.VmNicUuids.ElementsAs(ctx, &newNics, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := r.updateVmNics(ctx, uuid, oldNics, nil); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("vm_nic_uuids"), "Could not update security group VM NICs", err.Error())
+			return
+		}
+	}
+
+	if !plan.AttachedL3NetworkUuids.IsUnknown() && !plan.AttachedL3NetworkUuids.Equal(state.AttachedL3NetworkUuids) {
+		var oldL3Networks, newL3Networks []string
+		resp.Diagnostics.Append(state.AttachedL3NetworkUuids.ElementsAs(ctx, &oldL3Networks, false)...)
+		resp.Diagnostics.Append(plan.AttachedL3NetworkUuids.ElementsAs(ctx, &newL3Networks, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := r.updateL3Networks(ctx, uuid, oldL3Networks, newL3Networks); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("attached_l3_network_uuids"), "Could not update security group port groups", err.Error())
+			return
+		}
+	}
+
+	if updateNics {
+		if err := r.updateVmNics(ctx, uuid, nil, newNics); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("vm_nic_uuids"), "Could not update security group VM NICs", err.Error())
+			return
+		}
+	}
+
+	securityGroup, err := r.client.GetSecurityGroup(uuid)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not read security group", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = readSecurityGroupState(ctx, r.client, securityGroup, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete implements resource.Resource.
+func (r *securityGroupResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_security_group", "delete") {
+		return
+	}
+
+	var state securityGroupResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid := state.Uuid.ValueString()
+	if uuid == "" {
+		tflog.Warn(ctx, "security group uuid is empty, so nothing to delete, skip it")
+		return
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("delete security group %s", uuid))
+	err := r.client.DeleteSecurityGroup(uuid, param.DeleteModePermissive)
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QuerySecurityGroup, uuid)
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("security group %s not found, nothing to delete", uuid))
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not delete security group", "Error: "+err.Error(),
+		)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState.
+func (r *securityGroupResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("uuid"), req, resp)
+}
+
+// updateL3Networks detaches a security group from the old port groups that are not in the new ones, and attaches it to the new port groups.
+func (r *securityGroupResource) updateL3Networks(ctx context.Context, uuid string, oldL3Networks []string, newL3Networks []string) error {
+	for _, l3NetworkUuid := range oldL3Networks {
+		if slices.Contains(newL3Networks, l3NetworkUuid) {
+			continue
+		}
+		tflog.Info(ctx, fmt.Sprintf("detach security group %s from port group %s", uuid, l3NetworkUuid))
+		if _, err := r.client.DetachSecurityGroupFromL3Network(uuid, l3NetworkUuid); err != nil {
+			return fmt.Errorf("fail to detach security group %s from port group %s, err: %v", uuid, l3NetworkUuid, err)
+		}
+	}
+
+	for _, l3NetworkUuid := range newL3Networks {
+		if slices.Contains(oldL3Networks, l3NetworkUuid) {
+			continue
+		}
+		tflog.Info(ctx, fmt.Sprintf("attach security group %s to port group %s", uuid, l3NetworkUuid))
+		if _, err := r.client.AttachSecurityGroupToL3Network(uuid, l3NetworkUuid); err != nil {
+			return fmt.Errorf("fail to attach security group %s to port group %s, err: %v", uuid, l3NetworkUuid, err)
+		}
+	}
+	return nil
+}
+
+// updateVmNics unbinds the old VM NICs that are not in the new ones from a security group, and binds the new NICs.
+func (r *securityGroupResource) updateVmNics(ctx context.Context, uuid string, oldNics []string, newNics []string) error {
+	var removed, added []string
+	for _, nicUuid := range oldNics {
+		if !slices.Contains(newNics, nicUuid) {
+			removed = append(removed, nicUuid)
+		}
+	}
+	for _, nicUuid := range newNics {
+		if !slices.Contains(oldNics, nicUuid) {
+			added = append(added, nicUuid)
+		}
+	}
+
+	if len(removed) > 0 {
+		tflog.Info(ctx, fmt.Sprintf("unbind VM NICs %v from security group %s", removed, uuid))
+		if err := r.client.DeleteVmNicFromSecurityGroup(uuid, removed); err != nil {
+			return fmt.Errorf("fail to unbind VM NICs %v from security group %s, err: %v", removed, uuid, err)
+		}
+	}
+
+	if len(added) > 0 {
+		tflog.Info(ctx, fmt.Sprintf("bind VM NICs %v to security group %s", added, uuid))
+		err := r.client.AddVmNicToSecurityGroup(uuid, param.AddVmNicToSecurityGroupParam{
+			Params: param.AddVmNicToSecurityGroupDetailParam{
+				VmNicUuids: added,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("fail to bind VM NICs %v to security group %s, err: %v", added, uuid, err)
+		}
+	}
+	return nil
+}
+
+// securityGroupRules converts the rules of a security group to their model.
+func securityGroupRules(rules []view.SecurityGroupRuleInventoryView) []securityGroupRuleModel {
+	models := make([]securityGroupRuleModel, len(rules))
+	for i, rule := range rules {
+		models[i] = securityGroupRuleModel{
+			Uuid:                    types.StringValue(rule.UUID),
+			Type:                    types.StringValue(rule.Type),
+			Protocol:                types.StringValue(rule.Protocol),
+			Action:                  types.StringValue(rule.Action),
+			State:                   types.StringValue(rule.State),
+			Priority:                types.Int64Value(int64(rule.Priority)),
+			IpVersion:               types.Int64Value(int64(rule.IpVersion)),
+			SrcIpRange:              types.StringValue(rule.SrcIpRange),
+			DstIpRange:              types.StringValue(rule.DstIpRange),
+			DstPortRange:            types.StringValue(rule.DstPortRange),
+			RemoteSecurityGroupUuid: types.StringValue(rule.RemoteSecurityGroupUuid),
+			Description:             types.StringValue(rule.Description),
+		}
+	}
+	return models
+}
+
+func readSecurityGroupState(ctx context.Context, cli *client.ZSClient, securityGroup *view.SecurityGroupInventoryView, model *securityGroupResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	qparam := param.NewQueryParam()
+	qparam.AddQ("securityGroupUuid=" + securityGroup.UUID)
+	nicRefs, err := cli.QueryVmNicInSecurityGroup(qparam)
+	if err != nil {
+		diags.AddError(
+			"Could not read VM NICs of security group", "Error: "+err.Error(),
+		)
+		return diags
+	}
+
+	model.Uuid = types.StringValue(securityGroup.UUID)
+	model.Name = types.StringValue(securityGroup.Name)
+	if securityGroup.Description != "" || !model.Description.IsNull() {
+		model.Description = types.StringValue(securityGroup.Description)
+	}
+	model.IpVersion = types.Int64Value(int64(securityGroup.IpVersion))
+	model.State = types.StringValue(securityGroup.State)
+
+	l3NetworkUuids := securityGroup.AttachedL3NetworkUuids
+	if l3NetworkUuids == nil {
+		l3NetworkUuids = []string{}
+	}
+	nicUuids := []string{}
+	for _, nicRef := range nicRefs {
+		nicUuids = append(nicUuids, nicRef.VmNicUuid)
+	}
+
+	var d diag.Diagnostics
+	model.AttachedL3NetworkUuids, d = types.SetValueFrom(ctx, types.StringType, l3NetworkUuids)
+	diags.Append(d...)
+	model.VmNicUuids, d = types.SetValueFrom(ctx, types.StringType, nicUuids)
+	diags.Append(d...)
+	// a set, so that rules reordered by ZSphere are not a change
+	model.Rules, d = types.SetValueFrom(ctx, types.ObjectType{AttrTypes: securityGroupRuleModelAttrTypes}, securityGroupRules(securityGroup.Rules))
+	diags.Append(d...)
+
+	return diags
+}
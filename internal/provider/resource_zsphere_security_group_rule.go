@@ -0,0 +1,512 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+var (
+	_ resource.Resource                = &securityGroupRuleResource{}
+	_ resource.ResourceWithConfigure   = &securityGroupRuleResource{}
+	_ resource.ResourceWithImportState = &securityGroupRuleResource{}
+)
+
+const (
+	securityGroupRuleIngress = "Ingress"
+	securityGroupRuleEgress  = "Egress"
+)
+
+type securityGroupRuleResource struct {
+	client   *client.ZSClient
+	readOnly bool
+}
+
+type securityGroupRuleResourceModel struct {
+	Uuid                    types.String `tfsdk:"uuid"`
+	SecurityGroupUuid       types.String `tfsdk:"security_group_uuid"`
+	Type                    types.String `tfsdk:"type"`
+	Protocol                types.String `tfsdk:"protocol"`
+	IpVersion               types.Int64  `tfsdk:"ip_version"`
+	SrcIpRange              types.String `tfsdk:"src_ip_range"`
+	DstIpRange              types.String `tfsdk:"dst_ip_range"`
+	DstPortRange            types.String `tfsdk:"dst_port_range"`
+	RemoteSecurityGroupUuid types.String `tfsdk:"remote_security_group_uuid"`
+	Action                  types.String `tfsdk:"action"`
+	State                   types.String `tfsdk:"state"`
+	Priority                types.Int64  `tfsdk:"priority"`
+	Description             types.String `tfsdk:"description"`
+}
+
+func SecurityGroupRuleResource() resource.Resource {
+	return &securityGroupRuleResource{}
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *securityGroupRuleResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*resourceProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *resourceProviderData, got: %T. Please report this issue to the Provider developer. ", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.client
+	r.readOnly = providerData.readOnly
+}
+
+// Metadata implements resource.Resource.
+func (r *securityGroupRuleResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_security_group_rule"
+}
+
+// Schema implements resource.Resource.
+func (r *securityGroupRuleResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "This resource allows you to manage an ingress or egress rule of a security group in ZSphere. " +
+			"Each rule is its own resource, so rules reordered by ZSphere are not a change.",
+		Attributes: map[string]schema.Attribute{
+			"uuid": schema.StringAttribute{
+				Computed:    true,
+				Description: "The unique identifier of the rule. Automatically generated by ZSphere.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"security_group_uuid": schema.StringAttribute{
+				Required:    true,
+				Description: "The UUID of the security group of the rule. Changing this forces a new rule to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"type": schema.StringAttribute{
+				Required:    true,
+				Description: "The direction of the rule, 'Ingress' or 'Egress'. Changing this forces a new rule to be created.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(securityGroupRuleIngress, securityGroupRuleEgress),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"protocol": schema.StringAttribute{
+				Required:    true,
+				Description: "The protocol of the rule: 'TCP', 'UDP', 'ICMP' or 'ALL'. Changing this forces a new rule to be created.",
+				Validators: []validator.String{
+					stringvalidator.OneOf("TCP", "UDP", "ICMP", "ALL"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"ip_version": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(4),
+				Description: "The IP version of the rule, 4 or 6. Defaults to 4. Changing this forces a new rule to be created.",
+				Validators: []validator.Int64{
+					int64validator.OneOf(4, 6),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"src_ip_range": schema.StringAttribute{
+				Optional: true,
+				Description: "The source IPs of an ingress rule: an IP, a CIDR or a range such as '10.0.0.1-10.0.0.9', several separated by commas. " +
+					"Changing this forces a new rule to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"dst_ip_range": schema.StringAttribute{
+				Optional:    true,
+				Description: "The destination IPs of an egress rule, in the form of `src_ip_range`. Changing this forces a new rule to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"dst_port_range": schema.StringAttribute{
+				Optional: true,
+				Description: "The destination ports of a TCP or UDP rule: a port or a range such as '8000-8080', several separated by commas. " +
+					"All ports if not set. Changing this forces a new rule to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"remote_security_group_uuid": schema.StringAttribute{
+				Optional: true,
+				Description: "The UUID of a security group whose members the rule applies to, instead of `src_ip_range` or `dst_ip_range`. " +
+					"Changing this forces a new rule to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"action": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("ACCEPT"),
+				Description: "The action of the rule, 'ACCEPT' or 'DROP'. Defaults to 'ACCEPT'.",
+				Validators: []validator.String{
+					stringvalidator.OneOf("ACCEPT", "DROP"),
+				},
+			},
+			"state": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("Enabled"),
+				Description: "The state of the rule, 'Enabled' or 'Disabled'. Defaults to 'Enabled'.",
+				Validators: []validator.String{
+					stringvalidator.OneOf("Enabled", "Disabled"),
+				},
+			},
+			"priority": schema.Int64Attribute{
+				Optional: true,
+				Description: "The priority of the rule among the rules of the same direction, 1 is evaluated first. " +
+					"If not set, the rule is appended, and its priority is not tracked since it changes as other rules are added or deleted.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"description": schema.StringAttribute{
+				Optional:    true,
+				Description: "A description of the rule.",
+			},
+		},
+	}
+}
+
+// Create implements resource.Resource.
+func (r *securityGroupRuleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_security_group_rule", "create") {
+		return
+	}
+
+	var plan securityGroupRuleResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := checkSecurityGroupRuleParams(plan); err != nil {
+		resp.Diagnostics.AddError("Params Error", fmt.Sprintf("invalid security group rule param, err: %v", err))
+		return
+	}
+
+	securityGroupUuid := plan.SecurityGroupUuid.ValueString()
+
+	// the new rule is told apart from the others by comparing the rules before and after adding it,
+	// so no other rule may be added to the security group by the provider in between
+	unlock := lockSecurityGroup(securityGroupUuid)
+	defer unlock()
+
+	securityGroup, err := r.client.GetSecurityGroup(securityGroupUuid)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not read security group", "Error: "+err.Error(),
+		)
+		return
+	}
+	existing := make(map[string]bool)
+	for _, rule := range securityGroup.Rules {
+		existing[rule.UUID] = true
+	}
+
+	// -1 appends the rule after the other rules of its direction
+	priority := -1
+	if !plan.Priority.IsNull() {
+		priority = int(plan.Priority.ValueInt64())
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("add %s rule to security group %s", plan.Type.ValueString(), securityGroupUuid))
+	securityGroup, err = r.client.AddSecurityGroupRule(securityGroupUuid, param.AddSecurityGroupRuleParam{
+		Params: param.AddSecurityGroupRuleDetailParam{
+			Rules: []param.SecurityGroupRuleAO{{
+				Type:                    plan.Type.ValueString(),
+				State:                   plan.State.ValueString(),
+				Description:             plan.Description.ValueString(),
+				RemoteSecurityGroupUuid: plan.RemoteSecurityGroupUuid.ValueString(),
+				IpVersion:               int(plan.IpVersion.ValueInt64()),
+				Protocol:                plan.Protocol.ValueString(),
+				SrcIpRange:              plan.SrcIpRange.ValueString(),
+				DstIpRange:              plan.DstIpRange.ValueString(),
+				DstPortRange:            plan.DstPortRange.ValueString(),
+				Action:                  plan.Action.ValueString(),
+			}},
+			Priority: priority,
+		},
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not add security group rule",
+			fmt.Sprintf("fail to add rule to security group %s, err: %v", securityGroupUuid, err),
+		)
+		return
+	}
+
+	// the new rule is the one with the planned settings the security group did not have before
+	var added []*view.SecurityGroupRuleInventoryView
+	for i := range securityGroup.Rules {
+		if !existing[securityGroup.Rules[i].UUID] && isPlannedRule(securityGroup.Rules[i], plan) {
+			added = append(added, &securityGroup.Rules[i])
+		}
+	}
+	if len(added) != 1 {
+		uuids := make([]string, 0, len(added))
+		for _, rule := range added {
+			uuids = append(uuids, rule.UUID)
+		}
+		resp.Diagnostics.AddError(
+			"Could not add security group rule",
+			fmt.Sprintf("the rule added to security group %s cannot be told apart, %d new rules have its settings: %v. "+
+				"The rule may have been added to the security group in parallel outside of this configuration; "+
+				"import the rule that belongs to this resource.", securityGroupUuid, len(added), uuids),
+		)
+		return
+	}
+	rule := added[0]
+
+	readSecurityGroupRuleState(rule, &plan)
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read implements resource.Resource.
+func (r *securityGroupRuleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state securityGroupRuleResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rule, err := r.client.GetSecurityGroupRule(state.Uuid.ValueString())
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QuerySecurityGroupRule, state.Uuid.ValueString())
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("security group rule %s not found, remove it from state", state.Uuid.ValueString()))
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not read security group rule", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	readSecurityGroupRuleState(rule, &state)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *securityGroupRuleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_security_group_rule", "update") {
+		return
+	}
+
+	var plan securityGroupRuleResourceModel
+	var state securityGroupRuleResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid := state.Uuid.ValueString()
+	plan.Uuid = state.Uuid
+
+	var priority *int
+	if !plan.Priority.IsNull() && !plan.Priority.Equal(state.Priority) {
+		value := int(plan.Priority.ValueInt64())
+		priority = &value
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("change security group rule %s", uuid))
+	rule, err := r.client.ChangeSecurityGroupRule(uuid, param.ChangeSecurityGroupRuleParam{
+		ChangeSecurityGroupRule: param.ChangeSecurityGroupRuleDetailParam{
+			Description: descriptionParam(plan.Description),
+			Action:      plan.Action.ValueStringPointer(),
+			State:       plan.State.ValueStringPointer(),
+			Priority:    priority,
+		},
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not change security group rule",
+			fmt.Sprintf("fail to change security group rule %s, err: %v", uuid, err),
+		)
+		return
+	}
+
+	readSecurityGroupRuleState(rule, &plan)
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete implements resource.Resource.
+func (r *securityGroupRuleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_security_group_rule", "delete") {
+		return
+	}
+
+	var state securityGroupRuleResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid := state.Uuid.ValueString()
+	if uuid == "" {
+		tflog.Warn(ctx, "security group rule uuid is empty, so nothing to delete, skip it")
+		return
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("delete security group rule %s", uuid))
+	err := r.client.DeleteSecurityGroupRule([]string{uuid}, param.DeleteModePermissive)
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QuerySecurityGroupRule, uuid)
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("security group rule %s not found, nothing to delete", uuid))
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not delete security group rule", "Error: "+err.Error(),
+		)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState.
+func (r *securityGroupRuleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("uuid"), req, resp)
+}
+
+// securityGroupLocks are the locks of the security groups the provider adds rules to, by security group uuid.
+var securityGroupLocks sync.Map
+
+// lockSecurityGroup serializes the rule changes of a security group and returns the function to unlock it.
+func lockSecurityGroup(uuid string) func() {
+	lock, _ := securityGroupLocks.LoadOrStore(uuid, &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// isPlannedRule reports whether a rule of a security group has the settings of a planned rule. Protocols, actions
+// and IP ranges are compared ignoring case, as the API may report them in another case than they were given.
+func isPlannedRule(rule view.SecurityGroupRuleInventoryView, plan securityGroupRuleResourceModel) bool {
+	return rule.Type == plan.Type.ValueString() &&
+		strings.EqualFold(rule.Protocol, plan.Protocol.ValueString()) &&
+		int64(rule.IpVersion) == plan.IpVersion.ValueInt64() &&
+		strings.EqualFold(rule.SrcIpRange, plan.SrcIpRange.ValueString()) &&
+		strings.EqualFold(rule.DstIpRange, plan.DstIpRange.ValueString()) &&
+		rule.DstPortRange == plan.DstPortRange.ValueString() &&
+		rule.RemoteSecurityGroupUuid == plan.RemoteSecurityGroupUuid.ValueString() &&
+		strings.EqualFold(rule.Action, plan.Action.ValueString()) &&
+		rule.Description == plan.Description.ValueString()
+}
+
+// checkSecurityGroupRuleParams checks the IP ranges and ports a rule can have for its direction and protocol.
+func checkSecurityGroupRuleParams(plan securityGroupRuleResourceModel) error {
+	ruleType := plan.Type.ValueString()
+	if ruleType == securityGroupRuleIngress && plan.DstIpRange.ValueString() != "" {
+		return fmt.Errorf("dst_ip_range is only supported by %s rules, use src_ip_range", securityGroupRuleEgress)
+	}
+	if ruleType == securityGroupRuleEgress && plan.SrcIpRange.ValueString() != "" {
+		return fmt.Errorf("src_ip_range is only supported by %s rules, use dst_ip_range", securityGroupRuleIngress)
+	}
+	if plan.RemoteSecurityGroupUuid.ValueString() != "" && (plan.SrcIpRange.ValueString() != "" || plan.DstIpRange.ValueString() != "") {
+		return fmt.Errorf("remote_security_group_uuid cannot be set with src_ip_range or dst_ip_range")
+	}
+
+	protocol := plan.Protocol.ValueString()
+	if plan.DstPortRange.ValueString() != "" && protocol != "TCP" && protocol != "UDP" {
+		return fmt.Errorf("dst_port_range is only supported by TCP and UDP rules, got %s", protocol)
+	}
+	return nil
+}
+
+// readSecurityGroupRuleState reads a rule into the model. Protocols, actions and IP ranges that only differ in case
+// from the values in the model keep the values of the model, so that the state matches the configuration.
+func readSecurityGroupRuleState(rule *view.SecurityGroupRuleInventoryView, model *securityGroupRuleResourceModel) {
+	model.Uuid = types.StringValue(rule.UUID)
+	model.SecurityGroupUuid = types.StringValue(rule.SecurityGroupUuid)
+	model.Type = types.StringValue(rule.Type)
+	model.Protocol = stringValueIgnoringCase(model.Protocol, rule.Protocol)
+	model.IpVersion = types.Int64Value(int64(rule.IpVersion))
+	model.Action = stringValueIgnoringCase(model.Action, rule.Action)
+	model.State = types.StringValue(rule.State)
+
+	optional := []struct {
+		attr     *types.String
+		value    string
+		foldCase bool
+	}{
+		{&model.SrcIpRange, rule.SrcIpRange, true},
+		{&model.DstIpRange, rule.DstIpRange, true},
+		{&model.DstPortRange, rule.DstPortRange, false},
+		{&model.RemoteSecurityGroupUuid, rule.RemoteSecurityGroupUuid, false},
+		{&model.Description, rule.Description, false},
+	}
+	for _, o := range optional {
+		if o.value == "" && o.attr.IsNull() {
+			continue
+		}
+		if o.foldCase {
+			*o.attr = stringValueIgnoringCase(*o.attr, o.value)
+		} else {
+			*o.attr = types.StringValue(o.value)
+		}
+	}
+
+	// the priorities of the rules are renumbered as rules are added and deleted, so only a configured one is tracked
+	if !model.Priority.IsNull() {
+		model.Priority = types.Int64Value(int64(rule.Priority))
+	}
+}
+
+// stringValueIgnoringCase returns the value read from the API, or the current value if the two only differ in case.
+func stringValueIgnoringCase(current types.String, value string) types.String {
+	if !current.IsNull() && !current.IsUnknown() && strings.EqualFold(current.ValueString(), value) {
+		return current
+	}
+	return types.StringValue(value)
+}
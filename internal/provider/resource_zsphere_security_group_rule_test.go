@@ -0,0 +1,109 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+func TestIsPlannedRule(t *testing.T) {
+	plan := securityGroupRuleResourceModel{
+		Type:                    types.StringValue(securityGroupRuleIngress),
+		Protocol:                types.StringValue("TCP"),
+		IpVersion:               types.Int64Value(4),
+		SrcIpRange:              types.StringValue("10.0.0.0/24"),
+		DstIpRange:              types.StringNull(),
+		DstPortRange:            types.StringValue("22"),
+		RemoteSecurityGroupUuid: types.StringNull(),
+		Action:                  types.StringValue("ACCEPT"),
+		Description:             types.StringNull(),
+	}
+	planned := view.SecurityGroupRuleInventoryView{
+		Type:         securityGroupRuleIngress,
+		Protocol:     "TCP",
+		IpVersion:    4,
+		SrcIpRange:   "10.0.0.0/24",
+		DstPortRange: "22",
+		Action:       "ACCEPT",
+	}
+
+	tests := []struct {
+		name   string
+		change func(rule *view.SecurityGroupRuleInventoryView)
+		want   bool
+	}{
+		{"same settings", func(*view.SecurityGroupRuleInventoryView) {}, true},
+		{"protocol in lower case", func(rule *view.SecurityGroupRuleInventoryView) { rule.Protocol = "tcp" }, true},
+		{"other direction", func(rule *view.SecurityGroupRuleInventoryView) { rule.Type = securityGroupRuleEgress }, false},
+		{"other protocol", func(rule *view.SecurityGroupRuleInventoryView) { rule.Protocol = "UDP" }, false},
+		{"other ip version", func(rule *view.SecurityGroupRuleInventoryView) { rule.IpVersion = 6 }, false},
+		{"other source", func(rule *view.SecurityGroupRuleInventoryView) { rule.SrcIpRange = "10.0.1.0/24" }, false},
+		{"other port range", func(rule *view.SecurityGroupRuleInventoryView) { rule.DstPortRange = "22-23" }, false},
+		{"remote security group", func(rule *view.SecurityGroupRuleInventoryView) { rule.RemoteSecurityGroupUuid = "sg" }, false},
+		{"other action", func(rule *view.SecurityGroupRuleInventoryView) { rule.Action = "DROP" }, false},
+		{"other description", func(rule *view.SecurityGroupRuleInventoryView) { rule.Description = "ssh" }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := planned
+			tt.change(&rule)
+			if got := isPlannedRule(rule, plan); got != tt.want {
+				t.Errorf("isPlannedRule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadSecurityGroupRuleStateKeepsConfiguredCase(t *testing.T) {
+	model := securityGroupRuleResourceModel{
+		Type:                    types.StringValue(securityGroupRuleEgress),
+		Protocol:                types.StringValue("TCP"),
+		IpVersion:               types.Int64Value(6),
+		SrcIpRange:              types.StringNull(),
+		DstIpRange:              types.StringValue("FD00::/64"),
+		DstPortRange:            types.StringNull(),
+		RemoteSecurityGroupUuid: types.StringNull(),
+		Action:                  types.StringValue("ACCEPT"),
+		Description:             types.StringValue("Web"),
+		Priority:                types.Int64Null(),
+	}
+	rule := view.SecurityGroupRuleInventoryView{
+		Type:        securityGroupRuleEgress,
+		Protocol:    "tcp",
+		IpVersion:   6,
+		DstIpRange:  "fd00::/64",
+		Action:      "accept",
+		Description: "web",
+		State:       "Enabled",
+	}
+
+	readSecurityGroupRuleState(&rule, &model)
+
+	tests := []struct {
+		name string
+		got  types.String
+		want types.String
+	}{
+		{"protocol", model.Protocol, types.StringValue("TCP")},
+		{"action", model.Action, types.StringValue("ACCEPT")},
+		{"dst_ip_range", model.DstIpRange, types.StringValue("FD00::/64")},
+		{"src_ip_range", model.SrcIpRange, types.StringNull()},
+		{"description", model.Description, types.StringValue("web")},
+		{"state", model.State, types.StringValue("Enabled")},
+	}
+	for _, tt := range tests {
+		if !tt.got.Equal(tt.want) {
+			t.Errorf("%s = %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+
+	rule.Protocol = "udp"
+	readSecurityGroupRuleState(&rule, &model)
+	if want := types.StringValue("udp"); !model.Protocol.Equal(want) {
+		t.Errorf("protocol after a change = %s, want %s", model.Protocol, want)
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+var (
+	_ resource.Resource                = &vipResource{}
+	_ resource.ResourceWithConfigure   = &vipResource{}
+	_ resource.ResourceWithImportState = &vipResource{}
+)
+
+type vipResource struct {
+	client   *client.ZSClient
+	readOnly bool
+}
+
+type vipResourceModel struct {
+	Uuid          types.String `tfsdk:"uuid"`
+	Name          types.String `tfsdk:"name"`
+	Description   types.String `tfsdk:"description"`
+	L3NetworkUuid types.String `tfsdk:"l3_network_uuid"`
+	Ip            types.String `tfsdk:"ip"`
+	Netmask       types.String `tfsdk:"netmask"`
+	Gateway       types.String `tfsdk:"gateway"`
+	State         types.String `tfsdk:"state"`
+	UseFor        types.String `tfsdk:"use_for"`
+}
+
+func VipResource() resource.Resource {
+	return &vipResource{}
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *vipResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*resourceProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *resourceProviderData, got: %T. Please report this issue to the Provider developer. ", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.client
+	r.readOnly = providerData.readOnly
+}
+
+// Metadata implements resource.Resource.
+func (r *vipResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_vip"
+}
+
+// Schema implements resource.Resource.
+func (r *vipResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "This resource allows you to manage virtual IPs (VIPs) in ZSphere. " +
+			"A VIP reserves an IP of a port group, usually a public one, that network services such as `zsphere_eip` are bound to. " +
+			"It is kept when the instances using it are replaced.",
+		Attributes: map[string]schema.Attribute{
+			"uuid": schema.StringAttribute{
+				Computed:    true,
+				Description: "The unique identifier of the VIP. Automatically generated by ZSphere.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the VIP.",
+			},
+			"description": schema.StringAttribute{
+				Optional:    true,
+				Description: "A description of the VIP.",
+			},
+			"l3_network_uuid": schema.StringAttribute{
+				Required:    true,
+				Description: "The UUID of the port group the IP of the VIP is allocated from. Changing this forces a new VIP to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"ip": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Description: "The IP address of the VIP. It must be a free IP of the IP ranges of the port group. " +
+					"If not set, ZSphere allocates a free IP. Changing this forces a new VIP to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"netmask": schema.StringAttribute{
+				Computed:    true,
+				Description: "The netmask of the VIP.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"gateway": schema.StringAttribute{
+				Computed:    true,
+				Description: "The gateway of the VIP.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"state": schema.StringAttribute{
+				Computed:    true,
+				Description: "The state of the VIP, such as 'Enabled'.",
+			},
+			"use_for": schema.StringAttribute{
+				Computed:    true,
+				Description: "The network services using the VIP, such as 'Eip'.",
+			},
+		},
+	}
+}
+
+// Create implements resource.Resource.
+func (r *vipResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_vip", "create") {
+		return
+	}
+
+	var plan vipResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	requiredIp := ""
+	if !plan.Ip.IsUnknown() {
+		requiredIp = plan.Ip.ValueString()
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("create vip %s", plan.Name.ValueString()))
+	vip, err := r.client.CreateVip(param.CreateVipParam{
+		Params: param.CreateVipDetailParam{
+			Name:          plan.Name.ValueString(),
+			Description:   plan.Description.ValueString(),
+			L3NetworkUuid: plan.L3NetworkUuid.ValueString(),
+			RequiredIp:    requiredIp,
+		},
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not create vip", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	readVipState(vip, &plan)
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read implements resource.Resource.
+func (r *vipResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state vipResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	vip, err := r.client.GetVip(state.Uuid.ValueString())
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryVip, state.Uuid.ValueString())
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("vip %s not found, remove it from state", state.Uuid.ValueString()))
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not read vip", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	readVipState(vip, &state)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *vipResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_vip", "update") {
+		return
+	}
+
+	var plan vipResourceModel
+	var state vipResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid := state.Uuid.ValueString()
+
+	tflog.Info(ctx, fmt.Sprintf("update vip %s", uuid))
+	vip, err := r.client.UpdateVip(uuid, param.UpdateVipParam{
+		UpdateVip: param.UpdateVipDetailParam{
+			Name:        plan.Name.ValueString(),
+			Description: descriptionParam(plan.Description),
+		},
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not update vip",
+			fmt.Sprintf("fail to update vip %s, err: %v", uuid, err),
+		)
+		return
+	}
+
+	readVipState(vip, &plan)
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete implements resource.Resource.
+func (r *vipResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_vip", "delete") {
+		return
+	}
+
+	var state vipResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid := state.Uuid.ValueString()
+	if uuid == "" {
+		tflog.Warn(ctx, "vip uuid is empty, so nothing to delete, skip it")
+		return
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("delete vip %s", uuid))
+	err := r.client.DeleteVip(uuid, param.DeleteModePermissive)
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryVip, uuid)
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("vip %s not found, nothing to delete", uuid))
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not delete vip", "Error: "+err.Error(),
+		)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState.
+func (r *vipResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("uuid"), req, resp)
+}
+
+func readVipState(vip *view.VipInventoryView, model *vipResourceModel) {
+	model.Uuid = types.StringValue(vip.UUID)
+	model.Name = types.StringValue(vip.Name)
+	if vip.Description != "" || !model.Description.IsNull() {
+		model.Description = types.StringValue(vip.Description)
+	}
+	model.L3NetworkUuid = types.StringValue(vip.L3NetworkUuid)
+	model.Ip = types.StringValue(vip.Ip)
+	model.Netmask = types.StringValue(vip.Netmask)
+	model.Gateway = types.StringValue(vip.Gateway)
+	model.State = types.StringValue(vip.State)
+	model.UseFor = types.StringValue(vip.UseFor)
+}
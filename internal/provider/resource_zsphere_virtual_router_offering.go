@@ -0,0 +1,348 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-provider-zsphere/internal/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+var (
+	_ resource.Resource                = &virtualRouterOfferingResource{}
+	_ resource.ResourceWithConfigure   = &virtualRouterOfferingResource{}
+	_ resource.ResourceWithImportState = &virtualRouterOfferingResource{}
+)
+
+type virtualRouterOfferingResource struct {
+	client   *client.ZSClient
+	readOnly bool
+}
+
+type virtualRouterOfferingResourceModel struct {
+	Uuid                  types.String `tfsdk:"uuid"`
+	Name                  types.String `tfsdk:"name"`
+	Description           types.String `tfsdk:"description"`
+	ZoneUuid              types.String `tfsdk:"zone_uuid"`
+	CpuNum                types.Int64  `tfsdk:"cpu_num"`
+	MemorySize            types.Int64  `tfsdk:"memory_size"`
+	ImageUuid             types.String `tfsdk:"image_uuid"`
+	ManagementNetworkUuid types.String `tfsdk:"management_network_uuid"`
+	PublicNetworkUuid     types.String `tfsdk:"public_network_uuid"`
+	IsDefault             types.Bool   `tfsdk:"is_default"`
+	State                 types.String `tfsdk:"state"`
+}
+
+func VirtualRouterOfferingResource() resource.Resource {
+	return &virtualRouterOfferingResource{}
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *virtualRouterOfferingResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*resourceProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *resourceProviderData, got: %T. Please report this issue to the Provider developer. ", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.client
+	r.readOnly = providerData.readOnly
+}
+
+// Metadata implements resource.Resource.
+func (r *virtualRouterOfferingResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_virtual_router_offering"
+}
+
+// Schema implements resource.Resource.
+func (r *virtualRouterOfferingResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "This resource allows you to manage virtual router offerings in ZSphere. " +
+			"A virtual router offering defines the CPU, memory, image and networks of the virtual routers ZSphere creates " +
+			"to provide network services, such as SNAT and EIP, to VPC networks.",
+		Attributes: map[string]schema.Attribute{
+			"uuid": schema.StringAttribute{
+				Computed:    true,
+				Description: "The unique identifier of the virtual router offering. Automatically generated by ZSphere.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the virtual router offering.",
+			},
+			"description": schema.StringAttribute{
+				Optional:    true,
+				Description: "A description of the virtual router offering.",
+			},
+			"zone_uuid": schema.StringAttribute{
+				Required:    true,
+				Description: "The UUID of the zone of the virtual router offering. Changing this forces a new offering to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"cpu_num": schema.Int64Attribute{
+				Required:    true,
+				Description: "The number of CPUs of the virtual routers. Changing this forces a new offering to be created.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"memory_size": schema.Int64Attribute{
+				Required:    true,
+				Description: "The memory size of the virtual routers in megabytes (MB). Changing this forces a new offering to be created.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"image_uuid": schema.StringAttribute{
+				Required:    true,
+				Description: "The UUID of the virtual router image the virtual routers are created from. Changing it only affects virtual routers created afterwards.",
+			},
+			"management_network_uuid": schema.StringAttribute{
+				Required: true,
+				Description: "The UUID of the port group the management NIC of the virtual routers is connected to. " +
+					"Changing this forces a new offering to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"public_network_uuid": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Description: "The UUID of the port group the public NIC of the virtual routers is connected to. " +
+					"Defaults to `management_network_uuid`. Changing this forces a new offering to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"is_default": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				Description: "Whether the offering is the default virtual router offering of its zone. If not set, a new offering " +
+					"is not the default one and an imported offering keeps its flag. " +
+					"Making an offering the default one unsets the flag of the previous default offering.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"state": schema.StringAttribute{
+				Computed:    true,
+				Description: "The state of the virtual router offering, such as 'Enabled'.",
+			},
+		},
+	}
+}
+
+// Create implements resource.Resource.
+func (r *virtualRouterOfferingResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_virtual_router_offering", "create") {
+		return
+	}
+
+	var plan virtualRouterOfferingResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	publicNetworkUuid := plan.ManagementNetworkUuid.ValueString()
+	if !plan.PublicNetworkUuid.IsUnknown() && !plan.PublicNetworkUuid.IsNull() {
+		publicNetworkUuid = plan.PublicNetworkUuid.ValueString()
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("create virtual router offering %s", plan.Name.ValueString()))
+	offering, err := r.client.CreateVirtualRouterOffering(param.CreateVirtualRouterOfferingParam{
+		Params: param.CreateVirtualRouterOfferingDetailParam{
+			Name:                  plan.Name.ValueString(),
+			Description:           plan.Description.ValueString(),
+			ZoneUuid:              plan.ZoneUuid.ValueString(),
+			ManagementNetworkUuid: plan.ManagementNetworkUuid.ValueString(),
+			PublicNetworkUuid:     publicNetworkUuid,
+			ImageUuid:             plan.ImageUuid.ValueString(),
+			IsDefault:             plan.IsDefault.ValueBool(),
+			CpuNum:                int(plan.CpuNum.ValueInt64()),
+			MemorySize:            utils.MBToBytes(plan.MemorySize.ValueInt64()),
+			Type:                  "VirtualRouter",
+		},
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not create virtual router offering", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	readVirtualRouterOfferingState(offering, &plan)
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read implements resource.Resource.
+func (r *virtualRouterOfferingResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state virtualRouterOfferingResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	offering, err := r.client.GetVirtualRouterOffering(state.Uuid.ValueString())
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryVirtualRouterOffering, state.Uuid.ValueString())
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("virtual router offering %s not found, remove it from state", state.Uuid.ValueString()))
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not read virtual router offering", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	readVirtualRouterOfferingState(offering, &state)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *virtualRouterOfferingResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_virtual_router_offering", "update") {
+		return
+	}
+
+	var plan virtualRouterOfferingResourceModel
+	var state virtualRouterOfferingResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid := state.Uuid.ValueString()
+
+	// an unset is_default keeps the flag of the offering
+	var isDefault *bool
+	if !plan.IsDefault.IsUnknown() && !plan.IsDefault.Equal(state.IsDefault) {
+		isDefault = plan.IsDefault.ValueBoolPointer()
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("update virtual router offering %s", uuid))
+	offering, err := r.client.UpdateVirtualRouterOffering(uuid, param.UpdateVirtualRouterOfferingParam{
+		UpdateVirtualRouterOffering: param.UpdateVirtualRouterOfferingDetailParam{
+			Name:        plan.Name.ValueString(),
+			Description: descriptionParam(plan.Description),
+			IsDefault:   isDefault,
+			ImageUuid:   plan.ImageUuid.ValueStringPointer(),
+		},
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not update virtual router offering",
+			fmt.Sprintf("fail to update virtual router offering %s, err: %v", uuid, err),
+		)
+		return
+	}
+
+	readVirtualRouterOfferingState(offering, &plan)
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete implements resource.Resource.
+func (r *virtualRouterOfferingResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_virtual_router_offering", "delete") {
+		return
+	}
+
+	var state virtualRouterOfferingResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid := state.Uuid.ValueString()
+	if uuid == "" {
+		tflog.Warn(ctx, "virtual router offering uuid is empty, so nothing to delete, skip it")
+		return
+	}
+
+	// a virtual router offering is an instance offering, it is deleted like one
+	tflog.Info(ctx, fmt.Sprintf("delete virtual router offering %s", uuid))
+	err := r.client.DeleteInstanceOffering(uuid, param.DeleteModePermissive)
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryVirtualRouterOffering, uuid)
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("virtual router offering %s not found, nothing to delete", uuid))
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not delete virtual router offering", "Error: "+err.Error(),
+		)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState.
+func (r *virtualRouterOfferingResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("uuid"), req, resp)
+}
+
+func readVirtualRouterOfferingState(offering *view.VirtualRouterOfferingInventoryView, model *virtualRouterOfferingResourceModel) {
+	model.Uuid = types.StringValue(offering.UUID)
+	model.Name = types.StringValue(offering.Name)
+	if offering.Description != "" || !model.Description.IsNull() {
+		model.Description = types.StringValue(offering.Description)
+	}
+	model.ZoneUuid = types.StringValue(offering.ZoneUuid)
+	model.CpuNum = types.Int64Value(int64(offering.CpuNum))
+	model.MemorySize = types.Int64Value(utils.BytesToMB(offering.MemorySize))
+	model.ImageUuid = types.StringValue(offering.ImageUuid)
+	model.ManagementNetworkUuid = types.StringValue(offering.ManagementNetworkUuid)
+	model.PublicNetworkUuid = types.StringValue(offering.PublicNetworkUuid)
+	model.IsDefault = types.BoolValue(offering.IsDefault)
+	model.State = types.StringValue(offering.State)
+}
@@ -0,0 +1,538 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"terraform-provider-zsphere/internal/utils"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+var (
+	_ resource.Resource                = &volumeResource{}
+	_ resource.ResourceWithConfigure   = &volumeResource{}
+	_ resource.ResourceWithImportState = &volumeResource{}
+)
+
+const (
+	volumeStatusDeleted = "Deleted"
+
+	defaultVolumeCreateTimeout = 10 * time.Minute
+	defaultVolumeUpdateTimeout = 30 * time.Minute
+	defaultVolumeDeleteTimeout = 10 * time.Minute
+)
+
+type volumeResource struct {
+	client   *client.ZSClient
+	readOnly bool
+}
+
+type volumeResourceModel struct {
+	Uuid               types.String   `tfsdk:"uuid"`
+	Name               types.String   `tfsdk:"name"`
+	Description        types.String   `tfsdk:"description"`
+	Size               types.Int64    `tfsdk:"size"`
+	PrimaryStorageUuid types.String   `tfsdk:"primary_storage_uuid"`
+	CephPoolName       types.String   `tfsdk:"ceph_pool_name"`
+	VirtioSCSI         types.Bool     `tfsdk:"virtio_scsi"`
+	Format             types.String   `tfsdk:"format"`
+	Expunge            types.Bool     `tfsdk:"expunge"`
+	Timeouts           timeouts.Value `tfsdk:"timeouts"`
+}
+
+func VolumeResource() resource.Resource {
+	return &volumeResource{}
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *volumeResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*resourceProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *resourceProviderData, got: %T. Please report this issue to the Provider developer. ", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.client
+	r.readOnly = providerData.readOnly
+}
+
+// Metadata implements resource.Resource.
+func (r *volumeResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_volume"
+}
+
+// Schema implements resource.Resource.
+func (r *volumeResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "This resource allows you to manage standalone data volumes in ZSphere. " +
+			"Unlike `data_disks` of `zsphere_instance`, a standalone volume is not deleted with a virtual machine, " +
+			"and can be attached to an instance with `zsphere_volume_attachment`.",
+		Attributes: map[string]schema.Attribute{
+			"uuid": schema.StringAttribute{
+				Computed:    true,
+				Description: "The unique identifier of the volume. Automatically generated by ZSphere.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the volume.",
+			},
+			"description": schema.StringAttribute{
+				Optional:    true,
+				Description: "A description of the volume.",
+			},
+			"size": schema.Int64Attribute{
+				Required:    true,
+				Description: "The size of the volume in gigabytes (GB). The volume can be expanded in place, shrinking is not supported.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					disallowShrink(),
+				},
+			},
+			"primary_storage_uuid": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Description: "The UUID of the primary storage where the volume is created. " +
+					"If not set, the volume is instantiated on the primary storage of the instance it is first attached to. " +
+					"Changing this migrates the volume to the new primary storage.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"ceph_pool_name": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The Ceph pool of the volume, when the primary storage is Ceph. Changing this forces a new volume to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"virtio_scsi": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether the volume uses the virtio-scsi bus. Changing this forces a new volume to be created.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"format": schema.StringAttribute{
+				Computed:    true,
+				Description: "The format of the volume, such as 'qcow2' or 'raw'.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"expunge": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Indicates if the volume should be expunged after deletion. It is not stored on the platform, so it shows as an in-place change after import when set.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+// Create implements resource.Resource.
+func (r *volumeResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_volume", "create") {
+		return
+	}
+
+	var plan volumeResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultVolumeCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	disk := diskModel{
+		PrimaryStorageUuid: plan.PrimaryStorageUuid,
+		CephPoolName:       plan.CephPoolName,
+	}
+	err := isDiskParamValid(r.client, disk)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Params Error",
+			fmt.Sprintf("invalid volume param, err: %v", err),
+		)
+		return
+	}
+
+	var systemTags []string
+	if !plan.CephPoolName.IsUnknown() && plan.CephPoolName.ValueString() != "" {
+		systemTags = append(systemTags, fmt.Sprintf("ceph::pool::%s", plan.CephPoolName.ValueString()))
+	}
+	if plan.VirtioSCSI.ValueBool() {
+		systemTags = append(systemTags, volumeVirtioSCSISystemTag)
+	}
+
+	primaryStorageUuid := ""
+	if !plan.PrimaryStorageUuid.IsUnknown() {
+		primaryStorageUuid = plan.PrimaryStorageUuid.ValueString()
+	}
+
+	resourceUuid, err := newResourceUuid()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not create data volume",
+			fmt.Sprintf("failed to generate volume uuid, err: %v", err),
+		)
+		return
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("create data volume %s", plan.Name.ValueString()))
+	volume, err := runJob(ctx, job{
+		name:         "create data volume",
+		resourceUuid: resourceUuid,
+	}, func() (*view.VolumeView, error) {
+		return r.client.CreateDataVolume(param.CreateDataVolumeParam{
+			BaseParam: param.BaseParam{
+				SystemTags: systemTags,
+			},
+			Params: param.CreateDataVolumeDetailParam{
+				Name:               plan.Name.ValueString(),
+				Description:        plan.Description.ValueString(),
+				DiskSize:           utils.GBToBytes(plan.Size.ValueInt64()),
+				PrimaryStorageUuid: primaryStorageUuid,
+				ResourceUuid:       resourceUuid,
+			},
+		})
+	})
+	if err != nil {
+		if errors.Is(err, errJobNotFinished) {
+			keepPartiallyCreated(ctx, resp, resourceUuid, "Could not create data volume", "Error: "+err.Error())
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Could not create data volume", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = readVolumeState(ctx, r.client, volume, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read implements resource.Resource.
+func (r *volumeResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state volumeResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	volume, err := r.client.GetVolume(state.Uuid.ValueString())
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryVolume, state.Uuid.ValueString())
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("volume %s not found, remove it from state", state.Uuid.ValueString()))
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not read data volume", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	if volume.Status == volumeStatusDeleted {
+		tflog.Warn(ctx, fmt.Sprintf("volume %s has been deleted, remove it from state", volume.UUID))
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	diags = readVolumeState(ctx, r.client, volume, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *volumeResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_volume", "update") {
+		return
+	}
+
+	var plan volumeResourceModel
+	var state volumeResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultVolumeUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	uuid := state.Uuid.ValueString()
+	plan.Uuid = state.Uuid
+
+	if plan.Size.ValueInt64() < state.Size.ValueInt64() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("size"),
+			"Params Error",
+			fmt.Sprintf("volume %s cannot be shrunk from %d GB to %d GB", uuid, state.Size.ValueInt64(), plan.Size.ValueInt64()),
+		)
+		return
+	}
+
+	if !plan.Name.Equal(state.Name) || !plan.Description.Equal(state.Description) {
+		tflog.Info(ctx, fmt.Sprintf("update data volume %s", uuid))
+		_, err := r.client.UpdateVolume(uuid, param.UpdateVolumeParam{
+			UpdateVolume: param.UpdateVolumeDetailParam{
+				Name:        plan.Name.ValueString(),
+				Description: descriptionParam(plan.Description),
+			},
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Could not update data volume",
+				fmt.Sprintf("fail to update volume %s, err: %v", uuid, err),
+			)
+			return
+		}
+	}
+
+	if plan.Size.ValueInt64() > state.Size.ValueInt64() {
+		tflog.Info(ctx, fmt.Sprintf("resize data volume %s to %d GB", uuid, plan.Size.ValueInt64()))
+		_, err := runJob(ctx, job{
+			name:         "resize data volume",
+			resourceUuid: uuid,
+		}, func() (any, error) {
+			return r.client.ResizeDataVolume(uuid, param.ResizeDataVolumeParam{
+				ResizeDataVolume: param.ResizeDataVolumeDetailParam{
+					Size: utils.GBToBytes(plan.Size.ValueInt64()),
+				},
+			})
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Could not resize data volume",
+				fmt.Sprintf("fail to resize volume %s, err: %v", uuid, err),
+			)
+			return
+		}
+	}
+
+	if !plan.PrimaryStorageUuid.IsUnknown() && plan.PrimaryStorageUuid.ValueString() != "" &&
+		!plan.PrimaryStorageUuid.Equal(state.PrimaryStorageUuid) {
+		err := migrateVolume(ctx, r.client, uuid, plan.PrimaryStorageUuid.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("primary_storage_uuid"),
+				"Could not migrate data volume",
+				err.Error(),
+			)
+			return
+		}
+	}
+
+	volume, err := r.client.GetVolume(uuid)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not read data volume", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = readVolumeState(ctx, r.client, volume, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete implements resource.Resource.
+func (r *volumeResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_volume", "delete") {
+		return
+	}
+
+	var state volumeResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid := state.Uuid.ValueString()
+	if uuid == "" {
+		tflog.Warn(ctx, "volume uuid is empty, so nothing to delete, skip it")
+		return
+	}
+
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultVolumeDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	tflog.Info(ctx, fmt.Sprintf("delete data volume %s", uuid))
+	_, err := runJob(ctx, job{
+		name:         "delete data volume",
+		resourceUuid: uuid,
+	}, func() (any, error) {
+		return nil, r.client.DeleteDataVolume(uuid, param.DeleteModePermissive)
+	})
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryVolume, uuid)
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("volume %s not found, nothing to delete", uuid))
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not delete data volume", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	if state.Expunge.ValueBool() {
+		tflog.Info(ctx, fmt.Sprintf("expunge data volume %s", uuid))
+		_, err = runJob(ctx, job{
+			name:         "expunge data volume",
+			resourceUuid: uuid,
+		}, func() (any, error) {
+			return nil, r.client.ExpungeDataVolume(uuid)
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Could not expunge data volume", "Error: "+err.Error(),
+			)
+			return
+		}
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState.
+func (r *volumeResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("uuid"), req, resp)
+}
+
+// migrateVolume moves a data volume to another primary storage. The platform
+// refuses to migrate a volume that is attached to a running instance.
+func migrateVolume(ctx context.Context, cli *client.ZSClient, volumeUuid string, primaryStorageUuid string) error {
+	err := isDiskParamValid(cli, diskModel{
+		PrimaryStorageUuid: types.StringValue(primaryStorageUuid),
+	})
+	if err != nil {
+		return err
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("migrate volume %s to primary storage %s", volumeUuid, primaryStorageUuid))
+	_, err = runJob(ctx, job{
+		name:         "migrate volume",
+		resourceUuid: volumeUuid,
+	}, func() (any, error) {
+		return nil, cli.PrimaryStorageMigrateVolume(param.PrimaryStorageMigrateVolumeParam{
+			PrimaryStorageMigrateVolume: param.PrimaryStorageMigrateVolumeDetailParam{
+				VolumeUuid:            volumeUuid,
+				DstPrimaryStorageUuid: primaryStorageUuid,
+			},
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("fail to migrate volume %s to primary storage %s, err: %w", volumeUuid, primaryStorageUuid, err)
+	}
+	return nil
+}
+
+func readVolumeState(ctx context.Context, cli *client.ZSClient, volume *view.VolumeView, model *volumeResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	virtioVolumes, err := querySystemTags(cli, []string{volume.UUID}, volumeVirtioSCSISystemTag)
+	if err != nil {
+		diags.AddError(
+			"Could not read volume system tags", "Error: "+err.Error(),
+		)
+		return diags
+	}
+
+	model.Uuid = types.StringValue(volume.UUID)
+	model.Name = types.StringValue(volume.Name)
+	if volume.Description != "" || !model.Description.IsNull() {
+		model.Description = types.StringValue(volume.Description)
+	}
+	model.Size = types.Int64Value(utils.BytesToGB(volume.Size))
+	model.PrimaryStorageUuid = types.StringValue(volume.PrimaryStorageUUID)
+	model.VirtioSCSI = types.BoolValue(len(virtioVolumes[volume.UUID]) > 0)
+	model.Format = types.StringValue(volume.Format)
+
+	// a volume without install path has not been instantiated on a primary storage yet
+	if volume.InstallPath != "" {
+		model.CephPoolName = cephPoolNameFromInstallPath(volume.InstallPath)
+	} else if model.CephPoolName.IsUnknown() {
+		model.CephPoolName = types.StringNull()
+	}
+
+	return diags
+}
@@ -0,0 +1,263 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+)
+
+var (
+	_ resource.Resource                = &volumeAttachmentResource{}
+	_ resource.ResourceWithConfigure   = &volumeAttachmentResource{}
+	_ resource.ResourceWithImportState = &volumeAttachmentResource{}
+)
+
+const (
+	defaultVolumeAttachTimeout = 10 * time.Minute
+	defaultVolumeDetachTimeout = 10 * time.Minute
+)
+
+type volumeAttachmentResource struct {
+	client   *client.ZSClient
+	readOnly bool
+}
+
+type volumeAttachmentResourceModel struct {
+	VolumeUuid   types.String   `tfsdk:"volume_uuid"`
+	InstanceUuid types.String   `tfsdk:"instance_uuid"`
+	Timeouts     timeouts.Value `tfsdk:"timeouts"`
+}
+
+func VolumeAttachmentResource() resource.Resource {
+	return &volumeAttachmentResource{}
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *volumeAttachmentResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*resourceProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *resourceProviderData, got: %T. Please report this issue to the Provider developer. ", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.client
+	r.readOnly = providerData.readOnly
+}
+
+// Metadata implements resource.Resource.
+func (r *volumeAttachmentResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_volume_attachment"
+}
+
+// Schema implements resource.Resource.
+func (r *volumeAttachmentResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "This resource attaches a data volume to a virtual machine instance in ZSphere. " +
+			"Destroying the attachment detaches the volume, the volume itself and its data are kept.",
+		Attributes: map[string]schema.Attribute{
+			"volume_uuid": schema.StringAttribute{
+				Required:    true,
+				Description: "The UUID of the data volume to attach. Changing this forces a new attachment to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"instance_uuid": schema.StringAttribute{
+				Required:    true,
+				Description: "The UUID of the virtual machine instance the volume is attached to. Changing this forces a new attachment to be created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+// Create implements resource.Resource.
+func (r *volumeAttachmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_volume_attachment", "create") {
+		return
+	}
+
+	var plan volumeAttachmentResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultVolumeAttachTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	volumeUuid := plan.VolumeUuid.ValueString()
+	instanceUuid := plan.InstanceUuid.ValueString()
+
+	tflog.Info(ctx, fmt.Sprintf("attach volume %s to vm instance %s", volumeUuid, instanceUuid))
+	_, err := runJob(ctx, job{
+		name:         "attach data volume",
+		resourceUuid: volumeUuid,
+	}, func() (any, error) {
+		return r.client.AttachDataVolumeToVm(volumeUuid, instanceUuid)
+	})
+	if err != nil {
+		if errors.Is(err, errJobNotFinished) {
+			// the volume may still get attached, so the tainted attachment is saved to be detached on replacement
+			resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+		}
+		resp.Diagnostics.AddError(
+			"Could not attach data volume",
+			fmt.Sprintf("fail to attach volume %s to vm instance %s, err: %v", volumeUuid, instanceUuid, err),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read implements resource.Resource.
+func (r *volumeAttachmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state volumeAttachmentResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	volumeUuid := state.VolumeUuid.ValueString()
+	volume, err := r.client.GetVolume(volumeUuid)
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryVolume, volumeUuid)
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("volume %s not found, remove its attachment from state", volumeUuid))
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not read data volume", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	// an imported attachment only knows the volume, the instance is taken from the platform
+	if volume.VMInstanceUUID == "" || volume.Status == volumeStatusDeleted ||
+		(!state.InstanceUuid.IsNull() && state.InstanceUuid.ValueString() != volume.VMInstanceUUID) {
+		tflog.Warn(ctx, fmt.Sprintf("volume %s is no longer attached to vm instance %s, remove the attachment from state", volumeUuid, state.InstanceUuid.ValueString()))
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.InstanceUuid = types.StringValue(volume.VMInstanceUUID)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource. Every attribute forces replacement, so there is nothing to update in place.
+func (r *volumeAttachmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan volumeAttachmentResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete implements resource.Resource.
+func (r *volumeAttachmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !checkWritable(r.readOnly, &resp.Diagnostics, "zsphere_volume_attachment", "delete") {
+		return
+	}
+
+	var state volumeAttachmentResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultVolumeDetachTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	volumeUuid := state.VolumeUuid.ValueString()
+	instanceUuid := state.InstanceUuid.ValueString()
+
+	volume, err := r.client.GetVolume(volumeUuid)
+	if err != nil {
+		notFound, queryErr := resourceNotFound(r.client.QueryVolume, volumeUuid)
+		if queryErr == nil && notFound {
+			tflog.Warn(ctx, fmt.Sprintf("volume %s not found, nothing to detach", volumeUuid))
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Could not read data volume", "Error: "+err.Error(),
+		)
+		return
+	}
+
+	if volume.VMInstanceUUID != instanceUuid {
+		tflog.Warn(ctx, fmt.Sprintf("volume %s is not attached to vm instance %s, nothing to detach", volumeUuid, instanceUuid))
+		return
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("detach volume %s from vm instance %s", volumeUuid, instanceUuid))
+	_, err = runJob(ctx, job{
+		name:         "detach data volume",
+		resourceUuid: volumeUuid,
+	}, func() (any, error) {
+		return r.client.DetachDataVolumeFromVm(volumeUuid, instanceUuid)
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Could not detach data volume",
+			fmt.Sprintf("fail to detach volume %s from vm instance %s, err: %v", volumeUuid, instanceUuid, err),
+		)
+		return
+	}
+}
+
+// ImportState implements resource.ResourceWithImportState. The import ID is the UUID of an attached volume.
+func (r *volumeAttachmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("volume_uuid"), req, resp)
+}
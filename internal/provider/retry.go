@@ -0,0 +1,230 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	defaultMaxRetries      = 3
+	defaultRetryMinBackoff = 1 * time.Second
+	defaultRetryMaxBackoff = 30 * time.Second
+
+	// maxRetryBodySize limits how much of an error response is read to look for a busy message.
+	maxRetryBodySize = 64 * 1024
+)
+
+// retryableStatusCodes are the responses of the management node or a proxy in front of it that mean
+// the request was not processed and can be sent again.
+var retryableStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// busyMessages mark an error response of the management node as a transient resource conflict.
+var busyMessages = []string{
+	"resource busy",
+	"is busy",
+	"try again later",
+}
+
+// retryPolicy describes how often and how long to wait before a failed ZSphere API call is retried.
+type retryPolicy struct {
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// backoff returns how long to wait before the given retry, counting from 1. The wait doubles with
+// every retry, up to the maximum backoff, with jitter so that parallel runs do not retry in lockstep.
+func (p retryPolicy) backoff(retry int) time.Duration {
+	wait := p.minBackoff
+	for i := 1; i < retry && wait < p.maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > p.maxBackoff {
+		wait = p.maxBackoff
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// retryTransport sends the requests of the ZSphere client and retries the ones that fail with a
+// transient error, like a 503 from the management node, a reset connection or a busy resource.
+// Requests that change resources (POST) are only retried when the management node did not get
+// or did not accept them, so that a resource is never created twice.
+type retryTransport struct {
+	// ctx carries the provider logger; requests of the SDK do not.
+	ctx    context.Context
+	next   http.RoundTripper
+	policy retryPolicy
+}
+
+func newRetryTransport(ctx context.Context, next http.RoundTripper, policy retryPolicy) *retryTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &retryTransport{
+		ctx:    ctx,
+		next:   next,
+		policy: policy,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.Body, _ = req.GetBody()
+	}
+
+	for retry := 0; ; retry++ {
+		attempt := req
+		if retry > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attempt = req.Clone(req.Context())
+			attempt.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(attempt)
+		reason := ""
+		if err != nil {
+			reason = retryableError(req, err)
+		} else {
+			reason = retryableResponse(req, resp)
+		}
+		if reason == "" || retry >= t.policy.maxRetries {
+			return resp, err
+		}
+
+		wait := t.policy.backoff(retry + 1)
+		if resp != nil {
+			if after := retryAfter(resp); after > wait {
+				wait = min(after, t.policy.maxBackoff)
+			}
+			// the connection can only be reused when the body is read to the end
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		tflog.Warn(t.ctx, fmt.Sprintf("%s %s failed with %s, retry %d of %d in %s",
+			req.Method, req.URL.Path, reason, retry+1, t.policy.maxRetries, wait.Round(time.Millisecond)))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryableError returns why a failed request can be retried, or an empty string if it cannot.
+func retryableError(req *http.Request, err error) string {
+	if req.Context().Err() != nil {
+		return ""
+	}
+
+	// the connection was never made, so the management node did not see the request
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return "connection error: " + err.Error()
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return "connection refused"
+	}
+
+	if !isIdempotent(req) {
+		return ""
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		strings.Contains(err.Error(), "connection reset by peer") {
+		return "connection reset"
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return "timeout"
+	}
+	return ""
+}
+
+// retryableResponse returns why a request can be retried after its response, or an empty string if it cannot.
+// The body of an error response is read to look for a busy message and replaced, so the caller can still read it.
+func retryableResponse(req *http.Request, resp *http.Response) string {
+	if resp.StatusCode < http.StatusBadRequest {
+		return ""
+	}
+	if retryableStatusCodes[resp.StatusCode] {
+		if resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusGatewayTimeout {
+			// a proxy may have passed the request on before the management node went away
+			if !isIdempotent(req) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRetryBodySize))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	if err != nil {
+		return ""
+	}
+
+	message := strings.ToLower(string(body))
+	for _, busy := range busyMessages {
+		if strings.Contains(message, busy) {
+			return fmt.Sprintf("HTTP %d, %s", resp.StatusCode, busy)
+		}
+	}
+	return ""
+}
+
+// retryAfter returns the wait the server asks for in the Retry-After header, in seconds, or 0.
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// isIdempotent reports whether sending the request twice has the same effect as sending it once.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
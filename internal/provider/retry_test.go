@@ -0,0 +1,285 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// faultServer is a fake management node that answers the first requests with the given faults and then succeeds.
+type faultServer struct {
+	*httptest.Server
+	requests atomic.Int32
+
+	mu     sync.Mutex
+	bodies []string
+}
+
+// fault answers a request with an error and reports whether it did, or leaves it to the success handler.
+type fault func(w http.ResponseWriter, r *http.Request) bool
+
+func newFaultServer(t *testing.T, faults ...fault) *faultServer {
+	t.Helper()
+
+	s := &faultServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(s.requests.Add(1))
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.bodies = append(s.bodies, string(body))
+		s.mu.Unlock()
+
+		if n <= len(faults) && faults[n-1](w, r) {
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, `{"inventory":{"uuid":"ok"}}`)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func statusFault(status int, body string) fault {
+	return func(w http.ResponseWriter, _ *http.Request) bool {
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+		return true
+	}
+}
+
+// resetFault closes the connection without a response, like a management node restarting.
+func resetFault(w http.ResponseWriter, _ *http.Request) bool {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		panic(err)
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		_ = tcp.SetLinger(0)
+	}
+	conn.Close()
+	return true
+}
+
+func newTestRetryClient(t *testing.T, maxRetries int) *http.Client {
+	t.Helper()
+
+	transport, err := newHttpTransport(transportConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport.DisableKeepAlives = true
+	return &http.Client{
+		Transport: newRetryTransport(context.Background(), transport, retryPolicy{
+			maxRetries: maxRetries,
+			minBackoff: time.Millisecond,
+			maxBackoff: 5 * time.Millisecond,
+		}),
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	cases := []struct {
+		name         string
+		method       string
+		faults       []fault
+		maxRetries   int
+		wantStatus   int
+		wantErr      bool
+		wantRequests int
+	}{
+		{
+			name:         "success is not retried",
+			method:       http.MethodGet,
+			maxRetries:   3,
+			wantStatus:   http.StatusOK,
+			wantRequests: 1,
+		},
+		{
+			name:         "503 is retried until it succeeds",
+			method:       http.MethodGet,
+			faults:       []fault{statusFault(http.StatusServiceUnavailable, ""), statusFault(http.StatusServiceUnavailable, "")},
+			maxRetries:   3,
+			wantStatus:   http.StatusOK,
+			wantRequests: 3,
+		},
+		{
+			name:         "503 on POST is retried",
+			method:       http.MethodPost,
+			faults:       []fault{statusFault(http.StatusServiceUnavailable, "")},
+			maxRetries:   3,
+			wantStatus:   http.StatusOK,
+			wantRequests: 2,
+		},
+		{
+			name:         "busy resource is retried",
+			method:       http.MethodPut,
+			faults:       []fault{statusFault(http.StatusInternalServerError, `{"error":{"details":"the vm instance is busy, try again later"}}`)},
+			maxRetries:   3,
+			wantStatus:   http.StatusOK,
+			wantRequests: 2,
+		},
+		{
+			name:         "client error is not retried",
+			method:       http.MethodGet,
+			faults:       []fault{statusFault(http.StatusBadRequest, `{"error":{"details":"invalid uuid"}}`)},
+			maxRetries:   3,
+			wantStatus:   http.StatusBadRequest,
+			wantRequests: 1,
+		},
+		{
+			name:         "502 on POST is not retried",
+			method:       http.MethodPost,
+			faults:       []fault{statusFault(http.StatusBadGateway, "")},
+			maxRetries:   3,
+			wantStatus:   http.StatusBadGateway,
+			wantRequests: 1,
+		},
+		{
+			name:         "last error is returned when retries are used up",
+			method:       http.MethodGet,
+			faults:       []fault{statusFault(http.StatusServiceUnavailable, ""), statusFault(http.StatusServiceUnavailable, ""), statusFault(http.StatusServiceUnavailable, "")},
+			maxRetries:   2,
+			wantStatus:   http.StatusServiceUnavailable,
+			wantRequests: 3,
+		},
+		{
+			name:         "no retries when disabled",
+			method:       http.MethodGet,
+			faults:       []fault{statusFault(http.StatusServiceUnavailable, "")},
+			maxRetries:   0,
+			wantStatus:   http.StatusServiceUnavailable,
+			wantRequests: 1,
+		},
+		{
+			name:         "connection reset is retried",
+			method:       http.MethodGet,
+			faults:       []fault{resetFault},
+			maxRetries:   3,
+			wantStatus:   http.StatusOK,
+			wantRequests: 2,
+		},
+		{
+			name:         "connection reset on POST is not retried",
+			method:       http.MethodPost,
+			faults:       []fault{resetFault},
+			maxRetries:   3,
+			wantErr:      true,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newFaultServer(t, tc.faults...)
+
+			req, err := http.NewRequest(tc.method, server.URL+"/zstack/v1/vm-instances", strings.NewReader(`{"params":{"name":"vm"}}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := newTestRetryClient(t, tc.maxRetries).Do(req)
+			if tc.wantErr {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatalf("expected an error, got HTTP %d", resp.StatusCode)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				defer resp.Body.Close()
+				if resp.StatusCode != tc.wantStatus {
+					t.Errorf("expected HTTP %d, got HTTP %d", tc.wantStatus, resp.StatusCode)
+				}
+			}
+
+			if got := int(server.requests.Load()); got != tc.wantRequests {
+				t.Errorf("expected %d requests, got %d", tc.wantRequests, got)
+			}
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			for i, body := range server.bodies {
+				if body != `{"params":{"name":"vm"}}` {
+					t.Errorf("request %d was sent with body %q", i+1, body)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransportKeepsErrorBody(t *testing.T) {
+	server := newFaultServer(t, statusFault(http.StatusInternalServerError, `{"error":{"details":"disk full"}}`))
+
+	resp, err := newTestRetryClient(t, 3).Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != `{"error":{"details":"disk full"}}` {
+		t.Errorf("error body was not passed on, got %q", body)
+	}
+}
+
+func TestRetryTransportStopsWhenCancelled(t *testing.T) {
+	server := newFaultServer(t, statusFault(http.StatusServiceUnavailable, ""), statusFault(http.StatusServiceUnavailable, ""))
+
+	client := &http.Client{
+		Transport: newRetryTransport(context.Background(), nil, retryPolicy{
+			maxRetries: 3,
+			minBackoff: time.Hour,
+			maxBackoff: time.Hour,
+		}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("expected an error after the context is cancelled")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("retry did not stop when the context was cancelled, took %s", elapsed)
+	}
+	if got := int(server.requests.Load()); got != 1 {
+		t.Errorf("expected 1 request, got %d", got)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := retryPolicy{
+		maxRetries: 10,
+		minBackoff: 100 * time.Millisecond,
+		maxBackoff: time.Second,
+	}
+
+	for retry, want := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		4: 800 * time.Millisecond,
+		5: time.Second,
+		9: time.Second,
+	} {
+		got := policy.backoff(retry)
+		if got < want/2 || got > want {
+			t.Errorf("backoff of retry %d is %s, expected between %s and %s", retry, got, want/2, want)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+)
+
+const (
+	// sessionHeaderPrefix is how the ZSphere API expects the session in the Authorization header.
+	sessionHeaderPrefix = "OAuth "
+
+	// logoutTimeout limits how long the provider waits for its sessions to be logged out when it shuts down.
+	// Terraform kills the provider about 2 seconds after asking it to stop, so it has to stay well below that.
+	logoutTimeout = 1 * time.Second
+)
+
+// sessionExpiredMessages mark an error response of the management node as an expired or invalid session.
+var sessionExpiredMessages = []string{
+	"session expired",
+	"invalid session",
+	"session not found",
+	"id.1001",
+}
+
+// sessionTransport keeps the session of account/password authentication alive. When the management node
+// answers that the session expired, it logs in again and resends the request with the new session.
+type sessionTransport struct {
+	// ctx carries the provider logger; requests of the SDK do not.
+	ctx  context.Context
+	next http.RoundTripper
+	// login logs in to the ZSphere API and returns the new session.
+	login func() (string, error)
+	// cache optionally keeps the session for other provider runs.
+	cache *sessionCache
+
+	mu      sync.Mutex
+	session string
+}
+
+// setSession records the session the client uses.
+func (t *sessionTransport) setSession(session string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.session = session
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *sessionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	auth := req.Header.Get("Authorization")
+	if t.login == nil || !strings.HasPrefix(auth, sessionHeaderPrefix) || isLoginRequest(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || !sessionExpired(resp) {
+		return resp, err
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		// the request cannot be sent again
+		return resp, nil
+	}
+
+	session, loginErr := t.relogin(strings.TrimPrefix(auth, sessionHeaderPrefix))
+	if loginErr != nil {
+		tflog.Warn(t.ctx, fmt.Sprintf("ZSphere session expired and logging in again failed, err: %v", loginErr))
+		return resp, nil
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+	retry.Header.Set("Authorization", sessionHeaderPrefix+session)
+	return t.next.RoundTrip(retry)
+}
+
+// relogin logs in again unless another request already replaced the expired session, and returns the new session.
+func (t *sessionTransport) relogin(expired string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.session != "" && t.session != expired {
+		return t.session, nil
+	}
+
+	tflog.Info(t.ctx, "ZSphere session expired, log in again")
+	session, err := t.login()
+	if err != nil {
+		return "", err
+	}
+	t.session = session
+	if t.cache != nil {
+		if err := t.cache.store(session); err != nil {
+			tflog.Warn(t.ctx, fmt.Sprintf("failed to cache ZSphere session, err: %v", err))
+		}
+	}
+	return session, nil
+}
+
+// isLoginRequest reports whether the request logs in to or out of the ZSphere API.
+func isLoginRequest(req *http.Request) bool {
+	path := strings.ToLower(req.URL.Path)
+	return strings.Contains(path, "/login") || strings.Contains(path, "/sessions")
+}
+
+// sessionExpired reports whether the management node rejected the session of a request. The body of an
+// error response is read to look for the reason and replaced, so the caller can still read it.
+func sessionExpired(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+	if resp.StatusCode < http.StatusBadRequest {
+		return false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRetryBodySize))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	if err != nil {
+		return false
+	}
+
+	message := strings.ToLower(string(body))
+	for _, expired := range sessionExpiredMessages {
+		if strings.Contains(message, expired) {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionCache keeps the session of an account on disk, so that parallel and later provider runs
+// against the same management node reuse it instead of each logging in.
+type sessionCache struct {
+	path     string
+	endpoint string
+	account  string
+}
+
+type cachedSession struct {
+	Endpoint  string    `json:"endpoint"`
+	Account   string    `json:"account"`
+	Session   string    `json:"session"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// newSessionCache returns the cache of the sessions of the account on the management node, in ~/.zsphere/sessions.
+func newSessionCache(endpoint string, account string) (*sessionCache, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the home directory for the session cache, err: %v", err)
+	}
+
+	key := sha256.Sum256([]byte(endpoint + "\n" + account))
+	return &sessionCache{
+		path:     filepath.Join(home, ".zsphere", "sessions", hex.EncodeToString(key[:16])+".json"),
+		endpoint: endpoint,
+		account:  account,
+	}, nil
+}
+
+// load returns the cached session, or an empty string if there is none.
+func (c *sessionCache) load() string {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return ""
+	}
+
+	var cached cachedSession
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Endpoint != c.endpoint || cached.Account != c.account {
+		return ""
+	}
+	return cached.Session
+}
+
+// store replaces the cached session. The file is only readable by the user, as the session grants access to the account.
+func (c *sessionCache) store(session string) error {
+	data, err := json.Marshal(cachedSession{
+		Endpoint:  c.endpoint,
+		Account:   c.account,
+		Session:   session,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".session-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// renaming is atomic, so a parallel run never reads a half written session
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// openSessions are the sessions the provider logged in with and has to log out of when it shuts down.
+var openSessions struct {
+	sync.Mutex
+	clients []*client.ZSClient
+}
+
+// logoutOnShutdown records a session to log out of when the provider shuts down.
+func logoutOnShutdown(cli *client.ZSClient) {
+	openSessions.Lock()
+	defer openSessions.Unlock()
+	openSessions.clients = append(openSessions.clients, cli)
+}
+
+// Shutdown logs out of the sessions the provider logged in with, except the cached ones that later runs reuse.
+// It is called when the provider server stops and gives up after logoutTimeout, before Terraform kills the
+// provider, as the sessions expire anyway.
+func Shutdown() {
+	openSessions.Lock()
+	clients := openSessions.clients
+	openSessions.clients = nil
+	openSessions.Unlock()
+
+	var wg sync.WaitGroup
+	for _, cli := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = cli.Logout()
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(logoutTimeout):
+	}
+}
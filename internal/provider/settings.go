@@ -0,0 +1,358 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	// defaultProfile is the profile used when none is selected, if the config file has it.
+	defaultProfile = "default"
+
+	profileAttr = "profile"
+	envPrefix   = "ZSPHERE_"
+)
+
+// credentialMethods are the attributes of each authentication method, account first as the provider
+// prefers it when both are set. The method is chosen by the first source that sets any credential, so that
+// an account from one source is never mixed with an access key from another.
+var credentialMethods = []struct {
+	name  string
+	attrs []string
+}{
+	{"account", []string{"account_name", "account_password"}},
+	{"access_key", []string{"access_key_id", "access_key_secret"}},
+}
+
+// addressAttrs are the parts of the API address that endpoint replaces.
+var addressAttrs = []string{"host", "port", "scheme"}
+
+// settingsLayer is one source of provider settings: the provider block, the environment or a profile.
+type settingsLayer struct {
+	// describe tells the user where the value of an attribute came from.
+	describe func(attr string) string
+	values   map[string]string
+}
+
+// providerSettings merges the provider settings from several sources. The first source that
+// sets an attribute wins: the provider block, then the ZSPHERE_* environment variables, then
+// the selected profile of the config file.
+type providerSettings struct {
+	layers []settingsLayer
+}
+
+func newProviderSettings(layers ...settingsLayer) providerSettings {
+	return providerSettings{layers: layers}
+}
+
+// lookup returns the value of the attribute and the index of the layer it came from, or -1 if it is not set.
+func (s providerSettings) lookup(attr string) (string, int) {
+	if method := credentialMethodOf(attr); method != "" && method != s.credentialMethod() {
+		return "", -1
+	}
+
+	for i, layer := range s.layers {
+		if value := layer.values[attr]; value != "" {
+			return value, i
+		}
+	}
+	return "", -1
+}
+
+// get returns the value of the attribute, or an empty string if it is not set.
+func (s providerSettings) get(attr string) string {
+	value, _ := s.lookup(attr)
+	return value
+}
+
+// source describes where the value of the attribute came from.
+func (s providerSettings) source(attr string) string {
+	_, i := s.lookup(attr)
+	if i < 0 {
+		return fmt.Sprintf("the default %s", attr)
+	}
+	return s.layers[i].describe(attr)
+}
+
+// endpoint returns the endpoint if it is the API address to use. It is used when the first source that sets
+// any part of the address sets the endpoint; a conflict is reported when that source sets other parts too.
+func (s providerSettings) endpoint() (string, error) {
+	endpoint, endpointLayer := s.lookup("endpoint")
+	if endpoint == "" {
+		return "", nil
+	}
+
+	for _, attr := range addressAttrs {
+		_, layer := s.lookup(attr)
+		switch {
+		case layer < 0 || layer > endpointLayer:
+			continue
+		case layer == endpointLayer:
+			return "", fmt.Errorf("%s contains the scheme, host and port of the ZSphere API, so %s cannot be set together with it",
+				s.source("endpoint"), s.source(attr))
+		default:
+			// a part of the address is set with a higher precedence than the endpoint
+			return "", nil
+		}
+	}
+	return endpoint, nil
+}
+
+// sources records where the values of the attributes came from, for diagnostics.
+func (s providerSettings) sources(attrs ...string) configSources {
+	sources := configSources{}
+	for _, attr := range attrs {
+		if _, i := s.lookup(attr); i >= 0 {
+			sources[attr] = s.layers[i].describe(attr)
+		}
+	}
+	return sources
+}
+
+// getBool returns the value of a boolean attribute, false if it is not set.
+func (s providerSettings) getBool(attr string) (bool, error) {
+	raw := s.get(attr)
+	if raw == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false, got: %q", s.source(attr), raw)
+	}
+	return b, nil
+}
+
+// getInt returns the value of a whole number attribute not less than 0, or the default if it is not set.
+func (s providerSettings) getInt(attr string, defaultValue int) (int, error) {
+	raw := s.get(attr)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return defaultValue, fmt.Errorf("%s must be a number not less than 0, got: %q", s.source(attr), raw)
+	}
+	return n, nil
+}
+
+// getDuration returns the value of a duration attribute, or the default if it is not set.
+func (s providerSettings) getDuration(attr string, defaultValue time.Duration) (time.Duration, error) {
+	raw := s.get(attr)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return defaultValue, fmt.Errorf("%s must be a duration such as \"1s\" or \"500ms\", got: %q", s.source(attr), raw)
+	}
+	return d, nil
+}
+
+// credentialMethod returns the authentication method of the first source that sets any credential.
+func (s providerSettings) credentialMethod() string {
+	for _, layer := range s.layers {
+		for _, method := range credentialMethods {
+			for _, attr := range method.attrs {
+				if layer.values[attr] != "" {
+					return method.name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// credentialMethodOf returns the authentication method of a credential attribute, or an empty string for other attributes.
+func credentialMethodOf(attr string) string {
+	for _, method := range credentialMethods {
+		for _, credential := range method.attrs {
+			if attr == credential {
+				return method.name
+			}
+		}
+	}
+	return ""
+}
+
+// providerAttrs returns the names of the provider attributes that can be set in every source.
+func providerAttrs() []string {
+	var attrs []string
+	t := reflect.TypeOf(ZSphereProviderModel{})
+	for i := 0; i < t.NumField(); i++ {
+		if attr := t.Field(i).Tag.Get("tfsdk"); attr != "" && attr != profileAttr {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs
+}
+
+// configLayer returns the settings of the provider block. Unknown values are left out, Configure rejects them.
+func configLayer(config ZSphereProviderModel) settingsLayer {
+	values := make(map[string]string)
+	v := reflect.ValueOf(config)
+	for i := 0; i < v.NumField(); i++ {
+		attr := v.Type().Field(i).Tag.Get("tfsdk")
+		switch value := v.Field(i).Interface().(type) {
+		case types.String:
+			if !value.IsNull() && !value.IsUnknown() {
+				values[attr] = value.ValueString()
+			}
+		case types.Int64:
+			if !value.IsNull() && !value.IsUnknown() {
+				values[attr] = strconv.FormatInt(value.ValueInt64(), 10)
+			}
+		case types.Bool:
+			if !value.IsNull() && !value.IsUnknown() {
+				values[attr] = strconv.FormatBool(value.ValueBool())
+			}
+		}
+	}
+	delete(values, profileAttr)
+
+	return settingsLayer{
+		describe: func(attr string) string {
+			return fmt.Sprintf("the %q attribute of the provider configuration", attr)
+		},
+		values: values,
+	}
+}
+
+// envLayer returns the settings of the ZSPHERE_* environment variables, read with getenv.
+func envLayer(getenv func(string) string) settingsLayer {
+	values := make(map[string]string)
+	for _, attr := range providerAttrs() {
+		if value := getenv(envName(attr)); value != "" {
+			values[attr] = value
+		}
+	}
+
+	return settingsLayer{
+		describe: func(attr string) string {
+			return fmt.Sprintf("the %s environment variable", envName(attr))
+		},
+		values: values,
+	}
+}
+
+// envName returns the environment variable of a provider attribute.
+func envName(attr string) string {
+	return envPrefix + strings.ToUpper(attr)
+}
+
+// configFilePath returns the path of the config file with the profiles, ~/.zsphere/config unless ZSPHERE_CONFIG_FILE is set.
+func configFilePath(getenv func(string) string) (string, error) {
+	if path := getenv("ZSPHERE_CONFIG_FILE"); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".zsphere", "config"), nil
+}
+
+// profileLayer returns the settings of a profile in the config file. When no profile is selected, the default
+// profile is used if the config file has it.
+func profileLayer(path string, profile string) (settingsLayer, error) {
+	selected := profile != ""
+	if !selected {
+		profile = defaultProfile
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if !selected && errors.Is(err, fs.ErrNotExist) {
+			return settingsLayer{}, nil
+		}
+		return settingsLayer{}, fmt.Errorf("failed to read the config file for profile %q, err: %v", profile, err)
+	}
+	defer file.Close()
+
+	profiles, err := parseProfiles(file)
+	if err != nil {
+		return settingsLayer{}, fmt.Errorf("failed to parse the config file %s, err: %v", path, err)
+	}
+
+	values, ok := profiles[profile]
+	if !ok {
+		if !selected {
+			return settingsLayer{}, nil
+		}
+		return settingsLayer{}, fmt.Errorf("profile %q is not found in the config file %s", profile, path)
+	}
+
+	known := make(map[string]bool)
+	for _, attr := range providerAttrs() {
+		known[attr] = true
+	}
+	for attr := range values {
+		if !known[attr] {
+			return settingsLayer{}, fmt.Errorf("unknown setting %q in profile %q of the config file %s", attr, profile, path)
+		}
+	}
+
+	return settingsLayer{
+		describe: func(attr string) string {
+			return fmt.Sprintf("the %q setting of profile %q in %s", attr, profile, path)
+		},
+		values: values,
+	}, nil
+}
+
+// parseProfiles parses an INI style config file. A profile starts with a [name] or [profile name] line
+// and holds "key = value" lines; lines starting with # or ; are comments.
+func parseProfiles(r io.Reader) (map[string]map[string]string, error) {
+	profiles := make(map[string]map[string]string)
+	var current map[string]string
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: profile header must end with ]", lineNo)
+			}
+			name := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "["), "]"))
+			name = strings.TrimSpace(strings.TrimPrefix(name, "profile "))
+			if name == "" {
+				return nil, fmt.Errorf("line %d: profile name is empty", lineNo)
+			}
+			if _, ok := profiles[name]; !ok {
+				profiles[name] = make(map[string]string)
+			}
+			current = profiles[name]
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		if current == nil {
+			return nil, fmt.Errorf("line %d: setting outside of a profile", lineNo)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' && value[len(value)-1] == '"' || value[0] == '\'' && value[len(value)-1] == '\'') {
+			value = value[1 : len(value)-1]
+		}
+		current[strings.TrimSpace(key)] = value
+	}
+	return profiles, scanner.Err()
+}
@@ -0,0 +1,305 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const testConfigFile = `
+# shared settings
+[default]
+host = 10.0.0.1
+port = 8080
+access_key_id = default-key
+access_key_secret = default-secret
+
+[profile prod]
+endpoint = "https://zsphere.example.com/zstack"
+account_name = admin
+account_password = 'prod password'
+; prod is read-only by default
+read_only = true
+
+[lab]
+host=172.16.0.1
+insecure_skip_verify = true
+`
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// testEnv returns a getenv function over the given variables, so that tests do not depend on the real environment.
+func testEnv(vars map[string]string) func(string) string {
+	return func(name string) string {
+		return vars[name]
+	}
+}
+
+func TestParseProfiles(t *testing.T) {
+	profiles, err := parseProfiles(strings.NewReader(testConfigFile))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]map[string]string{
+		"default": {"host": "10.0.0.1", "port": "8080", "access_key_id": "default-key", "access_key_secret": "default-secret"},
+		"prod":    {"endpoint": "https://zsphere.example.com/zstack", "account_name": "admin", "account_password": "prod password", "read_only": "true"},
+		"lab":     {"host": "172.16.0.1", "insecure_skip_verify": "true"},
+	}
+	if len(profiles) != len(want) {
+		t.Fatalf("expected %d profiles, got %v", len(want), profiles)
+	}
+	for name, values := range want {
+		for key, value := range values {
+			if got := profiles[name][key]; got != value {
+				t.Errorf("profile %q: expected %s = %q, got %q", name, key, value, got)
+			}
+		}
+		if len(profiles[name]) != len(values) {
+			t.Errorf("profile %q: expected %d settings, got %v", name, len(values), profiles[name])
+		}
+	}
+}
+
+func TestParseProfilesErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"setting outside of a profile": "host = 10.0.0.1\n",
+		"missing equals sign":          "[default]\nhost 10.0.0.1\n",
+		"unterminated header":          "[default\nhost = 10.0.0.1\n",
+		"empty profile name":           "[ ]\nhost = 10.0.0.1\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseProfiles(strings.NewReader(content)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestProfileLayer(t *testing.T) {
+	path := writeConfigFile(t, testConfigFile)
+
+	layer, err := profileLayer(path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := layer.values["host"]; got != "10.0.0.1" {
+		t.Errorf("expected the default profile without a selected profile, got host %q", got)
+	}
+
+	layer, err = profileLayer(path, "prod")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := layer.values["account_name"]; got != "admin" {
+		t.Errorf("expected account_name of profile prod, got %q", got)
+	}
+	if got := layer.describe("account_name"); !strings.Contains(got, `profile "prod"`) || !strings.Contains(got, path) {
+		t.Errorf("source does not name the profile and the file: %s", got)
+	}
+
+	if _, err := profileLayer(path, "missing"); err == nil || !strings.Contains(err.Error(), `"missing"`) {
+		t.Errorf("expected an error naming the missing profile, got %v", err)
+	}
+
+	if _, err := profileLayer(writeConfigFile(t, "[default]\nhots = 10.0.0.1\n"), ""); err == nil || !strings.Contains(err.Error(), "hots") {
+		t.Errorf("expected an error naming the unknown setting, got %v", err)
+	}
+
+	noFile := filepath.Join(t.TempDir(), "config")
+	if layer, err := profileLayer(noFile, ""); err != nil || len(layer.values) != 0 {
+		t.Errorf("expected no settings and no error without a config file, got %v, %v", layer.values, err)
+	}
+	if _, err := profileLayer(noFile, "prod"); err == nil {
+		t.Error("expected an error for a selected profile without a config file")
+	}
+
+	layer, err = profileLayer(writeConfigFile(t, "[lab]\nhost = 172.16.0.1\n"), "")
+	if err != nil || len(layer.values) != 0 {
+		t.Errorf("expected no settings without a default profile, got %v, %v", layer.values, err)
+	}
+}
+
+func TestProviderSettingsPrecedence(t *testing.T) {
+	profile, err := profileLayer(writeConfigFile(t, testConfigFile), "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := ZSphereProviderModel{
+		Host:       types.StringValue("192.168.0.1"),
+		MaxRetries: types.Int64Value(5),
+		DebugHttp:  types.BoolValue(false),
+	}
+	env := envLayer(testEnv(map[string]string{
+		"ZSPHERE_HOST":              "10.1.1.1",
+		"ZSPHERE_PORT":              "9090",
+		"ZSPHERE_RETRY_MIN_BACKOFF": "2s",
+		"ZSPHERE_DEBUG_HTTP":        "true",
+	}))
+	settings := newProviderSettings(configLayer(config), env, profile)
+
+	for attr, want := range map[string]string{
+		"host":              "192.168.0.1",
+		"port":              "9090",
+		"max_retries":       "5",
+		"retry_min_backoff": "2s",
+		"debug_http":        "false",
+		"access_key_id":     "default-key",
+		"scheme":            "",
+	} {
+		if got := settings.get(attr); got != want {
+			t.Errorf("expected %s = %q, got %q", attr, want, got)
+		}
+	}
+
+	for attr, want := range map[string]string{
+		"host":          `the "host" attribute of the provider configuration`,
+		"port":          "the ZSPHERE_PORT environment variable",
+		"access_key_id": `the "access_key_id" setting of profile "default"`,
+		"scheme":        "the default scheme",
+	} {
+		if got := settings.source(attr); !strings.HasPrefix(got, want) {
+			t.Errorf("expected the source of %s to start with %q, got %q", attr, want, got)
+		}
+	}
+
+	debugHttp, err := settings.getBool("debug_http")
+	if err != nil || debugHttp {
+		t.Errorf("expected debug_http false from the provider configuration, got %v, %v", debugHttp, err)
+	}
+}
+
+func TestProviderSettingsCredentials(t *testing.T) {
+	profile := settingsLayer{
+		describe: func(attr string) string { return "profile " + attr },
+		values:   map[string]string{"access_key_id": "key", "access_key_secret": "secret"},
+	}
+
+	cases := []struct {
+		name string
+		env  map[string]string
+		want map[string]string
+	}{
+		{
+			name: "access key of the profile",
+			want: map[string]string{"access_key_id": "key", "access_key_secret": "secret"},
+		},
+		{
+			name: "account of the environment replaces the access key of the profile",
+			env:  map[string]string{"ZSPHERE_ACCOUNT_NAME": "admin", "ZSPHERE_ACCOUNT_PASSWORD": "password"},
+			want: map[string]string{"account_name": "admin", "account_password": "password"},
+		},
+		{
+			name: "partial account is not completed with the access key",
+			env:  map[string]string{"ZSPHERE_ACCOUNT_NAME": "admin"},
+			want: map[string]string{"account_name": "admin"},
+		},
+		{
+			name: "access key parts are merged across sources",
+			env:  map[string]string{"ZSPHERE_ACCESS_KEY_SECRET": "env-secret"},
+			want: map[string]string{"access_key_id": "key", "access_key_secret": "env-secret"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			settings := newProviderSettings(configLayer(ZSphereProviderModel{}), envLayer(testEnv(tc.env)), profile)
+			for _, method := range credentialMethods {
+				for _, attr := range method.attrs {
+					if got := settings.get(attr); got != tc.want[attr] {
+						t.Errorf("expected %s = %q, got %q", attr, tc.want[attr], got)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestProviderSettingsEndpoint(t *testing.T) {
+	profile, err := profileLayer(writeConfigFile(t, testConfigFile), "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		config  ZSphereProviderModel
+		env     map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "endpoint of the profile",
+			want: "https://zsphere.example.com/zstack",
+		},
+		{
+			name: "host of the environment replaces the endpoint of the profile",
+			env:  map[string]string{"ZSPHERE_HOST": "10.1.1.1"},
+			want: "",
+		},
+		{
+			name:    "endpoint and port in the same source conflict",
+			config:  ZSphereProviderModel{Endpoint: types.StringValue("https://a.example.com"), Port: types.Int64Value(443)},
+			wantErr: true,
+		},
+		{
+			name:   "endpoint of the configuration replaces the host of the environment",
+			config: ZSphereProviderModel{Endpoint: types.StringValue("https://a.example.com")},
+			env:    map[string]string{"ZSPHERE_HOST": "10.1.1.1"},
+			want:   "https://a.example.com",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			settings := newProviderSettings(configLayer(tc.config), envLayer(testEnv(tc.env)), profile)
+			got, err := settings.endpoint()
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got endpoint %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("expected endpoint %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestProviderSettingsInvalidValues(t *testing.T) {
+	settings := newProviderSettings(envLayer(testEnv(map[string]string{
+		"ZSPHERE_PORT":              "http",
+		"ZSPHERE_READ_ONLY":         "yes please",
+		"ZSPHERE_RETRY_MAX_BACKOFF": "30",
+	})))
+
+	if _, err := settings.getInt("port", 8080); err == nil || !strings.Contains(err.Error(), "ZSPHERE_PORT") {
+		t.Errorf("expected an error naming ZSPHERE_PORT, got %v", err)
+	}
+	if _, err := settings.getBool("read_only"); err == nil || !strings.Contains(err.Error(), "ZSPHERE_READ_ONLY") {
+		t.Errorf("expected an error naming ZSPHERE_READ_ONLY, got %v", err)
+	}
+	if _, err := settings.getDuration("retry_max_backoff", defaultRetryMaxBackoff); err == nil || !strings.Contains(err.Error(), "ZSPHERE_RETRY_MAX_BACKOFF") {
+		t.Errorf("expected an error naming ZSPHERE_RETRY_MAX_BACKOFF, got %v", err)
+	}
+	if n, err := settings.getInt("max_retries", defaultMaxRetries); err != nil || n != defaultMaxRetries {
+		t.Errorf("expected the default max_retries, got %d, %v", n, err)
+	}
+}
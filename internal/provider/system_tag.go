@@ -0,0 +1,35 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"strings"
+
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/client"
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+)
+
+// querySystemTags returns the system tags of the given resources, grouped by resource uuid.
+// If tag is not empty only system tags equal to it are returned.
+func querySystemTags(cli *client.ZSClient, resourceUuids []string, tag string) (map[string][]string, error) {
+	tags := make(map[string][]string)
+	if len(resourceUuids) == 0 {
+		return tags, nil
+	}
+
+	qparam := param.NewQueryParam()
+	qparam.AddQ("resourceUuid?=" + strings.Join(resourceUuids, ","))
+	if tag != "" {
+		qparam.AddQ("tag=" + tag)
+	}
+
+	systemTags, err := cli.QuerySystemTags(qparam)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, systemTag := range systemTags {
+		tags[systemTag.ResourceUuid] = append(tags[systemTag.ResourceUuid], systemTag.Tag)
+	}
+	return tags, nil
+}
@@ -0,0 +1,149 @@
+// Copyright (c) ZStack.io, Inc.
+
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	schemeHttp  = "http"
+	schemeHttps = "https"
+
+	defaultContextPath = "zstack"
+)
+
+// transportConfig holds the TLS and proxy settings used to reach the ZSphere API.
+type transportConfig struct {
+	caCertFile         string
+	caCertPem          string
+	insecureSkipVerify bool
+	clientCertFile     string
+	clientKeyFile      string
+	clientCertPem      string
+	clientKeyPem       string
+	httpProxy          string
+}
+
+// apiEndpoint is where the ZSphere API is served.
+type apiEndpoint struct {
+	scheme      string
+	host        string
+	port        int
+	contextPath string
+}
+
+// parseEndpoint parses a full API URL, such as https://zsphere.example.com/zstack. The port defaults
+// to the one of the scheme and the context path to /zstack.
+func parseEndpoint(endpoint string) (apiEndpoint, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return apiEndpoint{}, err
+	}
+	if u.Scheme != schemeHttp && u.Scheme != schemeHttps {
+		return apiEndpoint{}, fmt.Errorf("scheme must be http or https, got: %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return apiEndpoint{}, fmt.Errorf("host is missing")
+	}
+
+	ep := apiEndpoint{
+		scheme:      u.Scheme,
+		host:        u.Hostname(),
+		contextPath: strings.Trim(u.Path, "/"),
+	}
+	if ep.contextPath == "" {
+		ep.contextPath = defaultContextPath
+	}
+
+	switch {
+	case u.Port() != "":
+		ep.port, err = strconv.Atoi(u.Port())
+		if err != nil {
+			return apiEndpoint{}, fmt.Errorf("invalid port %q", u.Port())
+		}
+	case u.Scheme == schemeHttps:
+		ep.port = 443
+	default:
+		ep.port = 80
+	}
+	return ep, nil
+}
+
+// newHttpTransport returns the transport used to send requests to the ZSphere API.
+func newHttpTransport(cfg transportConfig) (*http.Transport, error) {
+	defaultTransport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unexpected default http transport %T", http.DefaultTransport)
+	}
+	transport := defaultTransport.Clone()
+
+	if cfg.httpProxy != "" {
+		proxy, err := url.Parse(cfg.httpProxy)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid http_proxy %q, it must be a URL such as http://proxy.example.com:3128", cfg.httpProxy)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.insecureSkipVerify,
+	}
+
+	if cfg.caCertFile != "" || cfg.caCertPem != "" {
+		caCert := []byte(cfg.caCertPem)
+		if cfg.caCertFile != "" {
+			var err error
+			caCert, err = os.ReadFile(cfg.caCertFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read ca_cert_file, err: %v", err)
+			}
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no PEM encoded certificate found in the CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	certPem, keyPem := []byte(cfg.clientCertPem), []byte(cfg.clientKeyPem)
+	if cfg.clientCertFile != "" {
+		var err error
+		certPem, err = os.ReadFile(cfg.clientCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client_cert_file, err: %v", err)
+		}
+	}
+	if cfg.clientKeyFile != "" {
+		var err error
+		keyPem, err = os.ReadFile(cfg.clientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client_key_file, err: %v", err)
+		}
+	}
+	if len(certPem) > 0 || len(keyPem) > 0 {
+		if len(certPem) == 0 || len(keyPem) == 0 {
+			return nil, fmt.Errorf("client certificate authentication needs both a client certificate and a client key")
+		}
+		cert, err := tls.X509KeyPair(certPem, keyPem)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate or key, err: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
@@ -3,7 +3,9 @@
 
 package utils
 
-// FieldMapping
+// FieldMapping maps the filter keys of each data source to the fields of the ZSphere API. Filters on mapped
+// fields are also sent to the API as query conditions, see AddQueryConditions. Dotted keys map the nested
+// attributes whose names differ from the fields of the SDK view, see FilterResource.
 var FieldMapping = map[string]map[string]string{
 	"backup_storage": {
 		"total_capacity":     "totalCapacity",
@@ -13,20 +15,36 @@ var FieldMapping = map[string]map[string]string{
 	},
 
 	"instance": {
-		"cluster_uuid": "clusterUuid",
-		//"cpu_num":         "cpuNum",
-		"cpu_num":         "CPUNum",
+		"uuid":            "uuid",
+		"state":           "state",
+		"cluster_uuid":    "clusterUuid",
+		"cpu_num":         "cpuNum",
 		"host_uuid":       "hostUuid",
 		"hypervisor_type": "hypervisorType",
 		"image_uuid":      "imageUuid",
 		"memory_size":     "memorySize",
 		"zone_uuid":       "zoneUuid",
+		"datacenter_uuid": "zoneUuid",
+
+		"all_volumes.volume_uuid":        "allVolumes.uuid",
+		"all_volumes.volume_description": "allVolumes.description",
+		"all_volumes.volume_type":        "allVolumes.type",
+		"all_volumes.volume_format":      "allVolumes.format",
+		"all_volumes.volume_size":        "allVolumes.size",
+		"all_volumes.volume_actual_size": "allVolumes.actualSize",
+		"all_volumes.volume_state":       "allVolumes.state",
+		"all_volumes.volume_status":      "allVolumes.status",
 	},
 	"cluster": {
+		"uuid":            "uuid",
+		"state":           "state",
 		"hypervisor_type": "hypervisorType",
 		"zone_uuid":       "zoneUuid",
 	},
 	"host": {
+		"uuid":         "uuid",
+		"state":        "state",
+		"status":       "status",
 		"cluster_uuid": "clusterUuid",
 		"managementip": "managementIp",
 		"zone_uuid":    "zoneUuid",
@@ -36,12 +54,16 @@ var FieldMapping = map[string]map[string]string{
 		"allocator_strategy": "allocatorStrategy",
 	},
 	"image": {
+		"uuid":                 "uuid",
+		"state":                "state",
+		"status":               "status",
+		"platform":             "platform",
 		"guest_os_type":        "guestOsType",
 		"image_format":         "imageFormat",
 		"image_type":           "imageType",
 		"media_type":           "mediaType",
 		"boot_mode":            "bootMode",
-		"backup_storage_uuids": "backupStorageUuids",
+		"backup_storage_uuids": "backupStorageRefs.backupStorageUuid",
 	},
 	"instance_offer": {
 		"cpu_num":            "cpuNum",
@@ -50,7 +72,10 @@ var FieldMapping = map[string]map[string]string{
 		"allocator_strategy": "allocatorStrategy",
 	},
 	"l2network": {
+		"uuid":               "uuid",
+		"type":               "type",
 		"physical_interface": "physicalInterface",
+		"v_switch_type":      "vSwitchType",
 		"zone_uuid":          "zoneUuid",
 	},
 	"port_group": {},
@@ -60,6 +85,8 @@ var FieldMapping = map[string]map[string]string{
 		"use_for":               "useFor",
 	},
 	"virtual_router_offer": {
+		"uuid":                    "uuid",
+		"state":                   "state",
 		"allocator_strategy":      "allocatorStrategy",
 		"cpu_num":                 "cpuNum",
 		"image_uuid":              "imageUuid",
@@ -70,11 +97,15 @@ var FieldMapping = map[string]map[string]string{
 		"zone_uuid":               "zoneUuid",
 	},
 	"virtual_router_instance": {
-		"agent_port":        "agentPort",
-		"appliance_vm_type": "applianceVmType",
-		"cluster_uuid":      "clusterUuid",
-		//"cpu_num":                 "cpuNum",
-		"cpu_num":                 "CPUNum",
+		"uuid":                    "uuid",
+		"state":                   "state",
+		"status":                  "status",
+		"zone_uuid":               "zoneUuid",
+		"public_network_uuid":     "publicNetworkUuid",
+		"agent_port":              "agentPort",
+		"appliance_vm_type":       "applianceVmType",
+		"cluster_uuid":            "clusterUuid",
+		"cpu_num":                 "cpuNum",
 		"ha_status":               "haStatus",
 		"host_uuid":               "hostUuid",
 		"hypervisor_type":         "hypervisorType",
@@ -86,8 +117,14 @@ var FieldMapping = map[string]map[string]string{
 	"virtual_router_image": {
 		"guest_os_type": "guestOsType",
 	},
-	"zone": {},
+	"zone": {
+		"uuid":  "uuid",
+		"state": "state",
+	},
 	"primary_storage": {
+		"uuid":                        "uuid",
+		"state":                       "state",
+		"status":                      "status",
 		"total_capacity":              "totalCapacity",
 		"available_capacity":          "availableCapacity",
 		"total_physical_capacity":     "totalPhysicalCapacity",
@@ -95,6 +132,10 @@ var FieldMapping = map[string]map[string]string{
 		"system_used_capacity":        "systemUsedCapacity",
 	},
 	"disks": {
+		"uuid":                 "uuid",
+		"state":                "state",
+		"status":               "status",
+		"actual_size":          "actualSize",
 		"disk_offering_uuid":   "diskOfferingUuid",
 		"is_shareable":         "isShareable",
 		"primary_storage_uuid": "primaryStorageUuid",
@@ -121,9 +162,12 @@ var FieldMapping = map[string]map[string]string{
 	},
 	"tag": {},
 	"security_group": {
+		"uuid":                      "uuid",
+		"state":                     "state",
 		"ip_version":                "ipVersion",
-		"src_ip_range":              "srcIpRange",
-		"dst_ip_range":              "dstIpRange",
+		"src_ip_range":              "rules.srcIpRange",
+		"dst_ip_range":              "rules.dstIpRange",
+		"dst_port_range":            "rules.dstPortRange",
 		"attached_l3_network_uuids": "attachedL3NetworkUuids",
 	},
 	"security_group_rule": {
@@ -7,99 +7,382 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
+// Filter operators. Equal and In, NotEqual and NotIn are the same: a field matches Equal if it equals any value.
+const (
+	OperatorEqual        = "="
+	OperatorNotEqual     = "!="
+	OperatorRegex        = "regex"
+	OperatorPrefix       = "prefix"
+	OperatorLess         = "<"
+	OperatorLessEqual    = "<="
+	OperatorGreater      = ">"
+	OperatorGreaterEqual = ">="
+	OperatorIn           = "in"
+	OperatorNotIn        = "not_in"
+)
+
+// FilterOperators are the operators a filter can use.
+var FilterOperators = []string{
+	OperatorEqual, OperatorNotEqual, OperatorRegex, OperatorPrefix,
+	OperatorLess, OperatorLessEqual, OperatorGreater, OperatorGreaterEqual,
+	OperatorIn, OperatorNotIn,
+}
+
+// How the filters of a data source are combined: a resource matches all of them, or any of them.
+const (
+	MatchAll = "all"
+	MatchAny = "any"
+)
+
+// Filter is a condition on a field of the resources of a data source.
+type Filter struct {
+	Name string
+	// Operator is one of FilterOperators, OperatorEqual if empty.
+	Operator string
+	Values   []string
+}
+
+// operator returns the operator of the filter, with the aliases resolved.
+func (f Filter) operator() string {
+	switch f.Operator {
+	case "", OperatorIn:
+		return OperatorEqual
+	case OperatorNotIn:
+		return OperatorNotEqual
+	}
+	return f.Operator
+}
+
+// numeric reports whether the operator of the filter compares numbers.
+func (f Filter) numeric() bool {
+	switch f.operator() {
+	case OperatorLess, OperatorLessEqual, OperatorGreater, OperatorGreaterEqual:
+		return true
+	}
+	return false
+}
+
+// compiledFilter is a filter with its field path, regular expressions and numbers resolved once for all resources.
+type compiledFilter struct {
+	Filter
+	// path are the names of the fields from the resource to the filtered value.
+	path     []string
+	convert  func(int64) int64
+	patterns []*regexp.Regexp
+	number   float64
+}
+
+func compileFilter(filter Filter) (compiledFilter, error) {
+	compiled := compiledFilter{Filter: filter}
+
+	switch {
+	case filter.operator() == OperatorRegex:
+		for _, value := range filter.Values {
+			pattern, err := regexp.Compile(value)
+			if err != nil {
+				return compiled, fmt.Errorf("filter '%s' has an invalid regular expression %q: %v", filter.Name, value, err)
+			}
+			compiled.patterns = append(compiled.patterns, pattern)
+		}
+	case filter.numeric():
+		if len(filter.Values) != 1 {
+			return compiled, fmt.Errorf("filter '%s' with operator '%s' takes exactly one value, got %d", filter.Name, filter.Operator, len(filter.Values))
+		}
+		number, err := strconv.ParseFloat(filter.Values[0], 64)
+		if err != nil {
+			return compiled, fmt.Errorf("filter '%s' with operator '%s' takes a number, got %q", filter.Name, filter.Operator, filter.Values[0])
+		}
+		compiled.number = number
+	default:
+		known := false
+		for _, operator := range FilterOperators {
+			known = known || filter.operator() == operator
+		}
+		if !known {
+			return compiled, fmt.Errorf("filter '%s' has an unknown operator '%s', expected one of %s", filter.Name, filter.Operator, strings.Join(FilterOperators, ", "))
+		}
+	}
+	return compiled, nil
+}
+
+// matchesAny reports whether any of the values of a field satisfies the filter, or for != and not_in
+// whether none of them equals a value of the filter. A list field without elements has no values.
+func (f compiledFilter) matchesAny(fieldValues []string) (bool, error) {
+	if f.operator() == OperatorNotEqual {
+		equal := f
+		equal.Operator = OperatorEqual
+		found, err := equal.matchesAny(fieldValues)
+		return !found, err
+	}
+
+	for _, fieldValue := range fieldValues {
+		ok, err := f.matches(fieldValue)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// matches reports whether a single value of a field satisfies the filter.
+func (f compiledFilter) matches(fieldValue string) (bool, error) {
+	switch f.operator() {
+	case OperatorEqual:
+		for _, value := range f.Values {
+			if fieldValue == value {
+				return true, nil
+			}
+		}
+		return false, nil
+	case OperatorRegex:
+		for _, pattern := range f.patterns {
+			if pattern.MatchString(fieldValue) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case OperatorPrefix:
+		for _, value := range f.Values {
+			if strings.HasPrefix(fieldValue, value) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	number, err := strconv.ParseFloat(fieldValue, 64)
+	if err != nil {
+		return false, fmt.Errorf("field '%s' is not a number and cannot be compared with '%s', got %q", f.Name, f.Operator, fieldValue)
+	}
+	switch f.operator() {
+	case OperatorLess:
+		return number < f.number, nil
+	case OperatorLessEqual:
+		return number <= f.number, nil
+	case OperatorGreater:
+		return number > f.number, nil
+	default:
+		return number >= f.number, nil
+	}
+}
+
+// FilterResource returns the resources that match the filters, all of them or any of them as set by match.
+// The filter names are the attribute names of the data source and may be dotted paths into nested attributes
+// and lists such as vm_nics.ip, which match if any element matches. Names the SDK view spells differently,
+// such as all_volumes.volume_size for allVolumes.size, are mapped through FieldMapping; other names are the
+// fields of the SDK view in snake case, so fields that are not attributes, such as all_volumes.primary_storage_uuid,
+// can be filtered on too.
 func FilterResource[T any](
 	ctx context.Context,
 	resources []T,
-	filters map[string][]string,
+	filters []Filter,
+	match string,
 	dataSourceName string,
 ) ([]T, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	var filteredResources []T
 
-	fieldMapping := GetFieldMapping(dataSourceName)
+	if len(filters) == 0 {
+		return resources, diags
+	}
 
-	for _, resource := range resources {
-		match := true
-		resourceValue := reflect.ValueOf(resource)
+	fieldMapping := GetFieldMapping(dataSourceName)
+	resourceType := reflect.TypeFor[T]()
 
-		for key, values := range filters {
-			//  Terraform Schema map to API Attribute
-			apiFieldName, ok := fieldMapping[key]
-			if !ok {
-				apiFieldName = key
-			}
+	compiled := make([]compiledFilter, 0, len(filters))
+	for _, filter := range filters {
+		c, err := compileFilter(filter)
+		if err != nil {
+			diags.AddError("Invalid Filter", err.Error())
+			return nil, diags
+		}
 
-			fieldName := strings.Title(apiFieldName)
-			field := resourceValue.FieldByName(fieldName)
+		c.path = fieldPath(filter.Name, fieldMapping)
+		if summary, detail := checkFieldPath(resourceType, c.path, filter.Name); summary != "" {
+			diags.AddError(summary, detail)
+			return nil, diags
+		}
+		c.convert = unitConversion(dataSourceName, filter.Name)
+		compiled = append(compiled, c)
+	}
+	matchAny := match == MatchAny
 
-			if !field.IsValid() {
-				diags.AddError(
-					"Invalid Filter Key",
-					fmt.Sprintf("Field '%s' does not exist in resource", key),
-				)
-				return nil, diags
-			}
+	for _, resource := range resources {
+		matched := !matchAny
+		resourceValue := reflect.ValueOf(resource)
 
-			var fieldValue string
-			switch field.Kind() {
-			case reflect.Struct:
-				if field.Type() == reflect.TypeOf(types.String{}) {
-					strValue := field.Interface().(types.String)
-					fieldValue = strValue.ValueString()
-				} else {
-					diags.AddError(
-						"Unsupported Field Type",
-						fmt.Sprintf("Field '%s' has unsupported type: %s", key, field.Type()),
-					)
-					return nil, diags
-				}
-			case reflect.String:
-				fieldValue = field.String()
-			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-				if key == "memory_size" {
-					fieldValue = fmt.Sprintf("%d", BytesToMB(field.Int()))
-				} else if key == "disk_size" {
-					fieldValue = fmt.Sprintf("%d", BytesToGB(field.Int()))
-				} else if key == "volume_size" {
-					fieldValue = fmt.Sprintf("%d", BytesToGB(field.Int()))
-				} else {
-					fieldValue = fmt.Sprintf("%d", field.Int())
-				}
-			case reflect.Bool:
-				fieldValue = fmt.Sprintf("%t", field.Bool())
-			default:
-				diags.AddError(
-					"Unsupported Field Type",
-					fmt.Sprintf("Field '%s' has unsupported type: %s", key, field.Kind()),
-				)
+		for _, filter := range compiled {
+			valueMatch, err := filter.matchesAny(fieldValues(resourceValue, filter.path, filter.convert))
+			if err != nil {
+				diags.AddError("Invalid Filter", err.Error())
 				return nil, diags
 			}
 
-			// Check if the field value matches any of the filter values
-			valueMatch := false
-			for _, value := range values {
-				if fieldValue == value {
-					valueMatch = true
-					break
-				}
-			}
-
-			if !valueMatch {
-				match = false
+			if valueMatch == matchAny {
+				matched = matchAny
 				break
 			}
 		}
 
-		if match {
+		if matched {
 			filteredResources = append(filteredResources, resource)
 		}
 	}
 
 	return filteredResources, diags
 }
+
+// fieldPath returns the names of the API fields a filter key refers to. A key such as all_volumes.volume_size
+// walks into nested views; its longest part mapped through FieldMapping, e.g. all_volumes.volume_size itself,
+// is replaced by the API fields, and the rest is converted to camel case.
+func fieldPath(key string, fieldMapping map[string]string) []string {
+	names := strings.Split(key, ".")
+	for i := len(names); i > 0; i-- {
+		//  Terraform Schema map to API Attribute
+		if apiFieldName, ok := fieldMapping[strings.Join(names[:i], ".")]; ok {
+			path := strings.Split(apiFieldName, ".")
+			for _, name := range names[i:] {
+				path = append(path, snakeToCamel(name))
+			}
+			return path
+		}
+	}
+
+	for i, name := range names {
+		names[i] = snakeToCamel(name)
+	}
+	return names
+}
+
+// snakeToCamel converts a Terraform attribute name such as primary_storage_uuid to an API field name such as primaryStorageUuid.
+func snakeToCamel(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = upperFirst(parts[i])
+	}
+	return strings.Join(parts, "")
+}
+
+// upperFirst returns a name with its first letter in upper case.
+func upperFirst(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	if size == 0 {
+		return name
+	}
+	return string(unicode.ToUpper(r)) + name[size:]
+}
+
+// checkFieldPath checks that a field path leads from the resource type to a value a filter can compare,
+// and returns the summary and detail of the error otherwise.
+func checkFieldPath(resourceType reflect.Type, path []string, key string) (string, string) {
+	for resourceType.Kind() == reflect.Pointer || resourceType.Kind() == reflect.Slice || resourceType.Kind() == reflect.Array {
+		resourceType = resourceType.Elem()
+	}
+
+	if len(path) == 0 {
+		if resourceType == reflect.TypeOf(types.String{}) {
+			return "", ""
+		}
+		switch resourceType.Kind() {
+		case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return "", ""
+		}
+		return "Unsupported Field Type", fmt.Sprintf("Field '%s' has unsupported type: %s, filter on one of its fields with a dotted path such as '%s.uuid'", key, resourceType, key)
+	}
+
+	if resourceType.Kind() != reflect.Struct || resourceType == reflect.TypeOf(types.String{}) {
+		return "Invalid Filter Key", fmt.Sprintf("Field '%s' does not exist in resource", key)
+	}
+
+	field, ok := lookupField(resourceType, path[0])
+	if !ok {
+		return "Invalid Filter Key", fmt.Sprintf("Field '%s' does not exist in resource", key)
+	}
+	return checkFieldPath(field.Type, path[1:], key)
+}
+
+// fieldValues returns the values at the end of a field path checked by checkFieldPath. Lists on the way
+// contribute the values of all their elements, and nil pointers none.
+func fieldValues(value reflect.Value, path []string, convert func(int64) int64) []string {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return fieldValues(value.Elem(), path, convert)
+	case reflect.Slice, reflect.Array:
+		var values []string
+		for i := 0; i < value.Len(); i++ {
+			values = append(values, fieldValues(value.Index(i), path, convert)...)
+		}
+		return values
+	}
+
+	if len(path) > 0 {
+		field, ok := lookupField(value.Type(), path[0])
+		if !ok {
+			return nil
+		}
+		return fieldValues(value.FieldByIndex(field.Index), path[1:], convert)
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if strValue, ok := value.Interface().(types.String); ok {
+			return []string{strValue.ValueString()}
+		}
+	case reflect.String:
+		return []string{value.String()}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if convert != nil {
+			return []string{fmt.Sprintf("%d", convert(value.Int()))}
+		}
+		return []string{fmt.Sprintf("%d", value.Int())}
+	case reflect.Float32, reflect.Float64:
+		return []string{strconv.FormatFloat(value.Float(), 'f', -1, 64)}
+	case reflect.Bool:
+		return []string{fmt.Sprintf("%t", value.Bool())}
+	}
+	return nil
+}
+
+// lookupField finds the field of an SDK view that holds an API field.
+func lookupField(resourceType reflect.Type, apiFieldName string) (reflect.StructField, bool) {
+	field, ok := resourceType.FieldByName(upperFirst(apiFieldName))
+	if !ok {
+		// SDK views spell initialisms in upper case, e.g. vmInstanceUuid is VMInstanceUUID
+		field, ok = resourceType.FieldByNameFunc(func(name string) bool {
+			return strings.EqualFold(name, apiFieldName)
+		})
+	}
+	return field, ok
+}
+
+// unitConversion returns how a filter converts the bytes of a field to the unit of the data source, e.g. memory_size
+// in MB, or nil if the field is compared as it is.
+func unitConversion(dataSourceName string, key string) func(int64) int64 {
+	leaf := key[strings.LastIndex(key, ".")+1:]
+	switch {
+	case leaf == "memory_size":
+		return BytesToMB
+	case leaf == "disk_size", leaf == "volume_size", leaf == "volume_actual_size":
+		return BytesToGB
+	case dataSourceName == "disks" && (key == "size" || key == "actual_size"):
+		return BytesToGB
+	case strings.HasPrefix(key, "all_volumes.") && (leaf == "size" || leaf == "actual_size"):
+		// the volumes of an instance are shown in GB
+		return BytesToGB
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+// Copyright (c) ZStack.io, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+func testInstances() []view.VmInstanceInventoryView {
+	return []view.VmInstanceInventoryView{
+		{
+			UUID:       "vm-1",
+			Name:       "web-1",
+			State:      "Running",
+			CPUNum:     2,
+			MemorySize: 4 * 1024 * 1024 * 1024,
+			VMNics: []view.VmNicInventoryView{
+				{IP: "10.0.0.11", L3NetworkUUID: "l3-public"},
+				{IP: "192.168.0.11", L3NetworkUUID: "l3-private"},
+			},
+			AllVolumes: []view.VolumeView{
+				{UUID: "root-1", Type: "Root", PrimaryStorageUUID: "ps-ssd", Size: 40 * 1024 * 1024 * 1024},
+			},
+		},
+		{
+			UUID:       "vm-2",
+			Name:       "db-1",
+			State:      "Stopped",
+			CPUNum:     8,
+			MemorySize: 16 * 1024 * 1024 * 1024,
+			VMNics: []view.VmNicInventoryView{
+				{IP: "192.168.0.12", L3NetworkUUID: "l3-private"},
+			},
+			AllVolumes: []view.VolumeView{
+				{UUID: "root-2", Type: "Root", PrimaryStorageUUID: "ps-ssd", Size: 40 * 1024 * 1024 * 1024},
+				{UUID: "data-2", Type: "Data", PrimaryStorageUUID: "ps-hdd", Size: 500 * 1024 * 1024 * 1024},
+			},
+		},
+		{
+			UUID:       "vm-3",
+			Name:       "build",
+			State:      "Running",
+			CPUNum:     4,
+			MemorySize: 8 * 1024 * 1024 * 1024,
+		},
+	}
+}
+
+func uuids(instances []view.VmInstanceInventoryView) []string {
+	result := []string{}
+	for _, instance := range instances {
+		result = append(result, instance.UUID)
+	}
+	return result
+}
+
+func TestFilterResource(t *testing.T) {
+	cases := []struct {
+		name    string
+		filters []Filter
+		match   string
+		want    []string
+	}{
+		{
+			name: "no filters",
+			want: []string{"vm-1", "vm-2", "vm-3"},
+		},
+		{
+			name:    "equal to any value",
+			filters: []Filter{{Name: "state", Values: []string{"Stopped", "Paused"}}},
+			want:    []string{"vm-2"},
+		},
+		{
+			name:    "memory size in MB",
+			filters: []Filter{{Name: "memory_size", Values: []string{"8192"}}},
+			want:    []string{"vm-3"},
+		},
+		{
+			name:    "numeric range",
+			filters: []Filter{{Name: "cpu_num", Operator: ">=", Values: []string{"4"}}, {Name: "cpu_num", Operator: "<", Values: []string{"8"}}},
+			want:    []string{"vm-3"},
+		},
+		{
+			name:    "regex",
+			filters: []Filter{{Name: "name", Operator: "regex", Values: []string{"^(web|db)-"}}},
+			want:    []string{"vm-1", "vm-2"},
+		},
+		{
+			name:    "any filter",
+			filters: []Filter{{Name: "state", Values: []string{"Stopped"}}, {Name: "name", Operator: "prefix", Values: []string{"web"}}},
+			match:   MatchAny,
+			want:    []string{"vm-1", "vm-2"},
+		},
+		{
+			name:    "nic ip of any nic",
+			filters: []Filter{{Name: "vm_nics.ip", Values: []string{"192.168.0.11"}}},
+			want:    []string{"vm-1"},
+		},
+		{
+			name:    "nic l3 network",
+			filters: []Filter{{Name: "vm_nics.l3_network_uuid", Values: []string{"l3-private"}}},
+			want:    []string{"vm-1", "vm-2"},
+		},
+		{
+			name:    "nic ip prefix",
+			filters: []Filter{{Name: "vm_nics.ip", Operator: "prefix", Values: []string{"10."}}},
+			want:    []string{"vm-1"},
+		},
+		{
+			name:    "no nic in the network",
+			filters: []Filter{{Name: "vm_nics.l3_network_uuid", Operator: "not_in", Values: []string{"l3-public"}}},
+			want:    []string{"vm-2", "vm-3"},
+		},
+		{
+			name:    "volume primary storage",
+			filters: []Filter{{Name: "all_volumes.primary_storage_uuid", Values: []string{"ps-hdd"}}},
+			want:    []string{"vm-2"},
+		},
+		{
+			name:    "volume size in GB",
+			filters: []Filter{{Name: "all_volumes.volume_size", Operator: ">", Values: []string{"100"}}},
+			want:    []string{"vm-2"},
+		},
+		{
+			name:    "volume size by its SDK name",
+			filters: []Filter{{Name: "all_volumes.size", Operator: ">", Values: []string{"100"}}},
+			want:    []string{"vm-2"},
+		},
+		{
+			name:    "volume uuid",
+			filters: []Filter{{Name: "all_volumes.volume_uuid", Values: []string{"root-1"}}},
+			want:    []string{"vm-1"},
+		},
+		{
+			name:    "volume type",
+			filters: []Filter{{Name: "all_volumes.volume_type", Values: []string{"Data"}}},
+			want:    []string{"vm-2"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, diags := FilterResource(context.Background(), testInstances(), tc.filters, tc.match, "instance")
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+			if !reflect.DeepEqual(uuids(got), tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, uuids(got))
+			}
+		})
+	}
+}
+
+func TestFieldPath(t *testing.T) {
+	cases := []struct {
+		key  string
+		want []string
+	}{
+		{"state", []string{"state"}},
+		{"datacenter_uuid", []string{"zoneUuid"}},
+		{"vm_nics.ip", []string{"vmNics", "ip"}},
+		{"vm_nics.l3_network_uuid", []string{"vmNics", "l3NetworkUuid"}},
+		{"all_volumes.volume_size", []string{"allVolumes", "size"}},
+		{"all_volumes.volume_actual_size", []string{"allVolumes", "actualSize"}},
+		{"all_volumes.primary_storage_uuid", []string{"allVolumes", "primaryStorageUuid"}},
+	}
+
+	for _, tc := range cases {
+		if got := fieldPath(tc.key, GetFieldMapping("instance")); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.key, tc.want, got)
+		}
+	}
+
+	// nested keys are mapped through the longest mapped part
+	mapping := map[string]string{"backup_storage_refs": "backupStorageRefs"}
+	if got := fieldPath("backup_storage_refs.backup_storage_uuid", mapping); !reflect.DeepEqual(got, []string{"backupStorageRefs", "backupStorageUuid"}) {
+		t.Errorf("expected [backupStorageRefs backupStorageUuid], got %v", got)
+	}
+}
+
+func TestFilterResourceListOfValues(t *testing.T) {
+	networks := []view.L3NetworkInventoryView{
+		{
+			UUID:     "l3-1",
+			Category: "Public",
+			Dns:      []string{"8.8.8.8", "1.1.1.1"},
+			IpRanges: []view.IpRangeInventoryView{{NetworkCidr: "10.0.0.0/24"}},
+		},
+		{
+			UUID:     "l3-2",
+			Category: "Private",
+			IpRanges: []view.IpRangeInventoryView{{NetworkCidr: "192.168.0.0/24"}, {NetworkCidr: "192.168.1.0/24"}},
+		},
+	}
+
+	for _, tc := range []struct {
+		filter Filter
+		want   string
+	}{
+		{Filter{Name: "dns", Values: []string{"1.1.1.1"}}, "l3-1"},
+		{Filter{Name: "ip_ranges.network_cidr", Values: []string{"192.168.1.0/24"}}, "l3-2"},
+	} {
+		got, diags := FilterResource(context.Background(), networks, []Filter{tc.filter}, "", "port_group")
+		if diags.HasError() {
+			t.Fatalf("%s: unexpected error: %v", tc.filter.Name, diags)
+		}
+		if len(got) != 1 || got[0].UUID != tc.want {
+			t.Errorf("%s: expected %s, got %v", tc.filter.Name, tc.want, got)
+		}
+	}
+}
+
+func TestFilterResourceErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		filter  Filter
+		summary string
+	}{
+		{
+			name:    "unknown field",
+			filter:  Filter{Name: "vm_nic.ip", Values: []string{"10.0.0.11"}},
+			summary: "Invalid Filter Key",
+		},
+		{
+			name:    "unknown nested field",
+			filter:  Filter{Name: "vm_nics.address", Values: []string{"10.0.0.11"}},
+			summary: "Invalid Filter Key",
+		},
+		{
+			name:    "list of views",
+			filter:  Filter{Name: "vm_nics", Values: []string{"10.0.0.11"}},
+			summary: "Unsupported Field Type",
+		},
+		{
+			name:    "numeric operator on text",
+			filter:  Filter{Name: "vm_nics.ip", Operator: "<", Values: []string{"10"}},
+			summary: "Invalid Filter",
+		},
+		{
+			name:    "numeric operator with two values",
+			filter:  Filter{Name: "cpu_num", Operator: ">", Values: []string{"1", "2"}},
+			summary: "Invalid Filter",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, diags := FilterResource(context.Background(), testInstances(), []Filter{tc.filter}, "", "instance")
+			if !diags.HasError() {
+				t.Fatal("expected an error")
+			}
+			if got := diags.Errors()[0].Summary(); got != tc.summary {
+				t.Errorf("expected %q, got %q", tc.summary, got)
+			}
+		})
+	}
+
+	// an unknown field is reported even if no resource is returned
+	if _, diags := FilterResource[view.VmInstanceInventoryView](context.Background(), nil, []Filter{{Name: "vm_nics.address", Values: []string{"x"}}}, "", "instance"); !diags.HasError() {
+		t.Error("expected an error for an unknown field without resources")
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) ZStack.io, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/param"
+)
+
+// AddQueryConditions adds the filters that map to an API field through FieldMapping to the query, so that the
+// ZSphere API only returns the matching resources of type T instead of the whole collection.
+//
+// Filters the API cannot evaluate exactly or more loosely, such as regular expressions, fields converted to
+// another unit, lists or values with a comma, are left out, and so are all filters combined with MatchAny.
+// FilterResource must still be run with all filters: it filters those on the client and checks the others
+// again, so the result is the same as filtering on the client only.
+func AddQueryConditions[T any](params *param.QueryParam, filters []Filter, match string, dataSourceName string) {
+	for _, condition := range QueryConditions[T](filters, match, dataSourceName) {
+		params.AddQ(condition)
+	}
+}
+
+// QueryConditions returns the filters the ZSphere API can evaluate for resources of type T, in the form of
+// QueryParam.AddQ, sorted.
+func QueryConditions[T any](filters []Filter, match string, dataSourceName string) []string {
+	fieldMapping := GetFieldMapping(dataSourceName)
+	resourceType := reflect.TypeFor[T]()
+	if match == MatchAny || resourceType.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var conditions []string
+	for _, filter := range filters {
+		apiFieldName, ok := fieldMapping[filter.Name]
+		if !ok || len(filter.Values) == 0 || unitConversion(dataSourceName, filter.Name) != nil {
+			continue
+		}
+
+		field, ok := lookupField(resourceType, apiFieldName)
+		if !ok || !isScalar(field.Type.Kind()) {
+			continue
+		}
+
+		pushable := true
+		for _, value := range filter.Values {
+			if value == "" || strings.Contains(value, ",") || !isQueryValue(value, field.Type.Kind()) {
+				pushable = false
+				break
+			}
+		}
+		if !pushable {
+			continue
+		}
+
+		if condition := queryCondition(filter, apiFieldName, field.Type.Kind()); condition != "" {
+			conditions = append(conditions, condition)
+		}
+	}
+
+	sort.Strings(conditions)
+	return conditions
+}
+
+// queryCondition returns the condition of a filter on an API field, or an empty string if the API could
+// leave out resources the filter matches. The API compares strings ignoring case, so it may return more
+// resources for =, but fewer for != or numbers compared as text.
+func queryCondition(filter Filter, apiFieldName string, kind reflect.Kind) string {
+	sorted := append([]string(nil), filter.Values...)
+	sort.Strings(sorted)
+	list := strings.Join(sorted, ",")
+	isString := kind == reflect.String
+
+	switch filter.operator() {
+	case OperatorEqual:
+		if len(sorted) == 1 {
+			return apiFieldName + "=" + list
+		}
+		return apiFieldName + "?=" + list
+	case OperatorNotEqual:
+		if isString {
+			return ""
+		}
+		if len(sorted) == 1 {
+			return apiFieldName + "!=" + list
+		}
+		return apiFieldName + "!?=" + list
+	case OperatorPrefix:
+		if !isString || len(sorted) != 1 || strings.ContainsAny(list, "%_") {
+			return ""
+		}
+		return apiFieldName + "~=" + list + "%"
+	case OperatorLess, OperatorLessEqual, OperatorGreater, OperatorGreaterEqual:
+		if isString || kind == reflect.Bool || len(sorted) != 1 {
+			return ""
+		}
+		return apiFieldName + filter.operator() + list
+	}
+	return ""
+}
+
+// isQueryValue reports whether the API can compare a field of the given kind with a value. A number or boolean
+// field compared with text is left to FilterResource, which does not match it, instead of failing the query.
+func isQueryValue(value string, kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool:
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	}
+	return true
+}
+
+// isScalar reports whether a field of an SDK view is a plain value the ZSphere API can compare.
+func isScalar(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+// Copyright (c) ZStack.io, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/terraform-zstack-modules/zsphere-sdk-go/pkg/view"
+)
+
+func TestQueryConditions(t *testing.T) {
+	cases := []struct {
+		name    string
+		filters []Filter
+		match   string
+		want    []string
+	}{
+		{
+			name:    "equal",
+			filters: []Filter{{Name: "state", Values: []string{"Running"}}},
+			want:    []string{"state=Running"},
+		},
+		{
+			name:    "equal to any value, sorted",
+			filters: []Filter{{Name: "state", Operator: "in", Values: []string{"Stopped", "Running"}}},
+			want:    []string{"state?=Running,Stopped"},
+		},
+		{
+			name:    "conditions sorted",
+			filters: []Filter{{Name: "zone_uuid", Values: []string{"zone-1"}}, {Name: "cpu_num", Operator: ">=", Values: []string{"4"}}},
+			want:    []string{"cpuNum>=4", "zoneUuid=zone-1"},
+		},
+		{
+			name:    "schema name mapped to the API field",
+			filters: []Filter{{Name: "datacenter_uuid", Values: []string{"zone-1"}}},
+			want:    []string{"zoneUuid=zone-1"},
+		},
+		{
+			name:    "not equal to a number",
+			filters: []Filter{{Name: "cpu_num", Operator: "not_in", Values: []string{"2"}}},
+			want:    []string{"cpuNum!=2"},
+		},
+		{
+			name:    "not equal to text compares case-insensitively",
+			filters: []Filter{{Name: "state", Operator: "!=", Values: []string{"Running"}}},
+		},
+		{
+			name:    "prefix",
+			filters: []Filter{{Name: "uuid", Operator: "prefix", Values: []string{"ab12"}}},
+			want:    []string{"uuid~=ab12%"},
+		},
+		{
+			name:    "prefix with a wildcard",
+			filters: []Filter{{Name: "uuid", Operator: "prefix", Values: []string{"ab_"}}},
+		},
+		{
+			name:    "regex",
+			filters: []Filter{{Name: "state", Operator: "regex", Values: []string{"^Run"}}},
+		},
+		{
+			name:    "match any",
+			filters: []Filter{{Name: "state", Values: []string{"Running"}}, {Name: "cpu_num", Values: []string{"2"}}},
+			match:   MatchAny,
+		},
+		{
+			name:    "unit-converted field",
+			filters: []Filter{{Name: "memory_size", Values: []string{"8192"}}},
+		},
+		{
+			name:    "value with a comma",
+			filters: []Filter{{Name: "state", Values: []string{"Running,Stopped"}}},
+		},
+		{
+			name:    "empty value",
+			filters: []Filter{{Name: "state", Values: []string{""}}},
+		},
+		{
+			name:    "text compared with a number field",
+			filters: []Filter{{Name: "cpu_num", Values: []string{"four"}}},
+		},
+		{
+			name:    "fraction compared with a number field",
+			filters: []Filter{{Name: "cpu_num", Operator: ">", Values: []string{"4.5"}}},
+		},
+		{
+			name:    "numeric operator on text",
+			filters: []Filter{{Name: "state", Operator: ">", Values: []string{"1"}}},
+		},
+		{
+			name:    "unmapped field",
+			filters: []Filter{{Name: "name", Values: []string{"web-1"}}},
+		},
+		{
+			name:    "nested field",
+			filters: []Filter{{Name: "all_volumes.volume_uuid", Values: []string{"root-1"}}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := QueryConditions[view.VmInstanceInventoryView](tc.filters, tc.match, "instance")
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestQueryConditionsFieldTypes(t *testing.T) {
+	cases := []struct {
+		name       string
+		conditions func() []string
+		want       []string
+	}{
+		{
+			name: "boolean",
+			conditions: func() []string {
+				return QueryConditions[view.VolumeView]([]Filter{{Name: "is_shareable", Values: []string{"true"}}}, "", "disks")
+			},
+			want: []string{"isShareable=true"},
+		},
+		{
+			name: "text compared with a boolean field",
+			conditions: func() []string {
+				return QueryConditions[view.VolumeView]([]Filter{{Name: "is_shareable", Values: []string{"yes"}}}, "", "disks")
+			},
+		},
+		{
+			name: "size in GB",
+			conditions: func() []string {
+				return QueryConditions[view.VolumeView]([]Filter{{Name: "actual_size", Operator: ">", Values: []string{"10"}}}, "", "disks")
+			},
+		},
+		{
+			name: "list of nested views",
+			conditions: func() []string {
+				return QueryConditions[view.ImageView]([]Filter{{Name: "backup_storage_uuids", Values: []string{"bs-1"}}}, "", "image")
+			},
+		},
+		{
+			name: "not a view",
+			conditions: func() []string {
+				return QueryConditions[string]([]Filter{{Name: "state", Values: []string{"Running"}}}, "", "instance")
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.conditions(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}